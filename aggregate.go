@@ -0,0 +1,73 @@
+package promise
+
+import "time"
+
+// AggregateResult is what the promise returned by Aggregate resolves with.
+type AggregateResult struct {
+	// Required holds the results of every required promise, in order.
+	Required []Results
+	// Optional holds the results of every optional promise, in order. An
+	// optional promise that hadn't settled by the soft deadline has
+	// ErrTimeout as its error.
+	Optional []Results
+}
+
+// Aggregate gathers the results of required and optional promises, as a
+// backend-for-frontend gathers calls to several services into one
+// response. The returned promise resolves with an AggregateResult once
+// every required promise has resolved and every optional promise has
+// either settled or missed the soft deadline, whichever comes last. It is
+// rejected by the first required promise to fail, or with ErrTimeout if
+// the required promises haven't all resolved by the hard deadline. Both
+// deadlines are measured from the call to Aggregate.
+func Aggregate(required, optional []*Promise, soft, hard time.Duration) *Promise {
+	d := NewDeferred(typeOf[AggregateResult]())
+	for _, p := range append(append([]*Promise{}, required...), optional...) {
+		p.checkCopy()
+		p.observe()
+		p.graph.addChild(p, d.Promise)
+	}
+	d.watchContexts(required)
+	softDone := make(chan struct{})
+	hardDone := make(chan struct{})
+	softTimer := d.afterFunc(soft, func() { close(softDone) })
+	hardTimer := d.afterFunc(hard, func() { close(hardDone) })
+	d.Defer(func() {
+		softTimer.Stop()
+		hardTimer.Stop()
+	})
+	spawn(func() {
+		var result AggregateResult
+		for _, p := range required {
+			select {
+			case <-p.doneChan():
+			case <-hardDone:
+				d.Reject(ErrTimeout)
+				return
+			case <-d.doneChan():
+				return
+			}
+			if p.err != nil {
+				d.Reject(p.err)
+				return
+			}
+			result.Required = append(result.Required, p.settledResults())
+		}
+		for _, p := range optional {
+			select {
+			case <-p.doneChan():
+				result.Optional = append(result.Optional, p.settledResults())
+			case <-softDone:
+				if p.isSettled() {
+					result.Optional = append(result.Optional, p.settledResults())
+				} else {
+					result.Optional = append(result.Optional, Results{Err: ErrTimeout})
+				}
+			case <-d.doneChan():
+				return
+			}
+		}
+		d.Resolve(result)
+	})
+	return d.Promise
+}
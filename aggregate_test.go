@@ -0,0 +1,41 @@
+package promise
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	slow := make(chan struct{})
+	defer close(slow)
+	failure := fmt.Errorf("failure")
+	p := Aggregate(
+		[]*Promise{Resolved("user"), New(func() int { return 2 })},
+		[]*Promise{
+			Resolved("recommendations"),
+			New(func() string { <-slow; return "ads" }),
+			Rejected(failure, typeOf[string]()),
+		},
+		20*time.Millisecond, time.Second)
+	var result AggregateResult
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, []Results{{Values: []interface{}{"user"}}, {Values: []interface{}{2}}}, result.Required)
+	require.Len(t, result.Optional, 3)
+	require.Equal(t, []interface{}{"recommendations"}, result.Optional[0].Values)
+	require.Equal(t, ErrTimeout, result.Optional[1].Err)
+	require.Equal(t, failure, result.Optional[2].Err)
+}
+
+func TestAggregateRequired(t *testing.T) {
+	failure := fmt.Errorf("failure")
+	p := Aggregate([]*Promise{Resolved(1), Rejected(failure, typeOf[int]())}, nil, time.Millisecond, time.Second)
+	require.Equal(t, failure, cause(p.Wait(new(AggregateResult))))
+
+	slow := make(chan struct{})
+	defer close(slow)
+	p = Aggregate([]*Promise{New(func() { <-slow })}, nil, time.Millisecond, 10*time.Millisecond)
+	require.Equal(t, ErrTimeout, cause(p.Wait(new(AggregateResult))))
+}
@@ -0,0 +1,55 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+)
+
+// An AllCollector gathers promises created one at a time, such as the
+// requests of a crawl or a paginated listing, into a single All once
+// there are no more to come:
+//
+//	var c AllCollector
+//	for token := ""; ; {
+//		page := listPage(token)
+//		c.Add(New(fetchPage, page))
+//		if token = page.Next; token == "" {
+//			break
+//		}
+//	}
+//	var pages []Page
+//	err := c.Seal().Wait(&pages)
+//
+// Unlike Group, which starts the functions it is given, it takes
+// promises that are already running. The zero AllCollector is ready to
+// use and must not be copied after first use.
+type AllCollector struct {
+	mu       sync.Mutex
+	promises []*Promise
+	all      *Promise
+}
+
+// Add adds p to c, and may be called from any goroutine while the
+// promises added before it run. It panics if c is sealed.
+func (c *AllCollector) Add(p *Promise) {
+	p.observe()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.all != nil {
+		panic(errors.New("promise: Add on sealed AllCollector"))
+	}
+	c.promises = append(c.promises, p)
+}
+
+// Seal returns All of the promises added to c, in the order they were
+// added. Once sealed, c accepts no more promises, and later calls of Seal
+// return the same promise.
+func (c *AllCollector) Seal() *Promise {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.all == nil {
+		c.all = All(c.promises...)
+		c.promises = nil
+	}
+	return c.all
+}
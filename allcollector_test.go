@@ -0,0 +1,55 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllCollector(t *testing.T) {
+	var c AllCollector
+	var wg sync.WaitGroup
+	wg.Add(3)
+	c.Add(New(func() int { return 0 }))
+	for i := 1; i < 10; i++ {
+		i := i
+		c.Add(New(func() int {
+			// Promises added earlier can add more while they run.
+			if i%3 == 0 {
+				defer wg.Done()
+				c.Add(Resolved(i * 10))
+			}
+			return i
+		}))
+	}
+	wg.Wait()
+	all := c.Seal()
+	require.Same(t, all, c.Seal())
+	var values []int
+	require.NoError(t, all.Wait(&values))
+	require.Len(t, values, 13)
+	require.Equal(t, []int{0, 1, 2}, values[:3])
+	require.Panics(t, func() { c.Add(Resolved(0)) })
+}
+
+func TestAllCollectorRejects(t *testing.T) {
+	var c AllCollector
+	c.Add(Resolved(1))
+	c.Add(New(func() (int, error) { return 0, errors.New("failed") }))
+	err := c.Seal().Wait(new([]int))
+	require.Error(t, err)
+	require.Equal(t, "failed", cause(err).Error())
+}
+
+func TestAllCollectorEmpty(t *testing.T) {
+	var c AllCollector
+	require.NoError(t, c.Seal().Wait())
+
+	var d AllCollector
+	pages := []string{"stale"}
+	require.NoError(t, d.Seal().Wait(&pages))
+	require.NotNil(t, pages)
+	require.Empty(t, pages)
+}
@@ -0,0 +1,33 @@
+package promise
+
+import "sync/atomic"
+
+// WithAutoCancel makes p cancel itself, as if by Cancel, once nothing
+// needs its results any more: it had consumers, every promise chained
+// from it has settled without it, and no Wait or Result call is blocked
+// on it. The losing branches of Race and Any, and work whose consumers
+// were all canceled or timed out, then stop instead of running on, as
+// long as their functions watch the context passed by NewCtx. It returns
+// p for chaining.
+func (p *Promise) WithAutoCancel() *Promise {
+	p.checkCopy()
+	atomic.StoreInt32(&p.extras().autoCancel, 1)
+	return p
+}
+
+// releaseConsumer is called when a consumer of p stops needing it, and
+// cancels p if it was the last one.
+func (p *Promise) releaseConsumer() {
+	if atomic.LoadInt32(&p.peekExtras().autoCancel) == 0 || p.isSettled() || atomic.LoadInt32(&p.waiting) != 0 {
+		return
+	}
+	p.mu.Lock()
+	for _, child := range p.children {
+		if !child.isSettled() {
+			p.mu.Unlock()
+			return
+		}
+	}
+	p.mu.Unlock()
+	p.Cancel()
+}
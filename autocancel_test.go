@@ -0,0 +1,52 @@
+package promise
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoCancelRaceLoser(t *testing.T) {
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	loser := NewCtx(context.Background(), func(ctx context.Context) int {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+		return 0
+	}).WithAutoCancel()
+	<-started
+	var result int
+	require.NoError(t, Race(Resolved(1), loser).Wait(&result))
+	require.Equal(t, 1, result)
+	<-stopped
+	require.Equal(t, ErrCanceled, cause(loser.Wait(&result)))
+}
+
+func TestAutoCancelKeepsNeededPromises(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	p := New(func() int {
+		<-release
+		return 1
+	}).WithAutoCancel()
+	dropped := p.Then(func(i int) int { return i })
+	kept := p.Then(func(i int) int { return i })
+	dropped.Cancel()
+	require.Equal(t, ErrWaitTimeout, p.WaitTimeout(time.Millisecond, new(int)))
+	require.Equal(t, StatePending, p.State(), "p still has a consumer")
+
+	kept.Cancel()
+	require.Equal(t, ErrCanceled, cause(p.Wait(new(int))))
+}
+
+func TestAutoCancelAfterWaitGivesUp(t *testing.T) {
+	p := NewCtx(context.Background(), func(ctx context.Context) int {
+		<-ctx.Done()
+		return 0
+	}).WithAutoCancel()
+	require.Equal(t, ErrWaitTimeout, p.WaitTimeout(time.Millisecond, new(int)))
+	require.Equal(t, ErrCanceled, cause(p.Wait(new(int))))
+}
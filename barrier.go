@@ -0,0 +1,49 @@
+package promise
+
+import "sync"
+
+// A Barrier holds back the promises created with it until Start is called,
+// so that they begin together rather than staggered by the time it takes to
+// construct them, as benchmarks and coordinated load tests want:
+//
+//	b := NewBarrier()
+//	all := All(b.New(f, 1), b.New(f, 2), b.New(f, 3))
+//	b.Start()
+type Barrier struct {
+	mu      sync.Mutex
+	started bool
+	starts  []func()
+}
+
+// NewBarrier returns a Barrier that hasn't been started.
+func NewBarrier() *Barrier {
+	return &Barrier{}
+}
+
+// New is like the package's New, except that f isn't called until b is
+// started. Once b has started, New starts f immediately.
+func (b *Barrier) New(f interface{}, args ...interface{}) *Promise {
+	p, start := newCall(f, nil, args)
+	b.mu.Lock()
+	if b.started {
+		b.mu.Unlock()
+		start()
+		return p
+	}
+	b.starts = append(b.starts, start)
+	b.mu.Unlock()
+	return p
+}
+
+// Start starts every promise created with b so far. Only the first call
+// has any effect.
+func (b *Barrier) Start() {
+	b.mu.Lock()
+	starts := b.starts
+	b.starts = nil
+	b.started = true
+	b.mu.Unlock()
+	for _, start := range starts {
+		start()
+	}
+}
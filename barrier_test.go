@@ -0,0 +1,38 @@
+package promise
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBarrier(t *testing.T) {
+	var calls int32
+	f := func(i int) int {
+		atomic.AddInt32(&calls, 1)
+		return i
+	}
+	b := NewBarrier()
+	all := All(b.New(f, 1), b.New(f, 2), b.New(f, 3))
+
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	require.Equal(t, StatePending, all.State())
+
+	b.Start()
+	var results []int
+	require.NoError(t, all.Wait(&results))
+	require.Equal(t, []int{1, 2, 3}, results)
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestBarrierNewAfterStart(t *testing.T) {
+	b := NewBarrier()
+	b.Start()
+	b.Start()
+	var result int
+	require.NoError(t, b.New(func() int { return 1 }).Wait(&result))
+	require.Equal(t, 1, result)
+}
@@ -0,0 +1,88 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithConcurrency makes Batch process at most n chunks at once. Other
+// constructors ignore it.
+func WithConcurrency(n int) Option {
+	if n < 1 {
+		panic(fmt.Errorf("concurrency must be at least 1, got %d", n))
+	}
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// Batch splits items, a slice, into chunks of size items, the last of
+// which may be shorter, and calls fn on each chunk concurrently, for
+// bulk APIs that take a limited number of items per request:
+//
+//	users := Batch(ids, 100, lookupUsers, WithConcurrency(4), WithTimeout(time.Second))
+//
+// fn accepts a slice of the type of items and returns a slice, optionally
+// followed by an error. Batch resolves with the slices fn returns joined
+// in the order of items, or rejects as soon as a chunk fails, without
+// starting the chunks still waiting on the limit. opts configure the promise of each chunk,
+// as for NewWith; only WithConcurrency limits the chunks running at once.
+func Batch(items interface{}, size int, fn interface{}, opts ...Option) *Promise {
+	itemsRv := reflect.ValueOf(items)
+	if itemsRv.Kind() != reflect.Slice {
+		panic(fmt.Errorf("expected a slice, got %v", itemsRv.Kind()))
+	}
+	if size < 1 {
+		panic(fmt.Errorf("batch size must be at least 1, got %d", size))
+	}
+	fnRv := reflect.ValueOf(fn)
+	if fnRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", fnRv.Kind()))
+	}
+	fnType := fnRv.Type()
+	resultType, _ := getResultType(fnType)
+	if fnType.NumIn() != 1 || fnType.IsVariadic() || !itemsRv.Type().AssignableTo(fnType.In(0)) ||
+		len(resultType) != 1 || resultType[0].Kind() != reflect.Slice {
+		panic(fmt.Errorf("function must accept a %s and return a slice, got %s", itemsRv.Type(), fnType))
+	}
+	resultSlice := resultType[0]
+	if itemsRv.Len() == 0 {
+		return resolvedAs(resultSlice, reflect.MakeSlice(resultSlice, 0, 0).Interface())
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var factories []func() *Promise
+	for start := 0; start < itemsRv.Len(); start += size {
+		end := start + size
+		if end > itemsRv.Len() {
+			end = itemsRv.Len()
+		}
+		// A full slice expression keeps fn from appending over the next
+		// chunk, which is converted to the named slice type fn may take.
+		chunk := itemsRv.Slice3(start, end, end)
+		if fnType.In(0).Kind() != reflect.Interface {
+			chunk = chunk.Convert(fnType.In(0))
+		}
+		arg := chunk.Interface()
+		factories = append(factories, func() *Promise {
+			return NewWith(fn, append([]Option{Args(arg)}, opts...)...)
+		})
+	}
+	limit := o.concurrency
+	if limit == 0 {
+		limit = len(factories)
+	}
+	join := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{reflect.SliceOf(resultSlice)}, []reflect.Type{resultSlice}, true),
+		func(args []reflect.Value) []reflect.Value {
+			joined := reflect.MakeSlice(resultSlice, 0, itemsRv.Len())
+			for i := 0; i < args[0].Len(); i++ {
+				joined = reflect.AppendSlice(joined, args[0].Index(i))
+			}
+			return []reflect.Value{joined}
+		})
+	return AllWithLimit(limit, factories...).Then(join.Interface())
+}
@@ -0,0 +1,71 @@
+package promise
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	ids := make([]int, 250)
+	for i := range ids {
+		ids[i] = i
+	}
+	var calls, running, peak int32
+	lookup := func(chunk []int) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		if len(chunk) > 100 {
+			return nil, errors.New("chunk too large")
+		}
+		time.Sleep(time.Millisecond)
+		names := make([]string, len(chunk))
+		for i, id := range chunk {
+			names[i] = strconv.Itoa(id)
+		}
+		return names, nil
+	}
+	var names []string
+	require.NoError(t, Batch(ids, 100, lookup, WithConcurrency(2)).Wait(&names))
+	require.Len(t, names, 250)
+	for i, name := range names {
+		require.Equal(t, strconv.Itoa(i), name)
+	}
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	require.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+}
+
+func TestBatchRejects(t *testing.T) {
+	err := Batch([]int{1, 2, 3}, 1, func(chunk []int) ([]int, error) {
+		if chunk[0] == 2 {
+			return nil, errors.New("lookup failed")
+		}
+		return chunk, nil
+	}).Wait(new([]int))
+	require.Error(t, err)
+	require.Equal(t, "lookup failed", cause(err).Error())
+}
+
+func TestBatchEmpty(t *testing.T) {
+	var results []int
+	require.NoError(t, Batch([]string{}, 10, func([]string) []int { return nil }).Wait(&results))
+	require.Empty(t, results)
+}
+
+func TestBatchPanicsOnBadArguments(t *testing.T) {
+	require.Panics(t, func() { Batch(1, 10, func([]int) []int { return nil }) })
+	require.Panics(t, func() { Batch([]int{1}, 0, func([]int) []int { return nil }) })
+	require.Panics(t, func() { Batch([]int{1}, 10, func(int) int { return 0 }) })
+	require.Panics(t, func() { WithConcurrency(0) })
+}
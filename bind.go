@@ -0,0 +1,114 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// A structBinding populates a struct parameter from a promise's results.
+// By default the struct's exported fields take the results in order. A
+// struct whose fields are tagged `promise:"N"` instead binds the N'th
+// result to each tagged field and leaves untagged fields zero.
+type structBinding struct {
+	t reflect.Type
+	// fields holds the index of the field bound to each result, or -1 if
+	// the result is unused
+	fields []int
+}
+
+// bindsStruct reports whether a function of type fnType takes results
+// through a struct parameter: it accepts a single struct that the
+// results can't be passed as directly.
+func bindsStruct(fnType reflect.Type, results []reflect.Type) bool {
+	if fnType.NumIn() != 1 || fnType.IsVariadic() || fnType.In(0).Kind() != reflect.Struct {
+		return false
+	}
+	return len(results) != 1 || !results[0].AssignableTo(fnType.In(0))
+}
+
+// structDest reports whether out is a single pointer to a struct that
+// receives results through its fields, and returns the struct type.
+func structDest(results []reflect.Type, out []interface{}) (reflect.Type, bool) {
+	if len(out) != 1 {
+		return nil, false
+	}
+	outType := reflect.TypeOf(out[0])
+	if outType == nil || outType.Kind() != reflect.Ptr || outType.Elem().Kind() != reflect.Struct || reflect.ValueOf(out[0]).IsNil() {
+		return nil, false
+	}
+	t := outType.Elem()
+	return t, len(results) != 1 || !results[0].AssignableTo(t)
+}
+
+// newStructBinding binds results to the fields of t, panicking if they
+// don't fit.
+func newStructBinding(t reflect.Type, results []reflect.Type) *structBinding {
+	b, err := bindStruct(t, results)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// bindStruct binds results to the fields of t.
+func bindStruct(t reflect.Type, results []reflect.Type) (*structBinding, error) {
+	b := &structBinding{t: t, fields: make([]int, len(results))}
+	for i := range b.fields {
+		b.fields[i] = -1
+	}
+	tagged := false
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("promise"); ok {
+			tagged = true
+		}
+	}
+	next := 0
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		result := next
+		if tagged {
+			tag, ok := field.Tag.Lookup("promise")
+			if !ok {
+				continue
+			}
+			n, err := strconv.Atoi(tag)
+			if err != nil || n < 0 || n >= len(results) {
+				return nil, fmt.Errorf("field %s: tag %q is not a result index below %d", field.Name, tag, len(results))
+			}
+			result = n
+		} else {
+			next++
+			if result >= len(results) {
+				return nil, fmt.Errorf("promise returns %d values, but %s has more exported fields", len(results), t)
+			}
+		}
+		if !results[result].AssignableTo(field.Type) {
+			return nil, fmt.Errorf("for field %s: expected type %s got type %s", field.Name, results[result], field.Type)
+		}
+		b.fields[result] = i
+	}
+	if !tagged && next != len(results) {
+		return nil, fmt.Errorf("promise returns %d values, but %s has %d exported fields", len(results), t, next)
+	}
+	return b, nil
+}
+
+// bind returns a new struct holding results.
+func (b *structBinding) bind(results []reflect.Value) reflect.Value {
+	v := reflect.New(b.t).Elem()
+	b.fill(v, results)
+	return v
+}
+
+// fill sets the bound fields of the struct v to results.
+func (b *structBinding) fill(v reflect.Value, results []reflect.Value) {
+	for i, field := range b.fields {
+		if field >= 0 {
+			v.Field(field).Set(results[i])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package promise
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type profile struct {
+	Name    string
+	Age     int
+	private bool
+	Admin   bool
+}
+
+func TestThenBindsStructInOrder(t *testing.T) {
+	p := All(Resolved("ada"), Resolved(36), Resolved(true)).Then(func(pr profile) string {
+		if pr.Admin {
+			return pr.Name + " (admin)"
+		}
+		return pr.Name
+	})
+	var result string
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, "ada (admin)", result)
+}
+
+func TestThenBindsStructByTag(t *testing.T) {
+	type args struct {
+		Age   int    `promise:"1"`
+		Name  string `promise:"0"`
+		Extra string
+	}
+	var got args
+	p := All(Resolved("ada"), Resolved(36), Resolved(true)).Then(func(a args) {
+		got = a
+	})
+	require.NoError(t, p.Wait())
+	require.Equal(t, args{Age: 36, Name: "ada"}, got)
+}
+
+func TestThenPassesSingleStructDirectly(t *testing.T) {
+	var got profile
+	require.NoError(t, Resolved(profile{Name: "bob"}).Then(func(p profile) { got = p }).Wait())
+	require.Equal(t, "bob", got.Name)
+}
+
+func TestThenStructBindingChecksFields(t *testing.T) {
+	p := All(Resolved("ada"), Resolved(36))
+	require.Panics(t, func() { p.Then(func(profile) {}) })
+	require.Panics(t, func() {
+		p.Then(func(struct {
+			Name string
+			Age  string
+		}) {
+		})
+	})
+	require.Panics(t, func() {
+		p.Then(func(struct {
+			Name string `promise:"2"`
+		}) {
+		})
+	})
+}
+
+func TestWaitIntoStruct(t *testing.T) {
+	var pr profile
+	require.NoError(t, All(Resolved("ada"), Resolved(36), Resolved(true)).Wait(&pr))
+	require.Equal(t, profile{Name: "ada", Age: 36, Admin: true}, pr)
+
+	var tagged struct {
+		Age  int    `promise:"1"`
+		Name string `promise:"0"`
+		Note string
+	}
+	require.NoError(t, All(Resolved("ada"), Resolved(36), Resolved(true)).Wait(&tagged))
+	require.Equal(t, "ada", tagged.Name)
+	require.Equal(t, 36, tagged.Age)
+	require.Empty(t, tagged.Note)
+}
+
+func TestWaitIntoStructResult(t *testing.T) {
+	var pr profile
+	require.NoError(t, Resolved(profile{Name: "ada"}).Wait(&pr))
+	require.Equal(t, "ada", pr.Name)
+}
+
+func TestWaitIntoMismatchedStruct(t *testing.T) {
+	var wrong struct {
+		Name int
+		Age  int
+	}
+	p := All(Resolved("ada"), Resolved(36))
+	require.Panics(t, func() { _ = p.Wait(&wrong) })
+	require.Error(t, p.WaitScan(&wrong))
+}
@@ -0,0 +1,139 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen rejects the promises of calls that a circuit breaker
+// made by WithBreaker turns away.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerConfig configures a circuit breaker made by WithBreaker.
+type BreakerConfig struct {
+	// FailureThreshold is how many calls in a row must fail to open the
+	// breaker. Values below 1 count as 1.
+	FailureThreshold int
+	// OpenDuration is how long the breaker stays open before it lets
+	// probing calls through.
+	OpenDuration time.Duration
+	// HalfOpenProbes is how many calls may probe the backend once
+	// OpenDuration has passed, and how many of them must succeed to close
+	// the breaker again. Values below 1 count as 1.
+	HalfOpenProbes int
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type breaker struct {
+	config BreakerConfig
+
+	mu    sync.Mutex
+	state breakerState
+	// generation changes with every change of state, so that calls
+	// started before it don't count towards the new state.
+	generation int
+	failures   int
+	openedAt   time.Time
+	probes     int
+	successes  int
+}
+
+// WithBreaker returns a function that calls New(f, args...) behind a
+// circuit breaker, for calls to a flaky backend. Once
+// config.FailureThreshold calls in a row are rejected, the breaker opens,
+// and calls for the next config.OpenDuration return promises rejected with
+// ErrCircuitOpen without calling f. Then up to config.HalfOpenProbes calls
+// go through as probes, turning the rest away: if they all succeed the
+// breaker closes again, and if one fails it opens again. Promises stopped
+// by Cancel don't count as failures, and a canceled probe lets another call
+// probe in its place.
+func WithBreaker(f interface{}, config BreakerConfig) func(args ...interface{}) *Promise {
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	if config.HalfOpenProbes < 1 {
+		config.HalfOpenProbes = 1
+	}
+	c := newCaller(f)
+	resultType := c.resultType()
+	b := &breaker{config: config}
+	return func(args ...interface{}) *Promise {
+		generation, ok := b.allow()
+		if !ok {
+			return Rejected(ErrCircuitOpen, resultType...)
+		}
+		p, start := c.newCall(nil, args)
+		start()
+		p.whenSettled(func() {
+			b.record(generation, p.err)
+		})
+		return p
+	}
+}
+
+// allow reports whether a call may go through, and the generation it
+// counts towards.
+func (b *breaker) allow() (generation int, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerOpen && !currentTime().Before(b.openedAt.Add(b.config.OpenDuration)) {
+		b.setState(breakerHalfOpen)
+	}
+	switch b.state {
+	case breakerOpen:
+		return 0, false
+	case breakerHalfOpen:
+		if b.probes == b.config.HalfOpenProbes {
+			return 0, false
+		}
+		b.probes++
+	}
+	return b.generation, true
+}
+
+// record counts the outcome of a call allowed in generation.
+func (b *breaker) record(generation int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+	if errors.Is(err, ErrCanceled) {
+		if b.state == breakerHalfOpen {
+			// Hand the probe's slot to the next call.
+			b.probes--
+		}
+		return
+	}
+	switch {
+	case err != nil && b.state == breakerHalfOpen:
+		b.setState(breakerOpen)
+	case err != nil:
+		if b.failures++; b.failures == b.config.FailureThreshold {
+			b.setState(breakerOpen)
+		}
+	case b.state == breakerHalfOpen:
+		if b.successes++; b.successes == b.config.HalfOpenProbes {
+			b.setState(breakerClosed)
+		}
+	default:
+		b.failures = 0
+	}
+}
+
+func (b *breaker) setState(state breakerState) {
+	b.state = state
+	b.generation++
+	b.failures, b.probes, b.successes = 0, 0, 0
+	if state == breakerOpen {
+		b.openedAt = currentTime()
+	}
+}
@@ -0,0 +1,102 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBreaker(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+	healthy := false
+	calls := 0
+	fetch := WithBreaker(func(key string) (string, error) {
+		calls++
+		if !healthy {
+			return "", errors.New("backend down")
+		}
+		return "value of " + key, nil
+	}, BreakerConfig{FailureThreshold: 2, OpenDuration: time.Minute, HalfOpenProbes: 2})
+	call := func() error {
+		var s string
+		return cause(fetch("k").Wait(&s))
+	}
+
+	require.EqualError(t, call(), "backend down")
+	require.EqualError(t, call(), "backend down")
+	require.Equal(t, ErrCircuitOpen, call())
+	require.Equal(t, 2, calls)
+
+	// Once OpenDuration passes, a failing probe opens the breaker again.
+	clock.Advance(time.Minute)
+	require.EqualError(t, call(), "backend down")
+	require.Equal(t, ErrCircuitOpen, call())
+
+	clock.Advance(time.Minute)
+	healthy = true
+	require.NoError(t, call())
+	require.NoError(t, call())
+	require.Equal(t, 5, calls)
+
+	// Closed again, a single failure doesn't open it.
+	healthy = false
+	require.EqualError(t, call(), "backend down")
+	healthy = true
+	require.NoError(t, call())
+}
+
+func TestBreakerLimitsProbes(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+	release := NewDeferred()
+	fail := true
+	fetch := WithBreaker(func() error {
+		if fail {
+			return errors.New("backend down")
+		}
+		return release.Wait()
+	}, BreakerConfig{OpenDuration: time.Second})
+	require.Error(t, fetch().Wait())
+
+	clock.Advance(time.Second)
+	fail = false
+	probe := fetch()
+	require.Equal(t, ErrCircuitOpen, cause(fetch().Wait()))
+	release.Resolve()
+	require.NoError(t, probe.Wait())
+	require.NoError(t, fetch().Wait())
+}
+
+func TestBreakerCanceledProbe(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	fail := true
+	fetch := WithBreaker(func() error {
+		if fail {
+			return errors.New("backend down")
+		}
+		close(started)
+		<-release
+		return nil
+	}, BreakerConfig{OpenDuration: time.Second})
+	require.Error(t, fetch().Wait())
+
+	clock.Advance(time.Second)
+	fail = false
+	probe := fetch()
+	require.Equal(t, ErrCircuitOpen, cause(fetch().Wait()))
+	<-started
+	probe.Cancel()
+	require.Equal(t, ErrCanceled, cause(probe.Wait()))
+
+	fail = true
+	require.EqualError(t, cause(fetch().Wait()), "backend down", "the canceled probe's slot is free")
+}
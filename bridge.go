@@ -0,0 +1,154 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// An Overflow decides what a ChannelBridge does with a value received
+// while its buffer is full.
+type Overflow int
+
+const (
+	// OverflowBlock stops receiving until there is room, so a fast
+	// producer is held back by its channel instead of filling memory.
+	OverflowBlock Overflow = iota
+	// OverflowDropNewest discards the value just received.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest buffered value to make room.
+	OverflowDropOldest
+)
+
+// A ChannelBridge receives values from a channel into a bounded buffer and
+// hands them out one promise at a time, for consuming a stream of values
+// where FromChannel only takes the first.
+type ChannelBridge struct {
+	elem     reflect.Type
+	size     int
+	overflow Overflow
+	drained  *Deferred
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  []reflect.Value
+	// waiters are the promises returned by Next before a value was ready,
+	// in order
+	waiters []*Deferred
+	closed  bool
+	dropped int
+}
+
+// NewChannelBridge starts receiving from ch, which must be a channel that
+// can be received from, into a buffer of size values, handling values
+// that don't fit as overflow says. It keeps receiving until ch is closed.
+func NewChannelBridge(ch interface{}, size int, overflow Overflow) *ChannelBridge {
+	chRv := reflect.ValueOf(ch)
+	if chRv.Kind() != reflect.Chan || chRv.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic(fmt.Errorf("expected a channel to receive from, got %s", chRv.Type()))
+	}
+	if size < 0 {
+		size = 0
+	}
+	b := &ChannelBridge{
+		elem:     chRv.Type().Elem(),
+		size:     size,
+		overflow: overflow,
+		drained:  NewDeferred(),
+	}
+	b.cond = sync.NewCond(&b.mu)
+	spawn(func() { b.receive(chRv) })
+	return b
+}
+
+func (b *ChannelBridge) receive(chRv reflect.Value) {
+	for {
+		if b.overflow == OverflowBlock {
+			b.mu.Lock()
+			for len(b.buf) >= b.size+len(b.waiters) {
+				b.cond.Wait()
+			}
+			b.mu.Unlock()
+		}
+		value, ok := chRv.Recv()
+		b.mu.Lock()
+		if !ok {
+			b.closed = true
+			waiters := b.waiters
+			b.waiters = nil
+			drained := b.isDrained()
+			b.mu.Unlock()
+			for _, d := range waiters {
+				d.settle(nil, ErrChannelClosed)
+			}
+			if drained {
+				b.drained.Resolve()
+			}
+			return
+		}
+		if len(b.waiters) > 0 {
+			d := b.waiters[0]
+			b.waiters = b.waiters[1:]
+			b.mu.Unlock()
+			d.settle([]reflect.Value{value}, nil)
+			continue
+		}
+		switch {
+		case len(b.buf) < b.size:
+			b.buf = append(b.buf, value)
+		case b.overflow == OverflowDropOldest && b.size > 0:
+			b.buf = append(b.buf[1:], value)
+			b.dropped++
+		default:
+			b.dropped++
+		}
+		b.mu.Unlock()
+	}
+}
+
+// Next returns a promise that resolves with the next value from the
+// channel, or is rejected with ErrChannelClosed once the channel is closed
+// and every value before it has been handed out.
+func (b *ChannelBridge) Next() *Promise {
+	b.mu.Lock()
+	if len(b.buf) > 0 {
+		value := b.buf[0]
+		b.buf = b.buf[1:]
+		b.cond.Signal()
+		drained := b.isDrained()
+		b.mu.Unlock()
+		if drained {
+			b.drained.Resolve()
+		}
+		return resolvedAs(b.elem, value.Interface())
+	}
+	defer b.mu.Unlock()
+	if b.closed {
+		return Rejected(ErrChannelClosed, b.elem)
+	}
+	d := NewDeferred(b.elem)
+	b.waiters = append(b.waiters, d)
+	b.cond.Signal()
+	return d.Promise
+}
+
+// Drain returns a promise that resolves once the channel has been closed
+// and every value received from it has been handed out by Next, so a
+// producer can close the channel and wait for its values to be consumed.
+func (b *ChannelBridge) Drain() *Promise {
+	return b.drained.Promise
+}
+
+// Dropped returns how many values have been discarded because the buffer
+// was full.
+func (b *ChannelBridge) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// isDrained reports whether the channel is closed and the buffer empty.
+// b.mu must be held.
+func (b *ChannelBridge) isDrained() bool {
+	return b.closed && len(b.buf) == 0
+}
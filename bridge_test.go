@@ -0,0 +1,80 @@
+package promise
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelBridge(t *testing.T) {
+	ch := make(chan int)
+	b := NewChannelBridge(ch, 2, OverflowBlock)
+	first := b.Next()
+	go func() {
+		for i := 1; i <= 3; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	var result int
+	require.NoError(t, first.Wait(&result))
+	require.Equal(t, 1, result)
+	require.NoError(t, b.Next().Wait(&result))
+	require.Equal(t, 2, result)
+	require.NoError(t, b.Next().Wait(&result))
+	require.Equal(t, 3, result)
+	require.Equal(t, ErrChannelClosed, cause(b.Next().Wait(&result)))
+	require.NoError(t, b.Drain().Wait())
+	require.Equal(t, 0, b.Dropped())
+}
+
+func TestChannelBridgeBlocks(t *testing.T) {
+	ch := make(chan int)
+	b := NewChannelBridge(ch, 1, OverflowBlock)
+	ch <- 1
+	select {
+	case ch <- 2:
+		t.Fatal("the bridge received past its full buffer")
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.NoError(t, b.Next().Wait(new(int)))
+	ch <- 2
+	close(ch)
+	require.Equal(t, StatePending, b.Drain().State())
+	var result int
+	require.NoError(t, b.Next().Wait(&result))
+	require.Equal(t, 2, result)
+	require.NoError(t, b.Drain().Wait())
+}
+
+func TestChannelBridgeDrops(t *testing.T) {
+	for _, test := range []struct {
+		overflow Overflow
+		want     []int
+	}{
+		{OverflowDropNewest, []int{1, 2}},
+		{OverflowDropOldest, []int{3, 4}},
+	} {
+		ch := make(chan int)
+		b := NewChannelBridge(ch, 2, test.overflow)
+		for i := 1; i <= 4; i++ {
+			ch <- i
+		}
+		close(ch)
+		for b.Dropped() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		var got []int
+		for {
+			var result int
+			if err := b.Next().Wait(&result); err != nil {
+				break
+			}
+			got = append(got, result)
+		}
+		require.Equal(t, test.want, got)
+		require.Equal(t, 2, b.Dropped())
+		require.NoError(t, b.Drain().Wait())
+	}
+}
@@ -0,0 +1,68 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// A Budget is a token bucket capping the extra load that Retry, Hedge and
+// Speculate add on top of first attempts. They take a token before every
+// extra attempt and give up on it when none is left:
+//
+//	budget := NewBudget(10, 1)
+//	p := Retry(RetryConfig{Attempts: 3, Budget: budget}, fetch, url)
+//
+// Tokens refill at a steady rate, on the package's Clock, up to the
+// bucket's capacity. A single Budget is meant to be shared by every caller
+// of the same backend, so that during an incident the total extra load
+// stays bounded, as with gRPC retry throttling.
+type Budget struct {
+	capacity float64
+	rate     float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewBudget returns a full budget holding up to capacity tokens, which
+// refills at perSecond tokens per second.
+func NewBudget(capacity, perSecond float64) *Budget {
+	return &Budget{
+		capacity: capacity,
+		rate:     perSecond,
+		tokens:   capacity,
+		last:     currentTime(),
+	}
+}
+
+// TryTake takes a token for an extra attempt and reports whether one was
+// available. Callers must not launch the attempt when it reports false.
+func (b *Budget) TryTake() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Tokens returns the number of tokens currently available.
+func (b *Budget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	return b.tokens
+}
+
+// refill adds the tokens accrued since the last refill. b.mu must be held.
+func (b *Budget) refill() {
+	now := currentTime()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
@@ -0,0 +1,50 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetCapsExtraAttempts(t *testing.T) {
+	b := NewBudget(3, 0)
+	require.True(t, b.TryTake())
+	require.True(t, b.TryTake())
+	require.True(t, b.TryTake())
+	require.False(t, b.TryTake())
+	require.Equal(t, 0.0, b.Tokens())
+}
+
+func TestBudgetRefills(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	b := NewBudget(2, 20)
+	require.True(t, b.TryTake())
+	require.True(t, b.TryTake())
+	require.False(t, b.TryTake())
+	clock.Advance(40 * time.Millisecond)
+	require.False(t, b.TryTake())
+	clock.Advance(20 * time.Millisecond)
+	require.True(t, b.TryTake())
+	clock.Advance(time.Second)
+	require.Equal(t, 2.0, b.Tokens())
+}
+
+func TestBudgetLimitsRetries(t *testing.T) {
+	b := NewBudget(1, 0)
+	failure := errors.New("failure")
+	var attempts int32
+	fetch := func() (int, error) {
+		atomic.AddInt32(&attempts, 1)
+		return 0, failure
+	}
+	config := RetryConfig{Attempts: 3, Budget: b}
+	require.Equal(t, failure, cause(Retry(config, fetch).Wait(new(int))))
+	require.Equal(t, failure, cause(Retry(config, fetch).Wait(new(int))))
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts), "only the first retry fits in the budget")
+}
@@ -0,0 +1,111 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// A Cache holds promises by key so that concurrent callers share one
+// promise per key instead of stampeding the work behind it. Rejected
+// promises are dropped, so the next call for their key tries again.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[interface{}]*cacheEntry
+}
+
+type cacheEntry struct {
+	ref promiseRef
+	// refreshing is set while a stale entry is being replaced
+	refreshing bool
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{entries: map[interface{}]*cacheEntry{}}
+}
+
+// GetOrCreate returns the promise cached under key if it is pending, or
+// resolved less than ttl ago. Otherwise it caches and returns the promise
+// returned by factory. factory is called with c locked, so it must not use
+// c.
+func (c *Cache) GetOrCreate(key interface{}, ttl time.Duration, factory func() *Promise) *Promise {
+	return c.GetOrCreateStale(key, ttl, 0, factory)
+}
+
+// GetOrCreateStale is like GetOrCreate, but serves a promise that expired
+// less than staleTTL ago straight away while factory replaces it in the
+// background, so hot keys never wait on a refresh. If the refresh is
+// rejected, the stale promise is kept until it ages out.
+func (c *Cache) GetOrCreateStale(key interface{}, ttl, staleTTL time.Duration, factory func() *Promise) *Promise {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		if e.ref.stale() {
+			// Dropped so that later calls don't panic too.
+			delete(c.entries, key)
+			c.mu.Unlock()
+			panic(errUsedAfterRelease)
+		}
+		p := e.ref.p
+		if !p.isSettled() {
+			c.mu.Unlock()
+			return p
+		}
+		age := currentTime().Sub(p.settled)
+		if p.err == nil && age < ttl {
+			c.mu.Unlock()
+			return p
+		}
+		if p.err == nil && age < ttl+staleTTL {
+			refresh := !e.refreshing
+			e.refreshing = true
+			c.mu.Unlock()
+			if refresh {
+				c.refresh(key, e, factory)
+			}
+			return p
+		}
+	}
+	p := factory()
+	e := &cacheEntry{ref: p.ref()}
+	c.entries[key] = e
+	c.mu.Unlock()
+	p.whenSettled(func() {
+		if p.err != nil {
+			c.drop(key, e)
+		}
+	})
+	return p
+}
+
+// refresh replaces the stale entry e with the promise returned by factory
+// once it resolves. If factory panics, a later call retries the refresh.
+func (c *Cache) refresh(key interface{}, e *cacheEntry, factory func() *Promise) {
+	started := false
+	defer func() {
+		if !started {
+			c.mu.Lock()
+			e.refreshing = false
+			c.mu.Unlock()
+		}
+	}()
+	p := factory()
+	p.observe()
+	started = true
+	p.whenSettled(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		e.refreshing = false
+		if p.err == nil && c.entries[key] == e {
+			c.entries[key] = &cacheEntry{ref: p.ref()}
+		}
+	})
+}
+
+// drop removes e from c if it is still cached under key.
+func (c *Cache) drop(key interface{}, e *cacheEntry) {
+	c.mu.Lock()
+	if c.entries[key] == e {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+}
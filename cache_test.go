@@ -0,0 +1,101 @@
+package promise
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheGetOrCreate(t *testing.T) {
+	c := NewCache()
+	var calls int32
+	factory := func() *Promise {
+		n := atomic.AddInt32(&calls, 1)
+		return New(func() int32 { return n })
+	}
+	p := c.GetOrCreate("k", 50*time.Millisecond, factory)
+	require.Same(t, p, c.GetOrCreate("k", 50*time.Millisecond, factory), "a pending promise is shared")
+	require.NoError(t, p.Wait(new(int32)))
+	require.Same(t, p, c.GetOrCreate("k", 50*time.Millisecond, factory))
+
+	time.Sleep(60 * time.Millisecond)
+	var result int32
+	require.NoError(t, c.GetOrCreate("k", 50*time.Millisecond, factory).Wait(&result))
+	require.Equal(t, int32(2), result, "an expired promise is replaced")
+}
+
+func TestCacheDropsRejected(t *testing.T) {
+	c := NewCache()
+	failed := c.GetOrCreate("k", time.Minute, func() *Promise {
+		return Rejected(fmt.Errorf("failure"), typeOf[int]())
+	})
+	require.Error(t, failed.Wait(new(int)))
+	var result int
+	require.NoError(t, c.GetOrCreate("k", time.Minute, func() *Promise { return Resolved(1) }).Wait(&result))
+	require.Equal(t, 1, result)
+}
+
+func TestCacheGetOrCreateStale(t *testing.T) {
+	c := NewCache()
+	var calls int32
+	release := make(chan struct{})
+	factory := func() *Promise {
+		n := atomic.AddInt32(&calls, 1)
+		return New(func() int32 {
+			if n > 1 {
+				<-release
+			}
+			return n
+		})
+	}
+	get := func() int32 {
+		var result int32
+		require.NoError(t, c.GetOrCreateStale("k", 20*time.Millisecond, time.Minute, factory).Wait(&result))
+		return result
+	}
+	require.Equal(t, int32(1), get())
+	time.Sleep(30 * time.Millisecond)
+
+	require.Equal(t, int32(1), get(), "the stale promise is served while refreshing")
+	require.Equal(t, int32(1), get())
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls), "only one refresh runs at a time")
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for get() != 2 {
+		require.True(t, time.Now().Before(deadline), "the refreshed promise replaces the stale one")
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheRefreshPanics(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	c := NewCache()
+	var calls int32
+	factory := func() *Promise {
+		switch atomic.AddInt32(&calls, 1) {
+		case 2:
+			panic("refresh failed")
+		case 3:
+			return Resolved(int32(3))
+		}
+		return Resolved(int32(1))
+	}
+	get := func() int32 {
+		var result int32
+		require.NoError(t, c.GetOrCreateStale("k", time.Minute, time.Hour, factory).Wait(&result))
+		return result
+	}
+	require.Equal(t, int32(1), get())
+	clock.Advance(2 * time.Minute)
+	require.Panics(t, func() { get() })
+	require.Equal(t, int32(1), get(), "the stale promise is served while refreshing again")
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls), "the refresh is retried after a panic")
+	require.Equal(t, int32(3), get())
+}
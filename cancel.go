@@ -0,0 +1,110 @@
+package promise
+
+import "errors"
+
+// ErrCanceled is the error of a promise stopped by Cancel.
+var ErrCanceled = errors.New("promise canceled")
+
+// Cancel rejects p with ErrCanceled if it hasn't settled yet, and cancels
+// every promise chained from it that hasn't settled, so their functions
+// never run. Functions passed to Finally are the exception: they still
+// run, and their promises then settle with ErrCanceled. Functions already
+// running are not interrupted, but a promise created by NewCtx sees its
+// context canceled.
+func (p *Promise) Cancel() {
+	p.checkCopy()
+	p.settle(nil, ErrCanceled)
+	p.mu.Lock()
+	children := make([]*Promise, 0, len(p.children))
+	for _, child := range p.children {
+		// Finally still runs its function for a canceled promise, and
+		// then settles with ErrCanceled itself.
+		if child.t != finallyCall && child.tryAcquire() {
+			children = append(children, child)
+		}
+	}
+	p.mu.Unlock()
+	for _, child := range children {
+		child.Cancel()
+		child.releaseRef()
+	}
+}
+
+// cancelOnSettle cancels those of promises still pending once p settles,
+// for combinators that stop needing their inputs once they have a result.
+// Promises that anything besides p is chained from are left to run, as
+// another consumer may still need them.
+func (p *Promise) cancelOnSettle(promises []*Promise) {
+	p.whenSettled(func() {
+		for _, prior := range promises {
+			if !prior.isSettled() && prior.onlyChild(p) {
+				prior.Cancel()
+			}
+		}
+	})
+}
+
+// onlyChild reports whether child is the only promise chained from p.
+func (p *Promise) onlyChild(child *Promise) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range p.children {
+		if c != child {
+			return false
+		}
+	}
+	return true
+}
+
+// CancelSubtree cancels, as if by Cancel, every promise in g named name
+// that hasn't settled, and with it every promise chained from them. It
+// returns the number of promises named name that it canceled.
+func (g *Graph) CancelSubtree(name string) int {
+	canceled := 0
+	for _, p := range g.members() {
+		if p.Name() == name && !p.isSettled() {
+			p.Cancel()
+			canceled++
+		}
+	}
+	return canceled
+}
+
+// CancelSubtree is like Graph.CancelSubtree over every graph with a
+// pending promise created in debug mode, for an operator to stop a
+// runaway branch of a long-running process, such as from a debug
+// endpoint. See EnableDebug.
+func CancelSubtree(name string) int {
+	tracked.Lock()
+	graphs := map[*Graph]bool{}
+	for p := range tracked.pending {
+		if p.graph != nil {
+			graphs[p.graph] = true
+		}
+	}
+	tracked.Unlock()
+	canceled := 0
+	for g := range graphs {
+		canceled += g.CancelSubtree(name)
+	}
+	return canceled
+}
+
+// members returns the promises reachable from g's root.
+func (g *Graph) members() []*Promise {
+	seen := map[*Promise]bool{g.root: true}
+	members := []*Promise{g.root}
+	for i := 0; i < len(members); i++ {
+		p := members[i]
+		p.mu.Lock()
+		children := append([]*Promise{}, p.children...)
+		p.mu.Unlock()
+		for _, child := range children {
+			if !seen[child] {
+				seen[child] = true
+				members = append(members, child)
+			}
+		}
+	}
+	return members
+}
@@ -0,0 +1,112 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelPreventsChainedFunctions(t *testing.T) {
+	release := make(chan struct{})
+	root := New(func() int {
+		<-release
+		return 1
+	})
+	ran := make(chan struct{}, 2)
+	first := root.Then(func(x int) int {
+		ran <- struct{}{}
+		return x
+	})
+	second := first.Then(func(x int) int {
+		ran <- struct{}{}
+		return x
+	})
+
+	root.Cancel()
+	close(release)
+
+	require.Equal(t, ErrCanceled, cause(root.Wait(new(int))))
+	require.Equal(t, ErrCanceled, cause(first.Wait(new(int))))
+	require.Equal(t, ErrCanceled, cause(second.Wait(new(int))))
+	require.Len(t, ran, 0)
+}
+
+func TestCancelSettledPromiseCancelsChildren(t *testing.T) {
+	root := New(func() int { return 1 })
+	require.NoError(t, root.Wait(new(int)))
+	release := make(chan struct{})
+	child := root.Then(func(x int) int {
+		<-release
+		return x
+	})
+	root.Cancel()
+	close(release)
+	require.NoError(t, root.Wait(new(int)), "settled promises keep their result")
+	require.Equal(t, ErrCanceled, cause(child.Wait(new(int))))
+}
+
+func TestCancelAbortsNewCtxFunction(t *testing.T) {
+	p := NewCtx(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	p.Cancel()
+	require.Equal(t, ErrCanceled, cause(p.Wait()))
+}
+
+func TestCancelSubtree(t *testing.T) {
+	d := NewDeferred(typeOf[int]())
+	branch := d.Promise.Then(func(x int) int { return x }).WithName("runaway")
+	below := branch.Then(func(x int) int { return x })
+	sibling := d.Promise.Then(func(x int) int { return x })
+
+	require.Equal(t, 1, d.Promise.Graph().CancelSubtree("runaway"))
+	require.Equal(t, 0, d.Promise.Graph().CancelSubtree("runaway"), "already canceled")
+	d.Resolve(1)
+
+	require.Equal(t, ErrCanceled, cause(branch.Wait(new(int))))
+	require.Equal(t, ErrCanceled, cause(below.Wait(new(int))))
+	require.NoError(t, sibling.Wait(new(int)))
+}
+
+func TestCancelSubtreeAcrossGraphs(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+	first := NewDeferred()
+	second := NewDeferred()
+	a := first.Promise.Then(func() {}).WithName("TestCancelSubtreeAcrossGraphs")
+	b := second.Promise.Then(func() {}).WithName("TestCancelSubtreeAcrossGraphs")
+
+	require.Equal(t, 2, CancelSubtree("TestCancelSubtreeAcrossGraphs"))
+	require.Equal(t, ErrCanceled, cause(a.Wait()))
+	require.Equal(t, ErrCanceled, cause(b.Wait()))
+	first.Resolve()
+	second.Resolve()
+}
+
+func TestRaceCancelsLosers(t *testing.T) {
+	loser := NewDeferred(typeOf[int]())
+	shared := NewDeferred(typeOf[int]())
+	chained := shared.Promise.Then(func(x int) int { return x })
+	p := Race(Resolved(1), loser.Promise, shared.Promise)
+	var out int
+	require.NoError(t, p.Wait(&out))
+	require.Equal(t, 1, out)
+	require.Equal(t, ErrCanceled, cause(loser.Promise.Wait(new(int))))
+
+	// shared is left alone, as chained still needs it.
+	shared.Resolve(2)
+	require.NoError(t, chained.Wait(&out))
+	require.Equal(t, 2, out)
+}
+
+func TestAnyCancelsLosers(t *testing.T) {
+	loser := NewDeferred(typeOf[int]())
+	p := Any(Rejected(errors.New("down"), typeOf[int]()), Resolved(2), loser.Promise)
+	var out int
+	require.NoError(t, p.Wait(&out))
+	require.Equal(t, 2, out)
+	require.Equal(t, ErrCanceled, cause(loser.Promise.Wait(new(int))))
+}
@@ -0,0 +1,153 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var errorType = typeOf[error]()
+
+// Catch returns a promise that recovers from p failing. If p is rejected,
+// f is called with its error and the returned promise resolves with f's
+// results, so the chain can continue with fallback values. If p resolves,
+// the returned promise resolves with p's results and f is not called.
+//
+// f must accept a single error and return the same types as p, optionally
+// followed by an error to reject the returned promise.
+func (p *Promise) Catch(f interface{}) *Promise {
+	p.checkCopy()
+	p.observe()
+	functionRv := reflect.ValueOf(f)
+	returnsError := checkCatchHandler(functionRv, errorType, p.resultType)
+	return p.catch(functionRv, funcName(functionRv), returnsError)
+}
+
+// CatchIf is like Catch, but only calls f for errors that matcher accepts.
+// Other errors reject the returned promise as they rejected p, so
+// handlers for different kinds of errors can be chained:
+//
+//	p.CatchIf(isNotFound, useDefault).CatchIf(isTransient, retry)
+func (p *Promise) CatchIf(matcher func(error) bool, f interface{}) *Promise {
+	p.checkCopy()
+	p.observe()
+	functionRv := reflect.ValueOf(f)
+	returnsError := checkCatchHandler(functionRv, errorType, p.resultType)
+	adapter := catchAdapter(functionRv, returnsError, p.resultType, func(err error) (reflect.Value, bool) {
+		if !matcher(err) {
+			return reflect.Value{}, false
+		}
+		errRv := reflect.New(errorType).Elem()
+		errRv.Set(reflect.ValueOf(err))
+		return errRv, true
+	})
+	return p.catch(adapter, funcName(functionRv), true)
+}
+
+// CatchAs is like CatchIf, but matches errors as errors.As would with a
+// pointer to a variable of the type of target, and calls f with the
+// matching error as that type:
+//
+//	p.CatchAs(&net.OpError{}, func(err *net.OpError) ([]byte, error) { ... })
+//
+// target is only used for its type, which must implement error, or be a
+// pointer to an interface type, such as (*net.Error)(nil), to match that
+// interface.
+func (p *Promise) CatchAs(target interface{}, f interface{}) *Promise {
+	p.checkCopy()
+	p.observe()
+	targetType := reflect.TypeOf(target)
+	if targetType == nil {
+		panic(errors.New("CatchAs target must not be nil"))
+	}
+	if targetType.Kind() == reflect.Ptr && targetType.Elem().Kind() == reflect.Interface {
+		targetType = targetType.Elem()
+	} else if !targetType.Implements(errorType) {
+		panic(fmt.Errorf("CatchAs target must implement error or point to an interface, got %s", targetType))
+	}
+	functionRv := reflect.ValueOf(f)
+	returnsError := checkCatchHandler(functionRv, targetType, p.resultType)
+	adapter := catchAdapter(functionRv, returnsError, p.resultType, func(err error) (reflect.Value, bool) {
+		matched := reflect.New(targetType)
+		if !errors.As(err, matched.Interface()) {
+			return reflect.Value{}, false
+		}
+		return matched.Elem(), true
+	})
+	return p.catch(adapter, funcName(functionRv), true)
+}
+
+// checkCatchHandler panics unless functionRv is a function accepting a
+// single param and returning resultType, optionally followed by an error,
+// which it reports.
+func checkCatchHandler(functionRv reflect.Value, param reflect.Type, resultType []reflect.Type) (returnsError bool) {
+	if functionRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", functionRv.Kind()))
+	}
+	reflectType := functionRv.Type()
+	if reflectType.NumIn() != 1 || reflectType.In(0) != param {
+		panic(fmt.Errorf("expected function accepting a single %s, got %s", param, reflectType))
+	}
+	handlerResults, returnsError := getResultType(reflectType)
+	if len(handlerResults) != len(resultType) {
+		panic(fmt.Errorf("promise returns %d values, but provided function returns %d values", len(resultType), len(handlerResults)))
+	}
+	for i := range resultType {
+		if handlerResults[i] != resultType[i] {
+			panic(fmt.Errorf("for return value %d: expected type %s got type %s", i, resultType[i], handlerResults[i]))
+		}
+	}
+	return returnsError
+}
+
+// catch chains a promise that calls functionRv if p is rejected.
+// functionRv must have been checked by checkCatchHandler.
+func (p *Promise) catch(functionRv reflect.Value, name string, returnsError bool) *Promise {
+	next := newPooledPromise(catchCall, name)
+	next.resultType, next.returnsError = p.resultType, returnsError
+	p.chain(next, functionRv)
+	return next
+}
+
+// catchAdapter returns a function for catch that calls handler with the
+// value match returns for an error, or returns the error unchanged if it
+// doesn't match.
+func catchAdapter(handler reflect.Value, returnsError bool, resultType []reflect.Type, match func(error) (reflect.Value, bool)) reflect.Value {
+	outs := append(append([]reflect.Type{}, resultType...), errorType)
+	return reflect.MakeFunc(reflect.FuncOf([]reflect.Type{errorType}, outs, false), func(in []reflect.Value) []reflect.Value {
+		err, _ := in[0].Interface().(error)
+		arg, ok := match(err)
+		if !ok {
+			results := make([]reflect.Value, len(outs))
+			for i, t := range resultType {
+				results[i] = reflect.Zero(t)
+			}
+			results[len(resultType)] = in[0]
+			return results
+		}
+		results := handler.Call([]reflect.Value{arg})
+		if !returnsError {
+			results = append(results, reflect.Zero(errorType))
+		}
+		return results
+	})
+}
+
+// catchCall waits for prior and calls functionRv with its error if it
+// failed. It settles p directly and reports false when prior succeeded,
+// or if p settled while waiting.
+func (p *Promise) catchCall(prior *Promise, functionRv reflect.Value) ([]reflect.Value, bool) {
+	prior.await()
+	if prior.err == nil {
+		p.settle(prior.results, nil)
+		return nil, false
+	}
+	if p.isSettled() {
+		return nil, false
+	}
+	p.markStarted()
+	injectFault(p)
+	errRv := reflect.New(errorType).Elem()
+	errRv.Set(reflect.ValueOf(prior.err))
+	return functionRv.Call([]reflect.Value{errRv}), true
+}
@@ -0,0 +1,119 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatchRecoversWithFallback(t *testing.T) {
+	failed := New(func() (string, error) {
+		return "", errors.New("unreachable host")
+	})
+	var caught error
+	recovered := failed.Catch(func(err error) string {
+		caught = err
+		return "fallback"
+	}).Then(func(s string) string {
+		return s + "!"
+	})
+	var result string
+	require.NoError(t, recovered.Wait(&result))
+	require.Equal(t, "fallback!", result)
+	require.EqualError(t, caught, "unreachable host")
+}
+
+func TestCatchPassesThroughSuccess(t *testing.T) {
+	called := false
+	p := New(func() int {
+		return 1
+	}).Catch(func(err error) int {
+		called = true
+		return 0
+	})
+	var result int
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, 1, result)
+	require.False(t, called)
+}
+
+func TestCatchCanRejectAgain(t *testing.T) {
+	p := New(func() error {
+		return errors.New("first")
+	}).Catch(func(err error) error {
+		return errors.New("second: " + err.Error())
+	})
+	err := p.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "second: first")
+}
+
+func TestCatchValidatesHandler(t *testing.T) {
+	p := New(func() int { return 1 })
+	require.Panics(t, func() { p.Catch(func(err error) string { return "" }) })
+	require.Panics(t, func() { p.Catch(func(s string) int { return 0 }) })
+	require.Panics(t, func() { p.Catch(4) })
+}
+
+var errNotFound = errors.New("not found")
+
+type httpError struct {
+	code int
+}
+
+func (err *httpError) Error() string {
+	return "status " + strconv.Itoa(err.code)
+}
+
+func TestCatchIf(t *testing.T) {
+	isNotFound := func(err error) bool { return errors.Is(err, errNotFound) }
+	fallback := func(err error) string { return "default" }
+
+	var s string
+	p := New(func() (string, error) { return "", fmt.Errorf("lookup: %w", errNotFound) })
+	require.NoError(t, p.CatchIf(isNotFound, fallback).Wait(&s))
+	require.Equal(t, "default", s)
+
+	p = New(func() (string, error) { return "", errors.New("boom") })
+	err := p.CatchIf(isNotFound, fallback).Wait(&s)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "boom")
+
+	p = New(func() string { return "ok" })
+	require.NoError(t, p.CatchIf(isNotFound, fallback).Wait(&s))
+	require.Equal(t, "ok", s)
+}
+
+func TestCatchAs(t *testing.T) {
+	p := New(func() (int, error) {
+		return 0, fmt.Errorf("fetch: %w", &httpError{code: 503})
+	})
+	var code int
+	require.NoError(t, p.CatchAs(&httpError{}, func(err *httpError) int {
+		return err.code
+	}).Wait(&code))
+	require.Equal(t, 503, code)
+
+	other := New(func() (int, error) { return 0, errNotFound }).
+		CatchAs(&httpError{}, func(err *httpError) (int, error) { return err.code, nil }).
+		CatchIf(func(err error) bool { return errors.Is(err, errNotFound) }, func(error) int { return 404 })
+	require.NoError(t, other.Wait(&code))
+	require.Equal(t, 404, code)
+}
+
+func TestCatchAsInterface(t *testing.T) {
+	p := New(func() (int, error) { return 0, &httpError{code: 500} })
+	var code int
+	require.NoError(t, p.CatchAs((*error)(nil), func(err error) int { return 1 }).Wait(&code))
+	require.Equal(t, 1, code)
+}
+
+func TestCatchAsValidatesTarget(t *testing.T) {
+	p := New(func() int { return 1 })
+	require.Panics(t, func() { p.CatchAs(nil, func(err error) int { return 0 }) })
+	require.Panics(t, func() { p.CatchAs(httpError{}, func(err httpError) int { return 0 }) })
+	require.Panics(t, func() { p.CatchAs(&httpError{}, func(err error) int { return 0 }) })
+}
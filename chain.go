@@ -0,0 +1,54 @@
+package promise
+
+import "fmt"
+
+// Chain returns a promise that calls the first of fs, then each of the
+// others with the results of the one before, like New followed by a Then
+// for each function after the first:
+//
+//	body := Chain(get, readBody, parse)
+//
+// The signatures of every stage are checked before the first function is
+// called, and a mismatch panics naming the stage, even with strict mode
+// off, so nothing runs for a chain that can't complete.
+func Chain(fs ...interface{}) *Promise {
+	return chain("Chain", fs)
+}
+
+// Waterfall is Chain, under the name other promise libraries give it. A
+// signature mismatch panics naming the stage of Waterfall.
+func Waterfall(fs ...interface{}) *Promise {
+	return chain("Waterfall", fs)
+}
+
+// chain implements Chain and Waterfall, named name in errors.
+func chain(name string, fs []interface{}) *Promise {
+	if len(fs) == 0 {
+		panic(fmt.Errorf("%s needs at least one function", name))
+	}
+	built := make([]*Promise, 0, len(fs))
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		// The chain never starts, so drop what was built from the
+		// promises DumpPending reports.
+		for _, p := range built {
+			untrackPending(p)
+		}
+		if err, ok := r.(error); ok {
+			r = fmt.Errorf("stage %d of %s: %w", len(built)+1, name, err)
+		}
+		panic(r)
+	}()
+	first, start := newCall(fs[0], nil, nil)
+	built = append(built, first)
+	p := first
+	for _, f := range fs[1:] {
+		p = p.thenChecked(f, nil, true)
+		built = append(built, p)
+	}
+	start()
+	return p
+}
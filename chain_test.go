@@ -0,0 +1,78 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain(t *testing.T) {
+	p := Chain(
+		func() string { return " 41 " },
+		strings.TrimSpace,
+		strconv.Atoi,
+		func(n int) int { return n + 1 },
+	)
+	var n int
+	require.NoError(t, p.Wait(&n))
+	require.Equal(t, 42, n)
+
+	var s string
+	require.NoError(t, Chain(func() string { return "only" }).Wait(&s))
+	require.Equal(t, "only", s)
+}
+
+func TestChainStopsAtFailingStage(t *testing.T) {
+	called := false
+	p := Chain(
+		func() (string, error) { return "", errors.New("no body") },
+		func(string) string {
+			called = true
+			return ""
+		},
+	)
+	require.EqualError(t, cause(p.Wait(new(string))), "no body")
+	require.False(t, called)
+}
+
+func TestChainChecksSignaturesFirst(t *testing.T) {
+	ran := make(chan struct{}, 1)
+	panicMessage := func(f func()) (msg string) {
+		defer func() { msg = fmt.Sprint(recover()) }()
+		f()
+		return ""
+	}
+	msg := panicMessage(func() {
+		Chain(
+			func() string {
+				ran <- struct{}{}
+				return ""
+			},
+			strings.TrimSpace,
+			func(int) {},
+		)
+	})
+	require.Equal(t, "stage 3 of Chain: for argument 0: expected type string got type int", msg)
+	require.Len(t, ran, 0)
+	require.Equal(t, "Chain needs at least one function", panicMessage(func() { Chain() }))
+}
+
+func TestWaterfall(t *testing.T) {
+	p := Waterfall(
+		func() (string, int) { return "a", 3 },
+		strings.Repeat,
+		func(s string) int { return len(s) },
+	)
+	var n int
+	require.NoError(t, p.Wait(&n))
+	require.Equal(t, 3, n)
+
+	require.PanicsWithValue(t, "stage 2 of Waterfall: promise returns 1 values, but provided function accepts 2 args", func() {
+		defer func() { panic(fmt.Sprint(recover())) }()
+		Waterfall(func() string { return "" }, strings.Repeat)
+	})
+}
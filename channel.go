@@ -0,0 +1,93 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrChannelClosed rejects a promise from FromChannel whose channel was
+// closed before delivering a value.
+var ErrChannelClosed = errors.New("channel closed before a value was received")
+
+// Results holds how a promise settled: its values if it resolved, or its
+// error if it was rejected.
+type Results struct {
+	Values []interface{}
+	Err    error
+}
+
+// settledResults returns how p settled. p must have settled.
+func (p *Promise) settledResults() Results {
+	if p.err != nil {
+		return Results{Err: p.err}
+	}
+	results, _, err := p.retained()
+	if err != nil {
+		return Results{Err: err}
+	}
+	defer p.consumed()
+	values := make([]interface{}, len(results))
+	for i, result := range results {
+		values[i] = result.Interface()
+	}
+	return Results{Values: values}
+}
+
+// FromChannel returns a promise that resolves with the first value
+// received from ch, which must be a channel that can be received from. It
+// is rejected with ErrChannelClosed if ch is closed first.
+func FromChannel(ch interface{}) *Promise {
+	return FromChannelErr(ch, nil)
+}
+
+// FromChannelErr is like FromChannel, except that the promise is rejected
+// with the first error received from errc if that arrives before a value.
+func FromChannelErr(ch interface{}, errc <-chan error) *Promise {
+	chRv := reflect.ValueOf(ch)
+	if chRv.Kind() != reflect.Chan || chRv.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic(fmt.Errorf("expected a channel to receive from, got %s", chRv.Type()))
+	}
+	p := newPromise(signalCall, "FromChannel")
+	p.resultType = []reflect.Type{chRv.Type().Elem()}
+	newGraph(p)
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: chRv},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(p.doneChan())},
+	}
+	if errc != nil {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(errc)})
+	}
+	spawn(func() {
+		chosen, value, ok := reflect.Select(cases)
+		switch {
+		case chosen == 0 && ok:
+			p.settle([]reflect.Value{value}, nil)
+		case chosen == 0:
+			p.settle(nil, ErrChannelClosed)
+		case chosen == 2 && ok:
+			err, _ := value.Interface().(error)
+			if err == nil {
+				err = errors.New("nil error received from error channel")
+			}
+			p.settle(nil, err)
+		case chosen == 2:
+			p.settle(nil, ErrChannelClosed)
+		}
+	})
+	return p
+}
+
+// ToChannel returns a channel that delivers how p settled and is then
+// closed. The channel is buffered, so the delivery never blocks even if
+// nothing receives it.
+func (p *Promise) ToChannel() <-chan Results {
+	p.checkCopy()
+	p.observe()
+	ch := make(chan Results, 1)
+	p.whenSettled(func() {
+		ch <- p.settledResults()
+		close(ch)
+	})
+	return ch
+}
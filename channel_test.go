@@ -0,0 +1,52 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromChannel(t *testing.T) {
+	ch := make(chan string)
+	p := FromChannel(ch)
+	ch <- "hello"
+	var result string
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, "hello", result)
+}
+
+func TestFromChannelClosed(t *testing.T) {
+	ch := make(chan int)
+	close(ch)
+	err := FromChannel((<-chan int)(ch)).Wait(new(int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), ErrChannelClosed.Error())
+}
+
+func TestFromChannelErr(t *testing.T) {
+	errc := make(chan error, 1)
+	errc <- errors.New("producer failed")
+	err := FromChannelErr(make(chan int), errc).Wait(new(int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "producer failed")
+}
+
+func TestFromChannelCanceled(t *testing.T) {
+	p := FromChannel(make(chan int))
+	p.Cancel()
+	require.Error(t, p.Wait(new(int)))
+	require.Panics(t, func() { FromChannel(make(chan<- int)) })
+	require.Panics(t, func() { FromChannel(1) })
+}
+
+func TestToChannel(t *testing.T) {
+	results := <-Resolved(1, "a").ToChannel()
+	require.Equal(t, Results{Values: []interface{}{1, "a"}}, results)
+
+	failure := errors.New("failed")
+	ch := Rejected(failure).ToChannel()
+	require.Equal(t, Results{Err: failure}, <-ch)
+	_, open := <-ch
+	require.False(t, open)
+}
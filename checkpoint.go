@@ -0,0 +1,34 @@
+package promise
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// promiseKey keys the promise in the context NewCtx passes to its function.
+type promiseKey struct{}
+
+// Checkpoint is for long, CPU-bound functions run by NewCtx to call every
+// so often with the context they were passed. It yields the processor,
+// records that the promise is still making progress, as reported by
+// LastCheckpoint and DumpPending, and returns ctx.Err(), so the function
+// can stop once its promise is canceled.
+func Checkpoint(ctx context.Context) error {
+	runtime.Gosched()
+	if p, ok := ctx.Value(promiseKey{}).(*Promise); ok {
+		atomic.StoreInt64(&p.extras().checkpoint, p.now().UnixNano())
+	}
+	return ctx.Err()
+}
+
+// LastCheckpoint returns when p's function last called Checkpoint, or the
+// zero time if it never has.
+func (p *Promise) LastCheckpoint() time.Time {
+	nanos := atomic.LoadInt64(&p.peekExtras().checkpoint)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,40 @@
+package promise
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+	checkpointed := make(chan struct{})
+	p := NewCtx(context.Background(), func(ctx context.Context) (int, error) {
+		for i := 0; ; i++ {
+			if err := Checkpoint(ctx); err != nil {
+				return i, err
+			}
+			if i == 0 {
+				close(checkpointed)
+			}
+		}
+	})
+	<-checkpointed
+	require.False(t, p.LastCheckpoint().IsZero())
+	var dump bytes.Buffer
+	require.NoError(t, DumpPending(&dump))
+	require.Contains(t, dump.String(), "last checkpoint")
+
+	p.Cancel()
+	require.Equal(t, ErrCanceled, cause(p.Wait(new(int))))
+}
+
+func TestCheckpointWithoutPromise(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, Checkpoint(ctx))
+	cancel()
+	require.Equal(t, context.Canceled, Checkpoint(ctx))
+}
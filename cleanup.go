@@ -0,0 +1,37 @@
+package promise
+
+// Defer registers cleanup to run exactly once, when p settles. That
+// includes p being canceled or rejected by its context before its
+// function ever started, so resources acquired on behalf of p are
+// released however it ends. Cleanups run in the reverse order they were
+// registered, like deferred calls. If p has already settled, cleanup runs
+// immediately.
+func (p *Promise) Defer(cleanup func()) {
+	p.checkCopy()
+	p.mu.Lock()
+	if p.signal.Pending() {
+		x := p.extras()
+		x.cleanups = append(x.cleanups, cleanup)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	runCleanups(p.Name(), []func(){cleanup})
+}
+
+// runCleanups calls cleanups last to first. A panicking cleanup is logged
+// and doesn't stop the others.
+func runCleanups(name string, cleanups []func()) {
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		runCleanup(name, cleanups[i])
+	}
+}
+
+func runCleanup(name string, cleanup func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logf("promise: cleanup for %s panicked: %v", name, r)
+		}
+	}()
+	cleanup()
+}
@@ -0,0 +1,64 @@
+package promise
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeferRunsCleanupsOnSettle(t *testing.T) {
+	release := make(chan struct{})
+	p := New(func() int {
+		<-release
+		return 1
+	})
+	ran := make(chan int, 2)
+	p.Defer(func() { ran <- 1 })
+	p.Defer(func() { ran <- 2 })
+	close(release)
+	require.NoError(t, p.Wait(new(int)))
+	require.Equal(t, 2, <-ran)
+	require.Equal(t, 1, <-ran)
+}
+
+func TestDeferRunsCleanupOnCancelBeforeStart(t *testing.T) {
+	signal := Signal()
+	started := false
+	child := signal.Promise().Then(func() {
+		started = true
+	})
+	released := make(chan struct{})
+	child.Defer(func() { close(released) })
+	child.Cancel()
+	<-released
+	signal.Resolve()
+	require.Error(t, child.Wait())
+	require.False(t, started)
+}
+
+func TestDeferAfterSettleRunsImmediately(t *testing.T) {
+	p := New(func() {})
+	require.NoError(t, p.Wait())
+	ran := 0
+	p.Defer(func() { ran++ })
+	require.Equal(t, 1, ran)
+}
+
+func TestDeferLogsPanickingCleanup(t *testing.T) {
+	logs := make(chanLogger, 100)
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	signal := Signal()
+	ran := false
+	signal.Promise().Defer(func() { ran = true })
+	signal.Promise().Defer(func() { panic("boom") })
+	signal.Resolve()
+	require.True(t, ran)
+	for msg := range logs {
+		if strings.Contains(msg, "cleanup for Signal panicked: boom") {
+			break
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package promise
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A Clock supplies the time to the package: the timestamps behind Timings,
+// Cache and Budget, and the timers behind After, Delay and timeouts.
+// Replacing it with a simulated clock, such as a FakeClock, makes
+// schedules reproducible in tests.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine once d has passed, unless
+	// the returned Timer is stopped first, like time.AfterFunc.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// A Timer is a timer started by Clock.AfterFunc. *time.Timer satisfies
+// Timer.
+type Timer interface {
+	Stop() bool
+}
+
+// A Rand supplies the random numbers behind sampling and fault injection.
+// It must be safe for concurrent use.
+type Rand interface {
+	// Float64 returns a number in [0.0, 1.0).
+	Float64() float64
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+type globalRand struct{}
+
+func (globalRand) Float64() float64 { return rand.Float64() }
+
+type clockHolder struct{ Clock }
+
+type randHolder struct{ Rand }
+
+var (
+	clock   atomic.Value
+	entropy atomic.Value
+)
+
+// SetClock makes the package read the time from c. Passing nil restores
+// the system clock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = realClock{}
+	}
+	clock.Store(clockHolder{c})
+}
+
+// SetRand makes the package draw random numbers from r. Passing nil
+// restores the math/rand default source.
+func SetRand(r Rand) {
+	if r == nil {
+		r = globalRand{}
+	}
+	entropy.Store(randHolder{r})
+}
+
+func init() {
+	SetClock(nil)
+	SetRand(nil)
+}
+
+func currentTime() time.Time {
+	return clock.Load().(clockHolder).Now()
+}
+
+func afterFunc(d time.Duration, f func()) Timer {
+	return clock.Load().(clockHolder).AfterFunc(d, f)
+}
+
+// SetClock makes the promises in g read the time from c instead of the
+// package's clock, so one tree can run on a FakeClock while the rest of
+// the program keeps real time. It applies to the timestamps of promises
+// joining g from now on and to the timers of Delay, WithTimeout,
+// ThenWithTimeout and Aggregate started by its members, but not to After,
+// which starts a graph of its own. Passing nil restores the package's
+// clock.
+func (g *Graph) SetClock(c Clock) {
+	g.mu.Lock()
+	g.clock = c
+	g.mu.Unlock()
+}
+
+// clockFor returns the clock p reads the time from.
+func (p *Promise) clockFor() Clock {
+	if g := p.graph; g != nil {
+		g.mu.Lock()
+		c := g.clock
+		g.mu.Unlock()
+		if c != nil {
+			return c
+		}
+	}
+	return clock.Load().(clockHolder).Clock
+}
+
+func (p *Promise) now() time.Time {
+	return p.clockFor().Now()
+}
+
+func (p *Promise) afterFunc(d time.Duration, f func()) Timer {
+	// The timer may fire after p settles.
+	p.pin()
+	return p.clockFor().AfterFunc(d, f)
+}
+
+// sleep blocks until d has passed on c.
+func sleep(c Clock, d time.Duration) {
+	done := make(chan struct{})
+	c.AfterFunc(d, func() { close(done) })
+	<-done
+}
+
+func randFloat64() float64 {
+	return entropy.Load().(randHolder).Float64()
+}
+
+// A FakeClock is a Clock whose time only moves when advanced, for tests
+// of timeouts and delays that shouldn't wait for them in real time.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *FakeClock
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+// NewFakeClock returns a FakeClock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now implements Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc implements Clock. f runs once Advance moves the clock to or
+// past d from now.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+// Advance moves the clock forward by d and fires the timers that are due,
+// each in its own goroutine.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []func()
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.stopped:
+		case !t.at.After(c.now):
+			t.stopped = true
+			due = append(due, t.f)
+		default:
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+	for _, f := range due {
+		spawn(f)
+	}
+}
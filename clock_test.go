@@ -0,0 +1,82 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	b := NewBudget(1, 1)
+	require.True(t, b.TryTake())
+	require.False(t, b.TryTake())
+	clock.Advance(time.Second)
+	require.True(t, b.TryTake())
+
+	p := After(time.Hour)
+	clock.Advance(time.Minute)
+	require.Equal(t, StatePending, p.State())
+	clock.Advance(time.Hour)
+	require.NoError(t, p.Wait())
+	require.Equal(t, time.Unix(0, 0).Add(time.Hour+time.Second+time.Minute), p.Timings().Settled)
+}
+
+type constRand float64
+
+func (r constRand) Float64() float64 { return float64(r) }
+
+func TestSetRand(t *testing.T) {
+	samples := make(chan Sample, 2)
+	SetSampler(&Sampler{Rate: 0.5, Sink: func(s Sample) { samples <- s }})
+	defer SetSampler(nil)
+	defer SetRand(nil)
+
+	SetRand(constRand(0.4))
+	Resolved(1)
+	require.Len(t, samples, 1)
+
+	<-samples
+	SetRand(constRand(0.6))
+	Resolved(1)
+	require.Len(t, samples, 0)
+}
+
+func TestGraphSetClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	d := NewDeferred(typeOf[int]())
+	d.Promise.Graph().SetClock(clock)
+
+	delayed := d.Promise.Delay(time.Hour)
+	timedOut := d.Promise.Then(func(int) {}).WithTimeout(time.Minute)
+	require.Equal(t, time.Unix(0, 0), delayed.Timings().Created)
+
+	clock.Advance(time.Minute)
+	require.True(t, errors.Is(timedOut.Wait(), ErrTimeout))
+
+	d.Resolve(1)
+	require.Equal(t, StatePending, delayed.State())
+	clock.Advance(time.Hour)
+	var n int
+	require.NoError(t, delayed.Wait(&n))
+	require.Equal(t, 1, n)
+	require.Equal(t, time.Unix(0, 0).Add(time.Hour+time.Minute), delayed.Timings().Settled)
+}
+
+// pending returns the number of timers waiting on c.
+func (c *FakeClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, t := range c.timers {
+		if !t.stopped {
+			n++
+		}
+	}
+	return n
+}
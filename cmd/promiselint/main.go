@@ -0,0 +1,285 @@
+// Command promiselint reports promises that are created but never
+// observed: no Wait, Then or other use on any path, so their errors are
+// silently dropped.
+//
+// Usage:
+//
+//	promiselint [-detach=false] files or directories...
+//
+// Promises that are intentionally fire-and-forget should be acknowledged
+// with Promise.Detach. Passing -detach=false reports detached promises as
+// well.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const importPath = "github.com/garlicnation/promises"
+
+// constructors are the package functions that return a new *Promise.
+var constructors = map[string]bool{
+	"New":               true,
+	"NewCtx":            true,
+	"NewWith":           true,
+	"NewWithPriority":   true,
+	"NewLimited":        true,
+	"NewNamed":          true,
+	"All":               true,
+	"Race":              true,
+	"Any":               true,
+	"RaceIndexed":       true,
+	"AnyIndexed":        true,
+	"Some":              true,
+	"AllWithLimit":      true,
+	"AllCollect":        true,
+	"Aggregate":         true,
+	"Join":              true,
+	"Chain":             true,
+	"Waterfall":         true,
+	"Reduce":            true,
+	"Filter":            true,
+	"Series":            true,
+	"Each":              true,
+	"Batch":             true,
+	"ProcessByPriority": true,
+	"FromChannel":       true,
+	"FromChannelErr":    true,
+	"FromErrgroup":      true,
+	"FromFuture":        true,
+	"Resolved":          true,
+	"Rejected":          true,
+	"After":             true,
+	"Shared":            true,
+	"OnSignal":          true,
+	"OnDone":            true,
+	"ThenT":             true,
+	"Retry":             true,
+	"Hedge":             true,
+	"Speculate":         true,
+}
+
+// methods are the Promise methods that return a new *Promise.
+var methods = map[string]bool{
+	"Then":            true,
+	"ThenSerial":      true,
+	"ThenCatch":       true,
+	"ThenCtx":         true,
+	"ThenDirect":      true,
+	"ThenWithTimeout": true,
+	"WithTimeout":     true,
+	"Catch":           true,
+	"CatchIf":         true,
+	"CatchAs":         true,
+	"Finally":         true,
+	"Delay":           true,
+	"Spread":          true,
+}
+
+func main() {
+	allowDetach := flag.Bool("detach", true, "treat Detach as acknowledging a promise")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	found := false
+	for _, arg := range flag.Args() {
+		err := filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if name := info.Name(); path != arg && (name == "vendor" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				return err
+			}
+			for _, d := range check(fset, file, *allowDetach) {
+				fmt.Println(d)
+				found = true
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if found {
+		os.Exit(1)
+	}
+}
+
+// A diagnostic is a single report from check.
+type diagnostic struct {
+	pos     token.Position
+	message string
+}
+
+func (d diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.pos, d.message)
+}
+
+type checker struct {
+	fset        *token.FileSet
+	pkg         string
+	allowDetach bool
+	diagnostics []diagnostic
+}
+
+// check returns the unobserved promises in file.
+func check(fset *token.FileSet, file *ast.File, allowDetach bool) []diagnostic {
+	pkg := ""
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if path != importPath && !strings.HasPrefix(path, importPath+"/v") {
+			continue
+		}
+		pkg = "promise"
+		if spec.Name != nil {
+			pkg = spec.Name.Name
+		}
+	}
+	if pkg == "" || pkg == "_" {
+		return nil
+	}
+	c := &checker{fset: fset, pkg: pkg, allowDetach: allowDetach}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				c.checkBody(n.Body)
+			}
+		case *ast.FuncLit:
+			c.checkBody(n.Body)
+		}
+		return true
+	})
+	sort.Slice(c.diagnostics, func(i, j int) bool {
+		return c.diagnostics[i].pos.Offset < c.diagnostics[j].pos.Offset
+	})
+	return c.diagnostics
+}
+
+func (c *checker) report(pos token.Pos, format string, args ...interface{}) {
+	c.diagnostics = append(c.diagnostics, diagnostic{
+		pos:     c.fset.Position(pos),
+		message: fmt.Sprintf(format, args...),
+	})
+}
+
+// isPromise reports whether expr creates a new promise.
+func (c *checker) isPromise(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	if x, ok := sel.X.(*ast.Ident); ok && x.Name == c.pkg {
+		return constructors[sel.Sel.Name]
+	}
+	return methods[sel.Sel.Name]
+}
+
+// isDetach reports whether expr is a call to Detach on a promise.
+func (c *checker) isDetach(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Detach"
+}
+
+// checkBody reports promises created directly in body that are discarded
+// or assigned to variables that are never used again. Nested function
+// literals are checked separately.
+func (c *checker) checkBody(body *ast.BlockStmt) {
+	assigned := map[*ast.Object]*ast.Ident{}
+	for _, stmt := range body.List {
+		switch stmt := stmt.(type) {
+		case *ast.ExprStmt:
+			switch {
+			case c.isPromise(stmt.X):
+				c.report(stmt.Pos(), "promise is never waited on; call Wait, Then or Detach")
+			case !c.allowDetach && c.isDetach(stmt.X):
+				c.report(stmt.Pos(), "promise is detached and its result is never observed")
+			}
+		case *ast.AssignStmt:
+			if len(stmt.Lhs) != len(stmt.Rhs) {
+				continue
+			}
+			for i, rhs := range stmt.Rhs {
+				if !c.isPromise(rhs) {
+					continue
+				}
+				ident, ok := stmt.Lhs[i].(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if ident.Name == "_" {
+					c.report(ident.Pos(), "promise is discarded and never waited on")
+					continue
+				}
+				if ident.Obj != nil {
+					assigned[ident.Obj] = ident
+				}
+			}
+		}
+	}
+	if len(assigned) == 0 {
+		return
+	}
+
+	// Count the uses of every assigned promise, including uses inside
+	// nested function literals.
+	used := map[*ast.Object]bool{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && !c.allowDetach && c.isDetach(call) {
+			// Detach on its own is not a use when detaching is disallowed,
+			// but its arguments may still use other promises.
+			for _, arg := range call.Args {
+				ast.Inspect(arg, func(n ast.Node) bool {
+					c.markUse(n, assigned, used)
+					return true
+				})
+			}
+			return false
+		}
+		c.markUse(n, assigned, used)
+		return true
+	})
+	for obj, ident := range assigned {
+		if !used[obj] {
+			c.report(ident.Pos(), "promise %s is never waited on; call Wait, Then or Detach", ident.Name)
+		}
+	}
+}
+
+func (c *checker) markUse(n ast.Node, assigned map[*ast.Object]*ast.Ident, used map[*ast.Object]bool) {
+	ident, ok := n.(*ast.Ident)
+	if !ok || ident.Obj == nil {
+		return
+	}
+	if decl, ok := assigned[ident.Obj]; ok && decl != ident {
+		used[ident.Obj] = true
+	}
+}
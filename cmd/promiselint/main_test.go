@@ -0,0 +1,117 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func lint(t *testing.T, src string, allowDetach bool) []string {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "src.go", src, 0)
+	require.NoError(t, err)
+	messages := []string{}
+	for _, d := range check(fset, file, allowDetach) {
+		messages = append(messages, d.String())
+	}
+	return messages
+}
+
+const unobserved = `package main
+
+import promise "github.com/garlicnation/promises/v2"
+
+func work() {}
+
+func main() {
+	promise.New(work)
+	_ = promise.New(work)
+	p := promise.New(work)
+	waited := promise.New(work)
+	_ = waited.Wait()
+	chained := promise.New(work)
+	chained.Then(work)
+	promise.New(work).Detach(nil)
+	detached := promise.New(work)
+	detached.Detach(nil)
+}
+`
+
+func TestCheckReportsUnobservedPromises(t *testing.T) {
+	require.Equal(t, []string{
+		"src.go:8:2: promise is never waited on; call Wait, Then or Detach",
+		"src.go:9:2: promise is discarded and never waited on",
+		"src.go:10:2: promise p is never waited on; call Wait, Then or Detach",
+		"src.go:14:2: promise is never waited on; call Wait, Then or Detach",
+	}, lint(t, unobserved, true))
+}
+
+func TestCheckDisallowDetach(t *testing.T) {
+	messages := lint(t, unobserved, false)
+	require.Contains(t, messages, "src.go:15:2: promise is detached and its result is never observed")
+	require.Contains(t, messages, "src.go:16:2: promise detached is never waited on; call Wait, Then or Detach")
+}
+
+func TestCheckIgnoresFilesWithoutImport(t *testing.T) {
+	require.Empty(t, lint(t, "package main\n\nfunc main() { New(nil) }\n", true))
+}
+
+// chaining are the Promise methods that return their receiver rather than
+// a new promise.
+var chaining = map[string]bool{
+	"InheritOptions":              true,
+	"OnComplete":                  true,
+	"OnError":                     true,
+	"OnSuccess":                   true,
+	"WithAutoCancel":              true,
+	"WithConversions":             true,
+	"WithDiscardResultsAfterWait": true,
+	"WithName":                    true,
+	"WithPriority":                true,
+}
+
+// isPromisePtr reports whether expr is the type *Promise.
+func isPromisePtr(expr ast.Expr) bool {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	return ok && ident.Name == "Promise"
+}
+
+func returnsPromise(f *ast.FuncType) bool {
+	return f.Results != nil && len(f.Results.List) == 1 && isPromisePtr(f.Results.List[0].Type)
+}
+
+func TestListsCoverPackage(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "../..", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	require.NoError(t, err)
+	pkg, ok := pkgs["promise"]
+	require.True(t, ok)
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !fn.Name.IsExported() || !returnsPromise(fn.Type) {
+				continue
+			}
+			name := fn.Name.Name
+			if fn.Recv == nil {
+				require.True(t, constructors[name], "%s is missing from constructors", name)
+				continue
+			}
+			if !isPromisePtr(fn.Recv.List[0].Type) || chaining[name] {
+				continue
+			}
+			require.True(t, methods[name], "%s is missing from methods", name)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Command promises-gen writes promise types specialized to the result
+// types of a package, which call functions directly rather than through
+// reflection, for code paths where the promise package's reflective calls
+// are too slow.
+//
+// Usage:
+//
+//	promises-gen -package name [-import path]... [-o file] Name=Type...
+//
+// For every Name=Type pair, it writes a NamePromise type resolving with a
+// Type, with NewNamePromise, Wait, Done and Then, and a ThenOther method
+// for every other pair, such as ThenSize on a BodyPromise to get a
+// SizePromise. Types from other packages need their import paths passed
+// with -import. It is meant to be run by go generate:
+//
+//	//go:generate promises-gen -package fetch -o promises_gen.go Body=[]byte Size=int
+//
+// The generated code depends only on the standard library.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// importList collects repeated -import flags.
+type importList []string
+
+func (l *importList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *importList) Set(path string) error {
+	*l = append(*l, path)
+	return nil
+}
+
+func main() {
+	pkg := flag.String("package", "", "package name of the generated file")
+	out := flag.String("o", "", "file to write, instead of standard output")
+	var imports importList
+	flag.Var(&imports, "import", "import path the result types need; may be repeated")
+	flag.Parse()
+
+	if *pkg == "" || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: promises-gen -package name [-import path]... [-o file] Name=Type...")
+		os.Exit(2)
+	}
+	types, err := parseTypes(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	src, err := generate(*pkg, imports, types)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// A resultType is a type to generate a promise for.
+type resultType struct {
+	// Name prefixes the names of the generated identifiers.
+	Name string
+	// Type is the Go type the promise resolves with, as written in source.
+	Type string
+}
+
+// parseTypes parses Name=Type arguments.
+func parseTypes(args []string) ([]resultType, error) {
+	types := make([]resultType, 0, len(args))
+	seen := map[string]bool{}
+	for _, arg := range args {
+		name, typ, ok := strings.Cut(arg, "=")
+		if !ok || typ == "" {
+			return nil, fmt.Errorf("expected Name=Type, got %q", arg)
+		}
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return nil, fmt.Errorf("%q is not an exported identifier", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is given more than once", name)
+		}
+		seen[name] = true
+		types = append(types, resultType{Name: name, Type: typ})
+	}
+	return types, nil
+}
+
+// generate returns the formatted source of the promise types.
+func generate(pkg string, imports []string, types []resultType) ([]byte, error) {
+	var b bytes.Buffer
+	err := fileTemplate.Execute(&b, struct {
+		Package string
+		Imports []string
+		Types   []resultType
+	}{pkg, imports, types})
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by promises-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+	"sync"
+{{range .Imports}}	{{printf "%q" .}}
+{{end}})
+
+// promiseCore is the completion state shared by the generated promises.
+type promiseCore struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+// recoverPanic turns a panic in a promise function into an error.
+func recoverPanic(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", r)
+}
+{{range $t := .Types}}
+// {{$t.Name}}Promise resolves with a {{$t.Type}}, or fails with an error.
+type {{$t.Name}}Promise struct {
+	promiseCore
+	value {{$t.Type}}
+}
+
+// New{{$t.Name}}Promise returns a promise that resolves with the result of
+// f, which runs in its own goroutine.
+func New{{$t.Name}}Promise(f func() ({{$t.Type}}, error)) *{{$t.Name}}Promise {
+	p := &{{$t.Name}}Promise{promiseCore: promiseCore{done: make(chan struct{})}}
+	go p.run(f)
+	return p
+}
+
+func (p *{{$t.Name}}Promise) run(f func() ({{$t.Type}}, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.resolve(*new({{$t.Type}}), recoverPanic(r))
+		}
+	}()
+	p.resolve(f())
+}
+
+func (p *{{$t.Name}}Promise) resolve(value {{$t.Type}}, err error) {
+	p.once.Do(func() {
+		p.value, p.err = value, err
+		close(p.done)
+	})
+}
+
+// Done returns a channel that is closed once p settles.
+func (p *{{$t.Name}}Promise) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until p settles and returns its value or error.
+func (p *{{$t.Name}}Promise) Wait() ({{$t.Type}}, error) {
+	<-p.done
+	return p.value, p.err
+}
+{{range $u := $.Types}}
+// Then{{if ne $u.Name $t.Name}}{{$u.Name}}{{end}} returns a promise that resolves with the result of calling f
+// on p's value, or fails with p's error without calling f.
+func (p *{{$t.Name}}Promise) Then{{if ne $u.Name $t.Name}}{{$u.Name}}{{end}}(f func({{$t.Type}}) ({{$u.Type}}, error)) *{{$u.Name}}Promise {
+	return New{{$u.Name}}Promise(func() ({{$u.Type}}, error) {
+		value, err := p.Wait()
+		if err != nil {
+			return *new({{$u.Type}}), err
+		}
+		return f(value)
+	})
+}
+{{end}}{{end}}`))
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTypes(t *testing.T) {
+	types, err := parseTypes([]string{"Body=[]byte", "Headers=map[string][]string"})
+	require.NoError(t, err)
+	require.Equal(t, []resultType{
+		{Name: "Body", Type: "[]byte"},
+		{Name: "Headers", Type: "map[string][]string"},
+	}, types)
+
+	for _, bad := range [][]string{
+		{"Body"},
+		{"Body="},
+		{"body=[]byte"},
+		{"Bo dy=[]byte"},
+		{"Body=[]byte", "Body=string"},
+	} {
+		_, err := parseTypes(bad)
+		require.Error(t, err, "%v", bad)
+	}
+}
+
+func TestGenerateTypeChecks(t *testing.T) {
+	src, err := generate("fetch", []string{"time"}, []resultType{
+		{Name: "Body", Type: "[]byte"},
+		{Name: "Elapsed", Type: "time.Duration"},
+	})
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "promises_gen.go", src, parser.ParseComments)
+	require.NoError(t, err)
+	require.True(t, ast.IsGenerated(file))
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("fetch", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+
+	methods := func(name string) []string {
+		named := pkg.Scope().Lookup(name).Type()
+		set := types.NewMethodSet(types.NewPointer(named))
+		var exported []string
+		for i := 0; i < set.Len(); i++ {
+			if m := set.At(i).Obj(); m.Exported() {
+				exported = append(exported, m.Name())
+			}
+		}
+		return exported
+	}
+	require.Equal(t, []string{"Done", "Then", "ThenElapsed", "Wait"}, methods("BodyPromise"))
+	require.Equal(t, []string{"Done", "Then", "ThenBody", "Wait"}, methods("ElapsedPromise"))
+	require.NotNil(t, pkg.Scope().Lookup("NewBodyPromise"))
+	require.NotNil(t, pkg.Scope().Lookup("NewElapsedPromise"))
+}
+
+func TestGenerateRejectsBadTypes(t *testing.T) {
+	_, err := generate("fetch", nil, []resultType{{Name: "Body", Type: "[]byte)"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package promise
+
+import (
+	"reflect"
+	"sync"
+)
+
+// AllCollect is like All, but rather than failing as soon as one of
+// promises fails, it waits for all of them to settle and then rejects
+// with an *AggregateError holding every failure by index, so every
+// failing task can be reported rather than whichever failed first.
+func AllCollect(promises ...*Promise) *Promise {
+	var types []reflect.Type
+	for _, prior := range promises {
+		prior.checkCopy()
+		types = append(types, prior.resultType...)
+	}
+	d := NewDeferred(types...)
+	d.name = "AllCollect"
+	if len(promises) == 0 {
+		d.settle([]reflect.Value{}, nil)
+		return d.Promise
+	}
+	var mu sync.Mutex
+	remaining := len(promises)
+	aggregate := &AggregateError{Errs: make([]error, len(promises))}
+	d.watchContexts(promises)
+	for i, prior := range promises {
+		i, prior := i, prior
+		prior.observe()
+		prior.graph.addChild(prior, d.Promise)
+		prior.whenSettled(func() {
+			mu.Lock()
+			remaining--
+			if prior.err != nil {
+				aggregate.Errs[i] = prior.err
+				aggregate.LastErr = prior.err
+			}
+			done := remaining == 0
+			mu.Unlock()
+			if !done {
+				return
+			}
+			if aggregate.LastErr != nil {
+				d.Reject(aggregate)
+				return
+			}
+			results := make([]reflect.Value, 0, len(types))
+			for _, prior := range promises {
+				results = append(results, prior.results...)
+			}
+			d.settle(results, nil)
+		})
+	}
+	return d.Promise
+}
@@ -0,0 +1,37 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllCollect(t *testing.T) {
+	var a, b int
+	require.NoError(t, AllCollect(Resolved(1), New(func() int { return 2 })).Wait(&a, &b))
+	require.Equal(t, 1, a)
+	require.Equal(t, 2, b)
+	require.NoError(t, AllCollect().Wait())
+}
+
+func TestAllCollectReportsEveryFailure(t *testing.T) {
+	first := fmt.Errorf("first")
+	second := fmt.Errorf("second")
+	p := AllCollect(
+		Rejected(first, typeOf[int]()),
+		Resolved(2),
+		New(func() (int, error) {
+			time.Sleep(10 * time.Millisecond)
+			return 0, second
+		}),
+	)
+	err := p.Wait(new(int), new(int), new(int))
+	var aggregate *AggregateError
+	require.True(t, errors.As(err, &aggregate))
+	require.Equal(t, []error{first, nil, second}, aggregate.Errs)
+	require.Equal(t, second, aggregate.LastErr)
+	require.Contains(t, err.Error(), "2 of 3 promises failed: promise 0: first; promise 2: second")
+}
@@ -0,0 +1,76 @@
+package promise
+
+// A GoPool runs functions on a bounded set of goroutines. *pool.Pool from
+// github.com/sourcegraph/conc/pool satisfies GoPool, as do most worker
+// pools with a Go method.
+type GoPool interface {
+	Go(f func())
+}
+
+// PoolScheduler returns a Scheduler that runs promises on pool, so that
+// promises and the pool's other tasks share a single concurrency limit.
+// Pass it to WithExecutor or SetScheduler. Go blocks while pool is full,
+// holding up whatever settled the promise's dependencies, so a pool whose
+// tasks wait on promises it runs can deadlock.
+func PoolScheduler(pool GoPool) Scheduler {
+	return poolScheduler{pool}
+}
+
+type poolScheduler struct {
+	pool GoPool
+}
+
+func (s poolScheduler) Submit(f func()) {
+	s.pool.Go(f)
+}
+
+// A ResultPool collects the results of the functions it runs.
+// *pool.ResultPool[T] from github.com/sourcegraph/conc/pool satisfies
+// ResultPool[T]. Pools whose Wait returns only an error, such as
+// *pool.ErrorPool, satisfy ErrGroup instead, for FromErrgroup and
+// AttachTo.
+type ResultPool[T any] interface {
+	Wait() []T
+}
+
+// A ResultErrorPool collects the results of the functions it runs, and
+// the errors they return. *pool.ResultErrorPool[T] and
+// *pool.ResultContextPool[T] from github.com/sourcegraph/conc/pool satisfy
+// ResultErrorPool[T].
+type ResultErrorPool[T any] interface {
+	Wait() ([]T, error)
+}
+
+// FromResultPool returns a promise that resolves with the results of pool
+// once pool.Wait returns. Call it after starting the pool's functions, as
+// pool.Wait is called right away.
+func FromResultPool[T any](pool ResultPool[T]) *PromiseT[[]T] {
+	return FromResultErrorPool[T](resultPool[T]{pool})
+}
+
+// FromResultErrorPool is like FromResultPool, but is rejected with the
+// error pool.Wait returns, if any. For a pool whose functions are canceled
+// through a context, pass the same context to NewCtx for the promises
+// chained from it to be canceled too.
+func FromResultErrorPool[T any](pool ResultErrorPool[T]) *PromiseT[[]T] {
+	d := NewDeferred(typeOf[[]T]())
+	d.name = "FromResultPool"
+	spawn(func() {
+		values, err := pool.Wait()
+		if err != nil {
+			d.Reject(err)
+			return
+		}
+		d.Resolve(values)
+	})
+	return &PromiseT[[]T]{p: d.Promise}
+}
+
+// resultPool adapts a ResultPool to a ResultErrorPool.
+type resultPool[T any] struct {
+	pool ResultPool[T]
+}
+
+func (r resultPool[T]) Wait() ([]T, error) {
+	return r.pool.Wait(), nil
+}
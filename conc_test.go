@@ -0,0 +1,113 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// goPool is a minimal conc pool.Pool, running at most cap(sem) functions
+// at once.
+type goPool struct {
+	wg  sync.WaitGroup
+	sem chan struct{}
+}
+
+func (p *goPool) Go(f func()) {
+	p.sem <- struct{}{}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		f()
+	}()
+}
+
+func (p *goPool) Wait() {
+	p.wg.Wait()
+}
+
+// resultErrorPool is a minimal conc pool.ResultErrorPool.
+type resultErrorPool[T any] struct {
+	group
+	mu      sync.Mutex
+	results []T
+}
+
+func (p *resultErrorPool[T]) Go(f func() (T, error)) {
+	p.group.Go(func() error {
+		value, err := f()
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.results = append(p.results, value)
+		return nil
+	})
+}
+
+func (p *resultErrorPool[T]) Wait() ([]T, error) {
+	err := p.group.Wait()
+	return p.results, err
+}
+
+// resultPoolOnly hides the error of a resultErrorPool, like conc's
+// pool.ResultPool.
+type resultPoolOnly[T any] struct {
+	*resultErrorPool[T]
+}
+
+func (p resultPoolOnly[T]) Wait() []T {
+	values, _ := p.resultErrorPool.Wait()
+	return values
+}
+
+func TestPoolScheduler(t *testing.T) {
+	pool := &goPool{sem: make(chan struct{}, 2)}
+	var running, peak int32
+	task := func() {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+	}
+	var promises []*Promise
+	for i := 0; i < 10; i++ {
+		// Promises and the pool's own tasks share its limit.
+		pool.Go(task)
+		promises = append(promises, NewWith(task, WithExecutor(PoolScheduler(pool))))
+	}
+	require.NoError(t, All(promises...).Wait())
+	pool.Wait()
+	require.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+}
+
+func TestFromResultPool(t *testing.T) {
+	pool := &resultErrorPool[int]{}
+	for i := 0; i < 3; i++ {
+		i := i
+		pool.Go(func() (int, error) { return i, nil })
+	}
+	values, err := FromResultPool[int](resultPoolOnly[int]{pool}).Wait()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []int{0, 1, 2}, values)
+}
+
+func TestFromResultErrorPool(t *testing.T) {
+	pool := &resultErrorPool[int]{}
+	pool.Go(func() (int, error) { return 1, nil })
+	pool.Go(func() (int, error) { return 0, errors.New("task failed") })
+	_, err := FromResultErrorPool[int](pool).Wait()
+	require.Error(t, err)
+	require.Equal(t, "task failed", cause(err).Error())
+}
@@ -0,0 +1,84 @@
+package promise
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+var contextType = typeOf[context.Context]()
+
+// NewCtx returns a promise that calls f with ctx followed by args. The
+// first parameter of f must be a context.Context. If ctx is done before
+// the promise settles, the promise and every promise chained from it are
+// rejected with ctx.Err(), so a promise tree can be tied to the lifetime
+// of a request. Promises that combinators such as All, Reduce and Filter
+// create internally inherit the context the same way; use InheritOptions
+// for promises the package can't see are related.
+func NewCtx(ctx context.Context, f interface{}, args ...interface{}) *Promise {
+	return newWith(f, options{args: args, ctx: ctx})
+}
+
+// watchContext rejects p with ctx.Err() if ctx is done before p settles.
+// The context is inherited by promises chained from p.
+func (p *Promise) watchContext(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	if p.ctx == nil || p.ctx.Done() == nil {
+		p.ctx = ctx
+	}
+	if ctx.Done() == nil {
+		return
+	}
+	p.pin()
+	spawn(func() {
+		select {
+		case <-ctx.Done():
+			p.settle(nil, ctx.Err())
+		case <-p.doneChan():
+		}
+	})
+}
+
+// watchContexts calls watchContext with the contexts of priors, the
+// inputs of a combinator, watching each distinct context once, so that
+// inputs sharing a request's context cost one goroutine rather than one
+// each.
+func (p *Promise) watchContexts(priors []*Promise) {
+	var watched map[<-chan struct{}]bool
+	if p.ctx != nil && p.ctx.Done() != nil {
+		watched = map[<-chan struct{}]bool{p.ctx.Done(): true}
+	}
+	for _, prior := range priors {
+		ctx := prior.ctx
+		if ctx == nil {
+			continue
+		}
+		if done := ctx.Done(); done != nil {
+			if watched[done] {
+				continue
+			}
+			if watched == nil {
+				watched = map[<-chan struct{}]bool{}
+			}
+			watched[done] = true
+		}
+		p.watchContext(ctx)
+	}
+}
+
+// InheritOptions applies the settings of parent that promises chained from
+// parent inherit, its context, WithConversions and WithPriority, to p, and
+// returns p.
+// It's for promises that depend on parent in ways the package can't see,
+// such as ones created inside a factory passed to AllWithLimit or inside
+// a function passed to Then.
+func (p *Promise) InheritOptions(parent *Promise) *Promise {
+	p.checkCopy()
+	p.watchContext(parent.ctx)
+	if atomic.LoadInt32(&parent.conversions) != 0 {
+		p.WithConversions()
+	}
+	p.inheritPriority(parent, false)
+	return p
+}
@@ -0,0 +1,107 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCtxPassesContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "garlic")
+	p := NewCtx(ctx, func(ctx context.Context, suffix string) string {
+		return ctx.Value(key{}).(string) + suffix
+	}, " bread")
+	var result string
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, "garlic bread", result)
+}
+
+func TestNewCtxCancelsChain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	p := NewCtx(ctx, func(ctx context.Context) int {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return 1
+	})
+	ran := false
+	chained := p.Then(func(x int) int {
+		ran = true
+		return x
+	})
+	<-started
+	cancel()
+
+	err := chained.Wait(new(int))
+	require.Error(t, err)
+	require.Equal(t, context.Canceled, cause(err))
+	require.Equal(t, context.Canceled, cause(p.Wait(new(int))))
+	require.False(t, ran)
+}
+
+func TestNewCtxRequiresContextArgument(t *testing.T) {
+	require.Panics(t, func() {
+		NewCtx(context.Background(), func(x int) {}, 1)
+	})
+}
+
+func TestInheritOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	parent := NewCtx(ctx, func(ctx context.Context) int { return 1 }).WithConversions()
+	require.NoError(t, parent.Wait(new(int)))
+
+	release := make(chan struct{})
+	defer close(release)
+	child := New(func() int {
+		<-release
+		return 2
+	}).InheritOptions(parent)
+	var result int64
+	require.NotPanics(t, func() { child.Then(func(x int64) {}) })
+
+	cancel()
+	require.Equal(t, context.Canceled, cause(child.Wait(&result)))
+}
+
+func TestFilterInheritsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	p := Filter([]int{1, 2}, func(x int) *Promise {
+		return NewCtx(ctx, func(ctx context.Context) bool {
+			<-release
+			return true
+		})
+	})
+	cancel()
+	require.Equal(t, context.Canceled, cause(p.Wait(new([]int))))
+}
+
+func TestCombinatorsWatchSharedContextOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	inputs := make([]*Promise, 100)
+	for i := range inputs {
+		inputs[i] = NewCtx(ctx, func(ctx context.Context) int {
+			<-release
+			return 1
+		})
+	}
+	before := runtime.NumGoroutine()
+	all := All(inputs...)
+	race := Race(inputs...)
+	any := Any(inputs...)
+	require.LessOrEqual(t, runtime.NumGoroutine()-before, 3, "one context watcher per combinator")
+
+	cancel()
+	for _, p := range []*Promise{all, race, any} {
+		require.True(t, errors.Is(p.Wait(), context.Canceled))
+	}
+	close(release)
+}
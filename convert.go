@@ -0,0 +1,54 @@
+package promise
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// WithConversions lets Wait and Then accept destination types that p's
+// results can be converted to, such as int64 to int, []byte to string, or
+// between a type and one defined on top of it, instead of requiring a type
+// the result is assignable to. It must be called before the Wait or Then
+// calls it should affect, and returns p for chaining.
+func (p *Promise) WithConversions() *Promise {
+	p.checkCopy()
+	atomic.StoreInt32(&p.conversions, 1)
+	return p
+}
+
+// accepts reports whether a result of type result can be delivered to a
+// destination of type dest, as it can be to any destination it is
+// assignable to, such as an interface it implements.
+func (p *Promise) accepts(result, dest reflect.Type) bool {
+	if result.AssignableTo(dest) {
+		return true
+	}
+	return atomic.LoadInt32(&p.conversions) != 0 && convertible(result, dest)
+}
+
+// convertible reports whether values of type from may be converted to
+// type to. Conversions from integers to strings are refused, since they
+// produce a rune rather than the number's digits.
+func convertible(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	return !(isInteger(from.Kind()) && to.Kind() == reflect.String)
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// convertValue returns v as a value of type t, converting it if needed.
+func convertValue(v reflect.Value, t reflect.Type) reflect.Value {
+	if v.Type().AssignableTo(t) {
+		return v
+	}
+	return v.Convert(t)
+}
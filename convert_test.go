@@ -0,0 +1,125 @@
+package promise
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type celsius float64
+
+func TestWithConversionsWait(t *testing.T) {
+	p := New(func() (int64, []byte, float64) {
+		return 42, []byte("garlic"), 21.5
+	}).WithConversions()
+	var n int
+	var s string
+	var temp celsius
+	require.NoError(t, p.Wait(&n, &s, &temp))
+	require.Equal(t, 42, n)
+	require.Equal(t, "garlic", s)
+	require.Equal(t, celsius(21.5), temp)
+}
+
+func TestWithConversionsThen(t *testing.T) {
+	p := New(func() int32 {
+		return 7
+	}).WithConversions()
+	doubled := p.Then(func(x int) int {
+		return x * 2
+	})
+	var result int
+	require.NoError(t, doubled.Wait(&result))
+	require.Equal(t, 14, result)
+}
+
+func TestConversionsAreOptIn(t *testing.T) {
+	SetStrict(true)
+	defer SetStrict(defaultStrict)
+	p := New(func() int64 {
+		return 42
+	})
+	require.Panics(t, func() {
+		var n int
+		_ = p.Wait(&n)
+	})
+	require.Panics(t, func() {
+		p.Then(func(int) {})
+	})
+}
+
+func TestConversionsRefuseIntegerToString(t *testing.T) {
+	p := New(func() int {
+		return 65
+	}).WithConversions()
+	require.Panics(t, func() {
+		var s string
+		_ = p.Wait(&s)
+	})
+}
+
+func TestAssignableResults(t *testing.T) {
+	SetStrict(true)
+	defer SetStrict(defaultStrict)
+	p := New(func() *bytes.Buffer {
+		return bytes.NewBufferString("garlic")
+	})
+	read := p.Then(func(r io.Reader) (string, error) {
+		b, err := io.ReadAll(r)
+		return string(b), err
+	})
+	var s string
+	require.NoError(t, read.Wait(&s))
+	require.Equal(t, "garlic", s)
+
+	var buf *bytes.Buffer
+	var stringer fmt.Stringer
+	var anything interface{}
+	require.NoError(t, p.Wait(&buf))
+	require.NoError(t, p.Wait(&stringer))
+	require.NoError(t, p.Wait(&anything))
+	require.Same(t, buf, stringer)
+	require.Same(t, buf, anything)
+
+	joined := Join(func(r io.Reader, n int) int { return n }, p, Resolved(1))
+	require.NoError(t, joined.Wait(new(int)))
+	require.Panics(t, func() { p.Then(func(io.Writer, int) {}) })
+	require.Panics(t, func() { p.Wait(new(io.Closer)) })
+}
+
+type idList []int
+
+func TestAssignableCollections(t *testing.T) {
+	SetStrict(true)
+	defer SetStrict(defaultStrict)
+	s := NewStream(reflect.TypeOf(&bytes.Buffer{}))
+	lengths := s.Then(func(r io.Reader) int {
+		b, _ := io.ReadAll(r)
+		return len(b)
+	}).Reduce(func(acc interface{}, n int) int { return acc.(int) + n }, 0)
+	s.Emit(bytes.NewBufferString("garlic"))
+	s.Close()
+	var total interface{}
+	require.NoError(t, lengths.Wait(&total))
+	require.Equal(t, 6, total)
+
+	var sizes []int
+	require.NoError(t, Batch([]int{1, 2, 3}, 2, func(ids idList) []int {
+		return []int{len(ids)}
+	}).Wait(&sizes))
+	require.Equal(t, []int{2, 1}, sizes)
+
+	buffers := []*bytes.Buffer{bytes.NewBufferString("a"), bytes.NewBufferString("bb")}
+	var names []string
+	require.NoError(t, Each(buffers, fmt.Stringer.String).Wait(&names))
+	require.Equal(t, []string{"a", "bb"}, names)
+	require.NoError(t, Each(buffers, func(s fmt.Stringer) *Promise {
+		return Resolved(s.String())
+	}).Wait(&names))
+	require.Equal(t, []string{"a", "bb"}, names)
+	require.Panics(t, func() { Each(buffers, func(io.Closer) int { return 0 }) })
+}
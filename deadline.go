@@ -0,0 +1,69 @@
+package promise
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A DeadlineConflict describes a promise whose timeout is longer than the
+// time left before the deadline of the context it inherited, so the
+// timeout can never fire.
+type DeadlineConflict struct {
+	Name string
+	// Timeout is the promise's timeout, and Remaining the time left
+	// before its context's deadline when the timeout was started.
+	Timeout   time.Duration
+	Remaining time.Duration
+}
+
+// A DeadlineCheck decides what happens on a DeadlineConflict.
+type DeadlineCheck struct {
+	// OnConflict is called with every conflict, on the goroutine starting
+	// the timeout. If it is nil, conflicts are logged.
+	OnConflict func(DeadlineConflict)
+	// Clamp shortens conflicting timeouts to the time remaining, so the
+	// promise is rejected with its timeout error rather than the
+	// context's.
+	Clamp bool
+}
+
+var deadlineCheck atomic.Value
+
+// SetDeadlineCheck installs c to handle promises whose timeouts outlast
+// their context's deadline, as set by ThenWithTimeout, WithTimeout or a
+// stage budget. Passing nil restores the default, which logs conflicts
+// without clamping.
+func SetDeadlineCheck(c *DeadlineCheck) {
+	deadlineCheck.Store(c)
+}
+
+// checkDeadline returns the timeout to use for p in place of timeout,
+// reporting a conflict if p's context has less time left than that.
+func (p *Promise) checkDeadline(timeout time.Duration) time.Duration {
+	if p.ctx == nil {
+		return timeout
+	}
+	deadline, ok := p.ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+	remaining := deadline.Sub(p.now())
+	if remaining >= timeout {
+		return timeout
+	}
+	conflict := DeadlineConflict{Name: p.Name(), Timeout: timeout, Remaining: remaining}
+	c, _ := deadlineCheck.Load().(*DeadlineCheck)
+	if c == nil || c.OnConflict == nil {
+		logf("promise: %s has a timeout of %s but its context's deadline is in %s",
+			conflict.Name, conflict.Timeout, conflict.Remaining.Round(time.Millisecond))
+	} else {
+		c.OnConflict(conflict)
+	}
+	if c != nil && c.Clamp {
+		if remaining < 0 {
+			remaining = 0
+		}
+		return remaining
+	}
+	return timeout
+}
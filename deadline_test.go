@@ -0,0 +1,66 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlineConflictLogged(t *testing.T) {
+	logs := make(chanLogger, 10)
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p := NewCtx(ctx, func(context.Context) int { return 1 }).
+		ThenWithTimeout(5*time.Second, func(n int) int { return n })
+	require.NoError(t, p.Wait(new(int)))
+	msg := <-logs
+	require.True(t, strings.Contains(msg, "has a timeout of 5s but its context's deadline is in"), msg)
+}
+
+func TestDeadlineConflictHook(t *testing.T) {
+	conflicts := make(chan DeadlineConflict, 10)
+	SetDeadlineCheck(&DeadlineCheck{OnConflict: func(c DeadlineConflict) { conflicts <- c }})
+	defer SetDeadlineCheck(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	root := NewCtx(ctx, func(context.Context) int { return 1 })
+	require.NoError(t, root.ThenWithTimeout(time.Millisecond*500, func(n int) int { return n }).Wait(new(int)))
+	require.NoError(t, root.WithTimeout(time.Minute).Wait(new(int)))
+
+	c := <-conflicts
+	require.Equal(t, "WithTimeout", c.Name)
+	require.Equal(t, time.Minute, c.Timeout)
+	require.True(t, c.Remaining > 0 && c.Remaining <= time.Second, c.Remaining)
+	require.Len(t, conflicts, 0, "the 500ms timeout fits in the deadline")
+}
+
+func TestDeadlineCheckClamp(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	SetClock(clock)
+	defer SetClock(nil)
+	SetDeadlineCheck(&DeadlineCheck{OnConflict: func(DeadlineConflict) {}, Clamp: true})
+	defer SetDeadlineCheck(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	p := NewCtx(ctx, func(context.Context) {}).ThenWithTimeout(2*time.Hour, func() {
+		close(started)
+		<-release
+	})
+	<-started
+	// The fake clock started slightly before the context, so a little
+	// more than an hour is left.
+	clock.Advance(time.Hour + time.Minute)
+	require.True(t, errors.Is(p.Wait(), ErrThenTimeout))
+}
@@ -0,0 +1,112 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// debugDepth is how many frames of the creation stack are recorded in
+// debug mode.
+const debugDepth = 32
+
+var debugMode int32
+
+// EnableDebug makes promises record the stack they were created at, and
+// the errors returned by Wait and friends say where the promise that
+// failed was created, and where its function panicked if it did:
+//
+//	error during promise execution: promise created at main.go:42, panicked at main.go:17: panic: boom
+//
+// That tells apart the promises of a chain built in a loop. Debug mode
+// also tracks the promises for DumpPending, DumpGraph, CancelSubtree and
+// Stats.OldestPending, and labels the goroutines
+// running their functions with pprof labels. It slows down creating and
+// running promises several times over, so it is meant for development
+// and tests; with it off, none of that costs anything. It only affects
+// promises created afterwards.
+func EnableDebug() {
+	atomic.StoreInt32(&debugMode, 1)
+}
+
+// DisableDebug turns off debug mode.
+func DisableDebug() {
+	atomic.StoreInt32(&debugMode, 0)
+}
+
+func debugging() bool {
+	return atomic.LoadInt32(&debugMode) != 0
+}
+
+// recordStack records the stack p is being created at. It must be called
+// by initPromise.
+func (p *Promise) recordStack() {
+	stack := make([]uintptr, debugDepth)
+	// Skip runtime.Callers, recordStack, initPromise and its caller.
+	p.extras().stack = stack[:runtime.Callers(4, stack)]
+}
+
+// originOf returns the promise that err, which p is being rejected with,
+// came from: the parent p inherited it from, or else p itself.
+func (p *Promise) originOf(err error) *Promise {
+	p.mu.Lock()
+	parents := p.parents
+	p.mu.Unlock()
+	for _, parent := range parents {
+		parent.mu.Lock()
+		perr, origin := parent.err, parent.peekExtras().origin
+		parent.mu.Unlock()
+		if origin != nil && sameError(perr, err) {
+			return origin
+		}
+	}
+	return p
+}
+
+// sameError reports whether a and b are the same error, without
+// panicking on errors whose types aren't comparable.
+func sameError(a, b error) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// debugError adds where the promise p was rejected by was created, and
+// where it panicked, to err.
+func (p *Promise) debugError(err error) error {
+	p.mu.Lock()
+	origin := p.peekExtras().origin
+	p.mu.Unlock()
+	if origin == nil {
+		return err
+	}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) && panicErr.site != "" {
+		return fmt.Errorf("promise created at %s, panicked at %s: %w", origin.creationSite(), panicErr.site, err)
+	}
+	return fmt.Errorf("promise created at %s: %w", origin.creationSite(), err)
+}
+
+// panicSite returns the file and line a panic happened at. It must be
+// called while panicking, from a deferred function.
+func panicSite() string {
+	pcs := make([]uintptr, debugDepth)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		} else if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
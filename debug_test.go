@@ -0,0 +1,63 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// line returns the line it is called from.
+func line() int {
+	_, _, n, _ := runtime.Caller(1)
+	return n
+}
+
+func TestDebugCreationSite(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+	failure := errors.New("failed")
+	var created int
+	p := Resolved(0)
+	for i := 0; i < 3; i++ {
+		i := i
+		if i == 1 {
+			created = line() + 2
+		}
+		p = p.Then(func(n int) (int, error) {
+			if i == 1 {
+				return 0, failure
+			}
+			return n + 1, nil
+		})
+	}
+	err := p.Wait(new(int))
+	require.Equal(t, failure, cause(err))
+	require.Contains(t, err.Error(), fmt.Sprintf("promise created at %s:%d", thisFile(), created))
+}
+
+func TestDebugPanicSite(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+	var panicked int
+	p := New(func() int {
+		var m map[string]int
+		panicked = line() + 1
+		m["x"] = 1
+		return 0
+	})
+	err := p.Wait(new(int))
+	require.Contains(t, err.Error(), fmt.Sprintf("panicked at %s:%d", thisFile(), panicked))
+}
+
+func TestDebugOff(t *testing.T) {
+	err := Rejected(errors.New("failed")).Wait()
+	require.NotContains(t, err.Error(), "promise created at")
+}
+
+func thisFile() string {
+	_, file, _, _ := runtime.Caller(0)
+	return file
+}
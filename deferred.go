@@ -0,0 +1,68 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// A Deferred is a promise settled from outside, by calling Resolve or
+// Reject, rather than by running a function. It bridges callback-style
+// APIs, such as event handlers or cgo callbacks, into promises without
+// parking a goroutine in a blocking function.
+type Deferred struct {
+	*Promise
+}
+
+// NewDeferred returns a pending Deferred whose promise resolves with values
+// of the given types.
+func NewDeferred(types ...reflect.Type) *Deferred {
+	p := newPromise(signalCall, "Deferred")
+	p.resultType = append([]reflect.Type{}, types...)
+	newGraph(p)
+	return &Deferred{Promise: p}
+}
+
+// Resolve fulfills the promise with values, which must match the types
+// passed to NewDeferred. A nil value stands for the zero value of its type.
+// Only the first call to Resolve or Reject has any effect.
+func (d *Deferred) Resolve(values ...interface{}) {
+	if len(values) != len(d.resultType) {
+		panic(fmt.Errorf("Deferred resolves with %d values, Resolve was passed %d values", len(d.resultType), len(values)))
+	}
+	results := make([]reflect.Value, len(values))
+	for i, value := range values {
+		results[i] = valueAs(d.resultType[i], value, i)
+	}
+	d.settle(results, nil)
+}
+
+// valueAs returns value, the ith of some values, as a t. A nil value
+// stands for the zero t, if t can be nil. It panics if value isn't
+// assignable to t.
+func valueAs(t reflect.Type, value interface{}, i int) reflect.Value {
+	result := reflect.New(t).Elem()
+	if value != nil {
+		rv := reflect.ValueOf(value)
+		if !rv.Type().AssignableTo(t) {
+			panic(fmt.Errorf("for value %d: expected type %s got type %s", i, t, rv.Type()))
+		}
+		result.Set(rv)
+		return result
+	}
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+	default:
+		panic(fmt.Errorf("for value %d: nil is not a valid %s", i, t))
+	}
+	return result
+}
+
+// Reject fails the promise with err, which must not be nil. Only the first
+// call to Resolve or Reject has any effect.
+func (d *Deferred) Reject(err error) {
+	if err == nil {
+		panic(errors.New("promise: Deferred.Reject called with a nil error"))
+	}
+	d.settle(nil, err)
+}
@@ -0,0 +1,63 @@
+package promise
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeferredResolve(t *testing.T) {
+	d := NewDeferred(reflect.TypeOf(""), reflect.TypeOf(0))
+	greeting := d.Then(func(name string, times int) string {
+		return name + "!"
+	})
+	go func() {
+		d.Resolve("hello", 2)
+		d.Reject(errors.New("ignored"))
+	}()
+
+	var result string
+	require.NoError(t, greeting.Wait(&result))
+	require.Equal(t, "hello!", result)
+
+	var name string
+	var times int
+	require.NoError(t, d.Wait(&name, &times))
+	require.Equal(t, "hello", name)
+	require.Equal(t, 2, times)
+}
+
+func TestDeferredResolveInterfaceAndNil(t *testing.T) {
+	d := NewDeferred(reflect.TypeOf((*error)(nil)).Elem(), reflect.TypeOf((*io.Reader)(nil)).Elem())
+	failure := errors.New("failure")
+	d.Resolve(failure, nil)
+
+	var err error
+	var r io.Reader
+	require.NoError(t, d.Wait(&err, &r))
+	require.Equal(t, failure, err)
+	require.Nil(t, r)
+}
+
+func TestDeferredReject(t *testing.T) {
+	d := NewDeferred(reflect.TypeOf(0))
+	d.Reject(errors.New("callback failed"))
+	err := d.Wait(new(int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "callback failed")
+
+	d = NewDeferred(reflect.TypeOf(0))
+	require.Panics(t, func() { d.Reject(nil) })
+	require.Equal(t, StatePending, d.State())
+}
+
+func TestDeferredResolveChecksValues(t *testing.T) {
+	d := NewDeferred(reflect.TypeOf(0))
+	require.Panics(t, func() { d.Resolve() })
+	require.Panics(t, func() { d.Resolve("one") })
+	require.Panics(t, func() { d.Resolve(nil) })
+	require.False(t, d.isSettled())
+}
@@ -0,0 +1,37 @@
+package promise
+
+import (
+	"reflect"
+	"time"
+)
+
+// After returns a promise that resolves with no values once d has passed.
+// Unlike wrapping time.Sleep in New, it parks no goroutine while it waits,
+// and canceling it stops its timer.
+func After(d time.Duration) *Promise {
+	p := newPromise(signalCall, "After")
+	p.resultType = []reflect.Type{}
+	newGraph(p)
+	timer := afterFunc(d, func() {
+		p.settle([]reflect.Value{}, nil)
+	})
+	p.Defer(func() { timer.Stop() })
+	return p
+}
+
+// Delay returns a promise that settles like p, but d after p does.
+func (p *Promise) Delay(d time.Duration) *Promise {
+	p.checkCopy()
+	p.observe()
+	next := newPromise(signalCall, "Delay")
+	next.resultType = p.resultType
+	p.graph.addChild(p, next)
+	next.watchContext(p.ctx)
+	p.whenSettled(func() {
+		timer := next.afterFunc(d, func() {
+			next.settle(p.results, p.err)
+		})
+		next.Defer(func() { timer.Stop() })
+	})
+	return next
+}
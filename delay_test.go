@@ -0,0 +1,33 @@
+package promise
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAfter(t *testing.T) {
+	start := time.Now()
+	require.NoError(t, After(20*time.Millisecond).Wait())
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(20*time.Millisecond))
+}
+
+func TestAfterCancel(t *testing.T) {
+	p := After(time.Hour)
+	p.Cancel()
+	require.Equal(t, ErrCanceled, cause(p.Wait()))
+}
+
+func TestDelay(t *testing.T) {
+	start := time.Now()
+	var result int
+	require.NoError(t, Resolved(1).Delay(20*time.Millisecond).Wait(&result))
+	require.Equal(t, 1, result)
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(20*time.Millisecond))
+
+	failure := fmt.Errorf("failure")
+	err := Rejected(failure, typeOf[int]()).Delay(time.Millisecond).Wait(&result)
+	require.Equal(t, failure, cause(err))
+}
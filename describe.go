@@ -0,0 +1,109 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// maxDescribedValue is the length values and errors are truncated to by
+// String and Describe.
+const maxDescribedValue = 64
+
+// String returns a one-line summary of p, such as
+// `promise main.fetch (int, string) fulfilled after 12ms: [42 "ok"]`, so
+// p can be dropped into a log line. It doesn't block or count as reading
+// p's results.
+func (p *Promise) String() string {
+	d := p.describe()
+	s := fmt.Sprintf("promise %s %s %s %s", d.name, d.types, d.state, d.elapsed)
+	if d.outcome != "" {
+		s += ": " + d.outcome
+	}
+	return s
+}
+
+// Describe is like String, but puts each field on its own line and adds
+// the chain of promises p was created from, as named by WithName:
+//
+//	promise main.fetch
+//	  chain:   fetch google → read body → main.fetch
+//	  types:   (int, string)
+//	  state:   fulfilled
+//	  elapsed: 12ms
+//	  results: [42 "ok"]
+func (p *Promise) Describe() string {
+	d := p.describe()
+	var b strings.Builder
+	fmt.Fprintf(&b, "promise %s\n", d.name)
+	if labels, _ := p.ancestry(); len(labels) > 1 {
+		fmt.Fprintf(&b, "  chain:   %s\n", strings.Join(labels, " → "))
+	}
+	fmt.Fprintf(&b, "  types:   %s\n", d.types)
+	fmt.Fprintf(&b, "  state:   %s\n", d.state)
+	fmt.Fprintf(&b, "  elapsed: %s\n", d.elapsed)
+	switch d.state {
+	case StateFulfilled:
+		fmt.Fprintf(&b, "  results: %s\n", d.outcome)
+	case StateRejected:
+		fmt.Fprintf(&b, "  error:   %s\n", d.outcome)
+	}
+	return b.String()
+}
+
+type description struct {
+	name    string
+	types   string
+	state   State
+	elapsed string
+	// outcome is p's results or error once it has settled
+	outcome string
+}
+
+func (p *Promise) describe() description {
+	d := description{name: p.Name(), types: describeTypes(p.resultType)}
+	if d.name == "" {
+		d.name = "<anonymous>"
+	}
+	timings := p.Timings()
+	if timings.Settled.IsZero() {
+		d.state = StatePending
+		d.elapsed = fmt.Sprintf("for %s", p.now().Sub(timings.Created).Round(time.Millisecond))
+		return d
+	}
+	d.elapsed = fmt.Sprintf("after %s", timings.Total().Round(time.Millisecond))
+	if p.err != nil {
+		d.state = StateRejected
+		d.outcome = truncate(p.err.Error())
+		return d
+	}
+	d.state = StateFulfilled
+	results, _, err := p.retained()
+	if err != nil {
+		d.outcome = truncate(err.Error())
+		return d
+	}
+	values := make([]string, len(results))
+	for i, result := range results {
+		values[i] = truncate(fmt.Sprintf("%#v", result.Interface()))
+	}
+	d.outcome = "[" + strings.Join(values, " ") + "]"
+	return d
+}
+
+func describeTypes(types []reflect.Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+// truncate shortens s to maxDescribedValue bytes, marking where it was cut.
+func truncate(s string) string {
+	if len(s) <= maxDescribedValue {
+		return s
+	}
+	return s[:maxDescribedValue] + "..."
+}
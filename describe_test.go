@@ -0,0 +1,51 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func describeFetch() (int, string) { return 42, "ok" }
+
+func TestStringFulfilled(t *testing.T) {
+	p := New(describeFetch)
+	require.NoError(t, p.Wait(new(int), new(string)))
+	s := fmt.Sprint(p)
+	require.True(t, strings.HasPrefix(s, "promise github.com/garlicnation/promises/v2.describeFetch (int, string) fulfilled after "), s)
+	require.True(t, strings.HasSuffix(s, `: [42 "ok"]`), s)
+}
+
+func TestStringRejectedAndPending(t *testing.T) {
+	rejected := Rejected(errors.New("boom"), typeOf[int]())
+	require.Error(t, rejected.Wait(new(int)))
+	require.Contains(t, rejected.String(), " (int) rejected after ")
+	require.True(t, strings.HasSuffix(rejected.String(), ": boom"), rejected.String())
+
+	d := NewDeferred()
+	require.Contains(t, d.Promise.String(), "() pending for ")
+	d.Resolve()
+}
+
+func TestDescribe(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	p := Resolved(long)
+	desc := p.Describe()
+	require.Contains(t, desc, "\n  types:   (string)\n  state:   fulfilled\n")
+	require.Contains(t, desc, "  results: [\""+strings.Repeat("x", maxDescribedValue-1)+"...]\n")
+
+	var s string
+	require.NoError(t, p.Wait(&s))
+	require.Equal(t, long, s, "describing doesn't consume results")
+}
+
+func TestDescribePending(t *testing.T) {
+	d := NewDeferred(typeOf[int]())
+	desc := d.Promise.Describe()
+	require.Contains(t, desc, "state:   pending")
+	require.NotContains(t, desc, "results:")
+	d.Resolve(1)
+}
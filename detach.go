@@ -0,0 +1,59 @@
+package promise
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Detach marks p as intentionally fire-and-forget. Nothing will wait on
+// p, so its rejection is not reported as unhandled; instead the error is
+// passed to onError, or to the package Logger if onError is nil.
+func (p *Promise) Detach(onError func(error)) {
+	p.checkCopy()
+	p.observe()
+	if onError == nil {
+		onError = func(err error) {
+			logf("promise: detached promise %s failed: %v", p.Name(), err)
+		}
+	}
+	p.whenSettled(func() {
+		if err := p.err; err != nil {
+			// onError may block, unlike continuations.
+			spawn(func() { onError(err) })
+		}
+	})
+}
+
+// A rejectionTracker reports the error of a rejected promise that is
+// garbage collected without anything having observed it. It is allocated
+// separately from the promise and holds no reference back to it, because
+// a finalizer never runs on an object that is part of a cycle, and
+// promises are linked into graphs.
+type rejectionTracker struct {
+	name    string
+	err     error
+	handled int32
+}
+
+func (t *rejectionTracker) handle() {
+	atomic.StoreInt32(&t.handled, 1)
+}
+
+// trackRejection arranges for p's error to be logged if p is collected
+// while still unobserved.
+func (p *Promise) trackRejection() {
+	t := &rejectionTracker{name: p.Name(), err: p.err}
+	runtime.SetFinalizer(t, reportUnhandled)
+	p.extras().rejection.Store(t)
+	if atomic.LoadInt32(&p.observed) != 0 {
+		// Observed while the tracker was being installed.
+		t.handle()
+	}
+}
+
+func reportUnhandled(t *rejectionTracker) {
+	if atomic.LoadInt32(&t.handled) != 0 {
+		return
+	}
+	logf("promise: unhandled rejection in %s: %v", t.name, t.err)
+}
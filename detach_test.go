@@ -0,0 +1,82 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetachRoutesErrorToHandler(t *testing.T) {
+	errs := make(chan error, 1)
+	New(func() error {
+		return errors.New("detached failure")
+	}).Detach(func(err error) {
+		errs <- err
+	})
+	require.EqualError(t, <-errs, "detached failure")
+}
+
+type chanLogger chan string
+
+func (l chanLogger) Printf(format string, args ...interface{}) {
+	select {
+	case l <- fmt.Sprintf(format, args...):
+	default:
+	}
+}
+
+func TestDetachWithoutHandlerLogs(t *testing.T) {
+	logs := make(chanLogger, 100)
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	New(func() error {
+		return errors.New("detached failure")
+	}).Detach(nil)
+	for msg := range logs {
+		if strings.Contains(msg, "detached failure") {
+			break
+		}
+	}
+}
+
+func TestUnhandledRejectionIsLogged(t *testing.T) {
+	logs := make(chanLogger, 100)
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	func() {
+		p := New(func() error {
+			return errors.New("nobody listened")
+		})
+		p.await()
+	}()
+	pollUntil(t, func() bool {
+		runtime.GC()
+		select {
+		case msg := <-logs:
+			return strings.Contains(msg, "unhandled rejection") && strings.Contains(msg, "nobody listened")
+		default:
+			return false
+		}
+	})
+}
+
+func TestDetachDoesNotSpawnWhilePending(t *testing.T) {
+	deferreds := make([]*Deferred, 100)
+	for i := range deferreds {
+		deferreds[i] = NewDeferred()
+	}
+	before := runtime.NumGoroutine()
+	for _, d := range deferreds {
+		d.Detach(func(error) {})
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), before)
+	for _, d := range deferreds {
+		d.Resolve()
+	}
+}
@@ -0,0 +1,91 @@
+package promise
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dotColors are the fill colors DOT nodes get for each state, matching
+// the Mermaid classes.
+var dotColors = map[State]string{
+	StatePending:   "#fff3bf",
+	StateFulfilled: "#d3f9d8",
+	StateRejected:  "#ffe3e3",
+}
+
+// DOT writes g as a Graphviz digraph, with an edge from every promise to
+// each promise chained from it. Nodes are labeled with the promise's name,
+// its state and how long it has been pending or took to settle, at the
+// time of the call.
+func (g *Graph) DOT(w io.Writer) error {
+	return writeDOT(w, []*Promise{g.root})
+}
+
+// DumpGraph writes every graph that has a pending promise created in
+// debug mode as a single Graphviz digraph, like Graph.DOT, for a snapshot
+// of what the program is waiting on. See EnableDebug.
+func DumpGraph(w io.Writer) error {
+	tracked.Lock()
+	seen := map[*Graph]bool{}
+	var roots []*Promise
+	for p := range tracked.pending {
+		if p.graph != nil && !seen[p.graph] {
+			seen[p.graph] = true
+			roots = append(roots, p.graph.root)
+		}
+	}
+	tracked.Unlock()
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].createdAt().Before(roots[j].createdAt())
+	})
+	return writeDOT(w, roots)
+}
+
+func writeDOT(w io.Writer, roots []*Promise) error {
+	now := currentTime()
+	var b strings.Builder
+	b.WriteString("digraph promises {\n")
+	b.WriteString("    node [shape=box, style=filled];\n")
+	ids := map[*Promise]string{}
+	queue := []*Promise{}
+	for _, root := range roots {
+		if _, ok := ids[root]; !ok {
+			ids[root] = fmt.Sprintf("p%d", len(ids))
+			queue = append(queue, root)
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		state := p.State()
+		fmt.Fprintf(&b, "    %s [label=%s, fillcolor=%q];\n", ids[p], strconv.Quote(dotLabel(p, state, now)), dotColors[state])
+		p.mu.Lock()
+		children := append([]*Promise{}, p.children...)
+		p.mu.Unlock()
+		for _, child := range children {
+			if _, ok := ids[child]; !ok {
+				ids[child] = fmt.Sprintf("p%d", len(ids))
+				queue = append(queue, child)
+			}
+			fmt.Fprintf(&b, "    %s -> %s;\n", ids[p], ids[child])
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotLabel returns the text of p's node, such as "fetch\npending 1.2s".
+func dotLabel(p *Promise, state State, now time.Time) string {
+	timings := p.Timings()
+	elapsed := timings.Total()
+	if state == StatePending {
+		elapsed = now.Sub(timings.Created)
+	}
+	label, _ := p.label()
+	return fmt.Sprintf("%s\n%s %s", label, state, elapsed.Round(time.Millisecond))
+}
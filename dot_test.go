@@ -0,0 +1,46 @@
+package promise
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphDOT(t *testing.T) {
+	signal := Signal()
+	root := signal.Promise()
+	ok := root.Then(func() {})
+	failed := root.Then(func() error { return errors.New("failed") })
+	All(ok, failed).Catch(func(error) {})
+
+	var b strings.Builder
+	require.NoError(t, root.Graph().DOT(&b))
+	out := b.String()
+	require.True(t, strings.HasPrefix(out, "digraph promises {\n    node [shape=box, style=filled];\n    p0 [label=\"Signal\\npending "), out)
+	require.Contains(t, out, "    p0 -> p1;\n    p0 -> p2;\n")
+	require.Contains(t, out, "p3 [label=\"All\\npending ")
+	require.Contains(t, out, "    p3 -> p4;\n")
+	require.True(t, strings.HasSuffix(out, "}\n"), out)
+
+	signal.Resolve()
+	require.Error(t, failed.Wait())
+	b.Reset()
+	require.NoError(t, root.Graph().DOT(&b))
+	require.Contains(t, b.String(), `p0 [label="Signal\nfulfilled `)
+	require.Contains(t, b.String(), `fillcolor="#ffe3e3"`)
+}
+
+func TestDumpGraph(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+	d := NewDeferred()
+	d.Promise.WithName("stalled").Then(func() {})
+
+	var b bytes.Buffer
+	require.NoError(t, DumpGraph(&b))
+	require.Contains(t, b.String(), `[label="stalled\npending `)
+	d.Resolve()
+}
@@ -0,0 +1,148 @@
+package promise
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// packagePrefix identifies frames inside this package, which are skipped
+// when reporting where a promise was created.
+const packagePrefix = "github.com/garlicnation/promises/v2."
+
+// tracked holds the promises created in debug mode.
+var tracked = struct {
+	sync.Mutex
+	pending map[*Promise]struct{}
+	// goroutines maps the ID of every goroutine running on behalf of a
+	// promise to that promise.
+	goroutines map[int64]*Promise
+}{
+	pending:    map[*Promise]struct{}{},
+	goroutines: map[int64]*Promise{},
+}
+
+// pendingCount and runningCount count the promises that haven't settled
+// and the functions of promises running, for ReadStats.
+var pendingCount, runningCount int64
+
+// trackPending counts p as pending and, in debug mode, records it for
+// DumpPending.
+func trackPending(p *Promise) {
+	atomic.AddInt64(&pendingCount, 1)
+	if !debugging() {
+		return
+	}
+	p.tracked = true
+	tracked.Lock()
+	tracked.pending[p] = struct{}{}
+	tracked.Unlock()
+}
+
+// untrackPending undoes trackPending, once p has settled or failed to be
+// constructed.
+func untrackPending(p *Promise) {
+	atomic.AddInt64(&pendingCount, -1)
+	if !p.tracked {
+		return
+	}
+	tracked.Lock()
+	delete(tracked.pending, p)
+	tracked.Unlock()
+}
+
+// trackGoroutine labels the calling goroutine with p's name and creation
+// site, so profiles and goroutine dumps (debug=1) can be attributed to
+// the promise, and records it for DumpPending. The returned function
+// undoes both. It is only called for promises created in debug mode.
+func (p *Promise) trackGoroutine() (untrack func()) {
+	id := goroutineID()
+	tracked.Lock()
+	tracked.goroutines[id] = p
+	tracked.Unlock()
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels(
+		"promise", p.Name(),
+		"promise_site", p.creationSite(),
+	)))
+	return func() {
+		pprof.SetGoroutineLabels(context.Background())
+		tracked.Lock()
+		delete(tracked.goroutines, id)
+		tracked.Unlock()
+	}
+}
+
+// goroutineID returns the ID of the calling goroutine, as shown in
+// goroutine dumps.
+func goroutineID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	// The stack starts with "goroutine 123 [running]:".
+	field := strings.Fields(string(bytes.TrimPrefix(buf[:n], []byte("goroutine "))))[0]
+	id, _ := strconv.ParseInt(field, 10, 64)
+	return id
+}
+
+// creationSite returns the file and line of the first caller outside this
+// package when p was created, if it was created in debug mode.
+func (p *Promise) creationSite() string {
+	stack := p.peekExtras().stack
+	if stack == nil {
+		return "unknown"
+	}
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && (!strings.HasPrefix(frame.Function, packagePrefix) || strings.Contains(frame.File, "_test.go")) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// DumpPending writes every promise created in debug mode that hasn't
+// settled to w, oldest first, with its name, age, creation site and the
+// IDs of the goroutines working on its behalf, which match the IDs in
+// goroutine dumps. See EnableDebug.
+func DumpPending(w io.Writer) error {
+	now := currentTime()
+	tracked.Lock()
+	pending := make([]*Promise, 0, len(tracked.pending))
+	for p := range tracked.pending {
+		pending = append(pending, p)
+	}
+	goroutines := map[*Promise][]int64{}
+	for id, p := range tracked.goroutines {
+		goroutines[p] = append(goroutines[p], id)
+	}
+	tracked.Unlock()
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].createdAt().Before(pending[j].createdAt())
+	})
+	for _, p := range pending {
+		ids := goroutines[p]
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		checkpoint := ""
+		if last := p.LastCheckpoint(); !last.IsZero() {
+			checkpoint = fmt.Sprintf(", last checkpoint %s ago", now.Sub(last).Round(time.Millisecond))
+		}
+		_, err := fmt.Fprintf(w, "%s: pending for %s, created at %s, goroutines %v%s\n",
+			p.Name(), now.Sub(p.createdAt()).Round(time.Millisecond), p.creationSite(), ids, checkpoint)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
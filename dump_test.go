@@ -0,0 +1,52 @@
+package promise
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func stuckPromise(release chan struct{}) {
+	<-release
+}
+
+func TestDumpPending(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan int64)
+	p := New(func(release chan struct{}) {
+		started <- goroutineID()
+		stuckPromise(release)
+	}, release)
+	id := <-started
+
+	var buf bytes.Buffer
+	require.NoError(t, DumpPending(&buf))
+	require.Contains(t, buf.String(), p.name+": pending for")
+	require.Contains(t, buf.String(), "dump_test.go:")
+	require.Contains(t, buf.String(), "goroutines ["+strconv.FormatInt(id, 10)+"]")
+}
+
+func TestPromiseGoroutinesAreLabeled(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p := New(func(release chan struct{}) {
+		close(started)
+		stuckPromise(release)
+	}, release)
+	<-started
+
+	var buf bytes.Buffer
+	require.NoError(t, pprof.Lookup("goroutine").WriteTo(&buf, 1))
+	close(release)
+	require.NoError(t, p.Wait())
+	require.Contains(t, buf.String(), `"promise":"`+p.name+`"`)
+	require.Contains(t, buf.String(), `"promise_site":"`)
+}
@@ -0,0 +1,40 @@
+package promise
+
+import "reflect"
+
+// An ErrGroup runs functions and collects the first error they return.
+// *errgroup.Group from golang.org/x/sync/errgroup satisfies ErrGroup.
+type ErrGroup interface {
+	Go(f func() error)
+	Wait() error
+}
+
+// AttachTo makes p part of g's lifecycle: g.Wait doesn't return until p
+// has settled, and returns p's error if it was rejected. When g was
+// created with errgroup.WithContext, pass its context to NewCtx for the
+// promise chain to be canceled along with the rest of the group.
+func (p *Promise) AttachTo(g ErrGroup) {
+	p.checkCopy()
+	p.observe()
+	g.Go(func() error {
+		p.await()
+		return p.err
+	})
+}
+
+// FromErrgroup returns a promise that resolves, with no values, once
+// g.Wait returns, or is rejected with the error it returns. Call it after
+// starting the group's functions, as g.Wait is called right away.
+func FromErrgroup(g ErrGroup) *Promise {
+	p := newPromise(signalCall, "FromErrgroup")
+	p.resultType = []reflect.Type{}
+	newGraph(p)
+	spawn(func() {
+		if err := g.Wait(); err != nil {
+			p.settle(nil, err)
+			return
+		}
+		p.settle([]reflect.Value{}, nil)
+	})
+	return p
+}
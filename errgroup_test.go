@@ -0,0 +1,58 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// group is a minimal errgroup.Group.
+type group struct {
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+func (g *group) Go(f func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := f(); err != nil {
+			g.errOnce.Do(func() { g.err = err })
+		}
+	}()
+}
+
+func (g *group) Wait() error {
+	g.wg.Wait()
+	return g.err
+}
+
+func TestAttachTo(t *testing.T) {
+	g := &group{}
+	release := make(chan struct{})
+	p := New(func() error {
+		<-release
+		return errors.New("promise failed")
+	})
+	p.AttachTo(g)
+	g.Go(func() error { return nil })
+	close(release)
+	require.EqualError(t, g.Wait(), "promise failed")
+}
+
+func TestFromErrgroup(t *testing.T) {
+	g := &group{}
+	ran := false
+	g.Go(func() error { ran = true; return nil })
+	require.NoError(t, FromErrgroup(g).Wait())
+	require.True(t, ran)
+
+	g = &group{}
+	g.Go(func() error { return errors.New("task failed") })
+	err := FromErrgroup(g).Then(func() {}).Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "task failed")
+}
@@ -0,0 +1,78 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrorList is the error of a promise whose function was wrapped with
+// JoinErrors and returned a non-empty []error.
+type ErrorList []error
+
+func (err ErrorList) Error() string {
+	msgs := make([]string, len(err))
+	for i, e := range err {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors in the list matches target.
+func (err ErrorList) Is(target error) bool {
+	for _, e := range err {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+var errorSliceType = reflect.TypeOf([]error(nil))
+
+// joinedErrors marks a function passed to JoinErrors.
+type joinedErrors struct {
+	f interface{}
+}
+
+// JoinErrors marks f, a function whose last result is []error, so that
+// New, NewCtx and Then treat that result like a trailing error: the
+// promise is rejected with an ErrorList when the slice holds any non-nil
+// errors, and resolves with f's other results otherwise. This suits batch
+// validators:
+//
+//	p := New(JoinErrors(validate), order)
+//
+// Without JoinErrors, a []error result is an ordinary value.
+func JoinErrors(f interface{}) interface{} {
+	t := reflect.TypeOf(f)
+	if t == nil || t.Kind() != reflect.Func || t.NumOut() == 0 || t.Out(t.NumOut()-1) != errorSliceType {
+		panic(fmt.Errorf("JoinErrors expects a function whose last result is []error, got %v", t))
+	}
+	return joinedErrors{f}
+}
+
+// funcValue returns the function f holds and whether it was wrapped with
+// JoinErrors.
+func funcValue(f interface{}) (functionRv reflect.Value, joinErrors bool) {
+	if j, ok := f.(joinedErrors); ok {
+		return reflect.ValueOf(j.f), true
+	}
+	return reflect.ValueOf(f), false
+}
+
+// errorListOf returns the non-nil errors of errs as an ErrorList, or nil
+// if there are none.
+func errorListOf(errs []error) error {
+	var list ErrorList
+	for _, err := range errs {
+		if err != nil {
+			list = append(list, err)
+		}
+	}
+	if list == nil {
+		return nil
+	}
+	return list
+}
@@ -0,0 +1,63 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errNegative = errors.New("negative")
+
+func validate(xs []int) (int, []error) {
+	var errs []error
+	sum := 0
+	for _, x := range xs {
+		if x < 0 {
+			errs = append(errs, fmt.Errorf("%d: %w", x, errNegative))
+		}
+		sum += x
+	}
+	return sum, errs
+}
+
+func TestJoinErrorsResolvesWithoutErrors(t *testing.T) {
+	var sum int
+	require.NoError(t, New(JoinErrors(validate), []int{1, 2, 3}).Wait(&sum))
+	require.Equal(t, 6, sum)
+}
+
+func TestJoinErrorsRejectsWithErrorList(t *testing.T) {
+	err := New(JoinErrors(validate), []int{1, -2, -3}).Wait(new(int))
+	require.Error(t, err)
+	require.True(t, errors.Is(err, errNegative))
+	var list ErrorList
+	require.True(t, errors.As(err, &list))
+	require.Len(t, list, 2)
+	require.Equal(t, "-2: negative; -3: negative", list.Error())
+}
+
+func TestJoinErrorsThen(t *testing.T) {
+	check := JoinErrors(func(s string) []error {
+		if s == "" {
+			return []error{nil, errors.New("empty")}
+		}
+		return []error{nil}
+	})
+	require.NoError(t, Resolved("x").Then(check).Wait())
+	require.EqualError(t, Resolved("").Then(check).Wait(), "error during promise execution: empty")
+}
+
+func TestErrorSlicesAreValuesByDefault(t *testing.T) {
+	var sum int
+	var errs []error
+	require.NoError(t, New(validate, []int{-1}).Wait(&sum, &errs))
+	require.Equal(t, -1, sum)
+	require.Len(t, errs, 1)
+}
+
+func TestJoinErrorsPanicsOnOtherFunctions(t *testing.T) {
+	require.Panics(t, func() { JoinErrors(func() error { return nil }) })
+	require.Panics(t, func() { JoinErrors(1) })
+}
@@ -0,0 +1,207 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// An ErrorPolicy decides what a Poller does when one of its promises
+// fails.
+type ErrorPolicy int
+
+const (
+	// StopOnError stops polling and closes the stream with the error.
+	StopOnError ErrorPolicy = iota
+	// ContinueOnError skips the failed poll and keeps polling.
+	ContinueOnError
+)
+
+// A Poller creates a promise on a schedule and streams their values, for
+// polling loops such as waiting on a job's status:
+//
+//	poller := Every(time.Second, func() *Promise { return New(jobStatus, id) }, StopOnError)
+//	done := poller.Stream().Then(func(s Status) Status {
+//		if s.Done {
+//			poller.Stop()
+//		}
+//		return s
+//	}).All()
+//
+// Polls never overlap: the next promise is created an interval after the
+// previous one was, or as soon as it settles if it took longer.
+type Poller struct {
+	factory  func() *Promise
+	interval time.Duration
+	policy   ErrorPolicy
+	stream   *Stream
+	elem     reflect.Type
+
+	mu      sync.Mutex
+	stopped bool
+	timer   Timer
+	current *Promise
+}
+
+// Every returns a Poller that calls factory right away and then every
+// interval. Each promise factory returns must resolve with a single value
+// of the same type as the first, which is what the Poller's stream
+// carries; a promise of another type closes the stream with an error
+// whatever the policy. A panic in factory counts as a failed poll.
+//
+// If the first poll fails without a type, as when factory panics, the
+// stream's type is that of the first poll that resolves, and the type of
+// its consumer is only checked against it then.
+func Every(interval time.Duration, factory func() *Promise, policy ErrorPolicy) *Poller {
+	if interval <= 0 {
+		panic(fmt.Errorf("interval must be positive, got %v", interval))
+	}
+	first := pollWith(factory)
+	p := &Poller{
+		factory:  factory,
+		interval: interval,
+		policy:   policy,
+	}
+	switch {
+	case len(first.resultType) == 1:
+		p.elem = first.resultType[0]
+		p.stream = NewStream(p.elem)
+	case len(first.resultType) == 0 && first.isSettled() && first.err != nil:
+		p.stream = &Stream{}
+	default:
+		panic(fmt.Errorf("factory must return a promise resolving with a single value, got %v", first.resultType))
+	}
+	p.watch(first, currentTime())
+	return p
+}
+
+// Stream returns the stream of the values of p's promises, in the order
+// they were created. It is closed by Stop or, under StopOnError, by the
+// first failure.
+func (p *Poller) Stream() *Stream {
+	return p.stream
+}
+
+// Stop stops polling, cancels the pending poll, if any, and closes the
+// stream once any value being handed to its consumer has been consumed.
+// It may be called from the stream's consumer, and more than once.
+func (p *Poller) Stop() {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	current := p.current
+	p.mu.Unlock()
+	if current != nil {
+		current.Cancel()
+	}
+	spawn(p.stream.Close)
+}
+
+// pollWith calls factory, turning a panic or a nil promise into a
+// rejected promise.
+func pollWith(factory func() *Promise) (poll *Promise) {
+	defer func() {
+		if r := recover(); r != nil {
+			poll = Rejected(panicError(r))
+		}
+	}()
+	poll = factory()
+	if poll == nil {
+		panic(fmt.Errorf("factory returned a nil promise"))
+	}
+	return poll
+}
+
+// watch makes poll, created at start, the current poll, and streams its
+// value once it settles. It cancels poll if p is stopped.
+func (p *Poller) watch(poll *Promise, start time.Time) {
+	poll.observe()
+	p.mu.Lock()
+	stopped := p.stopped
+	if !stopped {
+		p.current = poll
+	}
+	p.mu.Unlock()
+	if stopped {
+		poll.Cancel()
+		return
+	}
+	poll.whenSettled(func() { p.settled(poll, start) })
+}
+
+// settled streams the value of poll and schedules the next one.
+func (p *Poller) settled(poll *Promise, start time.Time) {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.current = nil
+	var fail error
+	var learned reflect.Type
+	switch {
+	case poll.err != nil && p.policy == StopOnError:
+		fail = poll.err
+	case poll.err == nil && p.elem == nil:
+		if len(poll.resultType) != 1 {
+			fail = fmt.Errorf("factory returned a promise resolving with %v, expected a single value", poll.resultType)
+			break
+		}
+		p.elem = poll.resultType[0]
+		learned = p.elem
+	case poll.err == nil && !typesMatch(poll.resultType, []reflect.Type{p.elem}):
+		fail = fmt.Errorf("factory returned a promise resolving with %v, expected %s", poll.resultType, p.elem)
+	}
+	if fail != nil {
+		p.stopped = true
+		p.mu.Unlock()
+		p.stream.CloseWithError(fail)
+		return
+	}
+	p.mu.Unlock()
+	if learned != nil {
+		// The stream's consumer may hold its lock while calling Stop, so
+		// it is only taken once p.mu is released.
+		if err := p.stream.setElem(learned); err != nil {
+			p.mu.Lock()
+			p.stopped = true
+			p.mu.Unlock()
+			p.stream.CloseWithError(err)
+			return
+		}
+	}
+
+	// The value is handed out before the next poll is scheduled, so
+	// values stay in order.
+	if poll.err == nil {
+		p.stream.mu.Lock()
+		if !p.stream.closed {
+			p.stream.emit(poll.results[0])
+		}
+		p.stream.mu.Unlock()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	delay := p.interval - currentTime().Sub(start)
+	if delay < 0 {
+		delay = 0
+	}
+	p.timer = afterFunc(delay, p.tick)
+}
+
+// tick creates the next poll.
+func (p *Poller) tick() {
+	start := currentTime()
+	p.watch(pollWith(p.factory), start)
+}
@@ -0,0 +1,109 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// counter returns a factory of promises resolving with 1, 2, 3..., except
+// for fail, which is rejected.
+func counter(fail int) func() *Promise {
+	var n int32
+	return func() *Promise {
+		i := int(atomic.AddInt32(&n, 1))
+		if i == fail {
+			return Rejected(errors.New("poll failed"), typeOf[int]())
+		}
+		return Resolved(i)
+	}
+}
+
+// untilThree stops poller once it streams 3.
+func untilThree(poller *Poller) *Promise {
+	return poller.Stream().Then(func(i int) int {
+		if i == 3 {
+			poller.Stop()
+		}
+		return i
+	}).All()
+}
+
+func TestEvery(t *testing.T) {
+	poller := Every(time.Millisecond, counter(0), StopOnError)
+	var values []int
+	require.NoError(t, untilThree(poller).Wait(&values))
+	require.Equal(t, []int{1, 2, 3}, values)
+	poller.Stop()
+}
+
+func TestEveryStopOnError(t *testing.T) {
+	poller := Every(time.Millisecond, counter(2), StopOnError)
+	err := untilThree(poller).Wait(new([]int))
+	require.Error(t, err)
+	require.Equal(t, "poll failed", cause(err).Error())
+}
+
+func TestEveryContinueOnError(t *testing.T) {
+	poller := Every(time.Millisecond, counter(2), ContinueOnError)
+	var values []int
+	require.NoError(t, untilThree(poller).Wait(&values))
+	require.Equal(t, []int{1, 3}, values)
+}
+
+func TestEveryWaitsForInterval(t *testing.T) {
+	var polls int32
+	poller := Every(time.Hour, func() *Promise {
+		return Resolved(atomic.AddInt32(&polls, 1))
+	}, StopOnError)
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, int32(1), atomic.LoadInt32(&polls))
+	poller.Stop()
+	var values []int32
+	require.NoError(t, poller.Stream().All().Wait(&values))
+	require.Equal(t, []int32{1}, values)
+}
+
+func TestEveryStopCancelsPendingPoll(t *testing.T) {
+	pending := NewDeferred(typeOf[int]())
+	poller := Every(time.Millisecond, func() *Promise { return pending.Promise }, StopOnError)
+	poller.Stop()
+	require.True(t, errors.Is(pending.Promise.Wait(new(int)), ErrCanceled))
+}
+
+// panicFirst returns a factory that panics on its first call and then
+// returns the promises of next.
+func panicFirst(next func() *Promise) func() *Promise {
+	var calls int32
+	return func() *Promise {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			panic("boom")
+		}
+		return next()
+	}
+}
+
+func TestEveryRecoversFirstPoll(t *testing.T) {
+	poller := Every(time.Millisecond, panicFirst(counter(0)), ContinueOnError)
+	var values []int
+	require.NoError(t, untilThree(poller).Wait(&values))
+	require.Equal(t, []int{1, 2, 3}, values)
+
+	poller = Every(time.Millisecond, panicFirst(counter(0)), StopOnError)
+	err := untilThree(poller).Wait(new([]int))
+	var pe *PanicError
+	require.True(t, errors.As(err, &pe))
+
+	poller = Every(time.Millisecond, panicFirst(func() *Promise { return Resolved("a") }), ContinueOnError)
+	err = untilThree(poller).Wait(new([]int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "stream consumer accepts int")
+
+	poller = Every(time.Hour, panicFirst(counter(0)), ContinueOnError)
+	poller.Stop()
+	require.NoError(t, poller.Stream().All().Wait(&values))
+	require.Empty(t, values)
+}
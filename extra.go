@@ -0,0 +1,79 @@
+package promise
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// extra holds the fields of a promise that only some promises use, such
+// as those set by options, handlers and debug mode, so that Promise stays
+// small. It is allocated by extras on first use.
+type extra struct {
+	// anyErrs and errCounter tally the rejections of the inputs of an Any
+	anyErrs    []error
+	errCounter int64
+	// timeout, if set, rejects the promise with timeoutErr if its function
+	// runs for longer
+	timeout    time.Duration
+	timeoutErr error
+	// executor, if set by WithExecutor, runs the promise's function in
+	// place of the package's scheduler
+	executor Scheduler
+	// noRecover is set by WithNoRecover
+	noRecover bool
+	// cancelCtx cancels the context passed to the promise's function
+	cancelCtx context.CancelFunc
+	// stack holds the program counters of the code that created the
+	// promise, recorded in debug mode
+	stack []uintptr
+	// origin, set in debug mode, is the promise the error the promise
+	// was rejected with came from
+	origin *Promise
+	// serialTail is the last continuation attached with ThenSerial, and
+	// serialPrev the sibling a ThenSerial continuation waits for
+	serialTail *Promise
+	serialPrev *Promise
+	// onRejected is the error handler passed to ThenCatch
+	onRejected reflect.Value
+	// cleanups registered with Defer, run once the promise settles
+	cleanups []func()
+	// checkpoint is when the function last called Checkpoint, in Unix
+	// nanoseconds
+	checkpoint int64
+	// autoCancel is set by WithAutoCancel
+	autoCancel int32
+	// discardAfterWait is set by WithDiscardResultsAfterWait, and
+	// discarded once the results have been released
+	discardAfterWait int32
+	discarded        int32
+	rejection        atomic.Value
+}
+
+// noExtra stands in for the extra fields of promises that have none. It
+// must never be written to.
+var noExtra extra
+
+// extras returns the extra fields of p, allocating them if p has none
+// yet. Use peekExtras to only read them.
+func (p *Promise) extras() *extra {
+	if x := (*extra)(atomic.LoadPointer(&p.extra)); x != nil {
+		return x
+	}
+	x := new(extra)
+	if atomic.CompareAndSwapPointer(&p.extra, nil, unsafe.Pointer(x)) {
+		return x
+	}
+	return (*extra)(atomic.LoadPointer(&p.extra))
+}
+
+// peekExtras returns the extra fields of p for reading, which are those
+// of noExtra if p has none.
+func (p *Promise) peekExtras() *extra {
+	if x := (*extra)(atomic.LoadPointer(&p.extra)); x != nil {
+		return x
+	}
+	return &noExtra
+}
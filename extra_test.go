@@ -0,0 +1,28 @@
+package promise
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPromiseSize guards against fields that only some promises use
+// growing every promise; those belong in extra.
+func TestPromiseSize(t *testing.T) {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		t.Skip("sizes are for 64-bit platforms")
+	}
+	require.LessOrEqual(t, int(reflect.TypeOf(Promise{}).Size()), 448)
+}
+
+func TestExtrasAllocatedOnDemand(t *testing.T) {
+	p := New(func() int { return 1 })
+	require.NoError(t, p.Wait(new(int)))
+	require.True(t, p.peekExtras() == &noExtra, "plain promises have no extra fields")
+
+	p = NewWith(func() int { return 1 }, WithNoRecover())
+	require.NoError(t, p.Wait(new(int)))
+	require.True(t, p.peekExtras().noRecover)
+}
@@ -0,0 +1,103 @@
+package promise
+
+import "reflect"
+
+// A thunk calls a promise's function with args and returns its results,
+// like reflect.Value.Call but without its overhead, which dominates the
+// cost of running cheap functions.
+type thunk func(args []reflect.Value) []reflect.Value
+
+// thunkOf returns a thunk for f if it has one of the common signatures
+// below, or nil if f must be called through reflection.
+func thunkOf(f reflect.Value) thunk {
+	if !f.IsValid() || f.IsNil() {
+		return nil
+	}
+	switch fn := f.Interface().(type) {
+	case func():
+		return func([]reflect.Value) []reflect.Value {
+			fn()
+			return nil
+		}
+	case func() error:
+		return func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{errorValue(fn())}
+		}
+	case func() int:
+		return func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(fn())}
+		}
+	case func() (int, error):
+		return func([]reflect.Value) []reflect.Value {
+			n, err := fn()
+			return []reflect.Value{reflect.ValueOf(n), errorValue(err)}
+		}
+	case func() string:
+		return func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(fn())}
+		}
+	case func() (string, error):
+		return func([]reflect.Value) []reflect.Value {
+			s, err := fn()
+			return []reflect.Value{reflect.ValueOf(s), errorValue(err)}
+		}
+	case func(int) int:
+		return func(args []reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(fn(int(args[0].Int())))}
+		}
+	case func(int) (int, error):
+		return func(args []reflect.Value) []reflect.Value {
+			n, err := fn(int(args[0].Int()))
+			return []reflect.Value{reflect.ValueOf(n), errorValue(err)}
+		}
+	case func(string) (string, error):
+		return func(args []reflect.Value) []reflect.Value {
+			s, err := fn(args[0].String())
+			return []reflect.Value{reflect.ValueOf(s), errorValue(err)}
+		}
+	case func(interface{}) (interface{}, error):
+		return func(args []reflect.Value) []reflect.Value {
+			v, err := fn(args[0].Interface())
+			return []reflect.Value{interfaceValue(v), errorValue(err)}
+		}
+	}
+	return nil
+}
+
+// thunkT returns a thunk for f, as created by NewT.
+func thunkT[T any](f func() (T, error)) thunk {
+	return func([]reflect.Value) []reflect.Value {
+		v, err := f()
+		return []reflect.Value{reflect.ValueOf(&v).Elem(), errorValue(err)}
+	}
+}
+
+// thunkTU returns a thunk for f, as chained by ThenOf.
+func thunkTU[T, U any](f func(T) (U, error)) thunk {
+	return func(args []reflect.Value) []reflect.Value {
+		// A nil interface value doesn't assert to T, but is its zero value.
+		arg, _ := args[0].Interface().(T)
+		v, err := f(arg)
+		return []reflect.Value{reflect.ValueOf(&v).Elem(), errorValue(err)}
+	}
+}
+
+// errorValue returns err as a value of type error, as Call returns it,
+// even if it is nil.
+func errorValue(err error) reflect.Value {
+	return reflect.ValueOf(&err).Elem()
+}
+
+// interfaceValue returns v as a value of type interface{}.
+func interfaceValue(v interface{}) reflect.Value {
+	return reflect.ValueOf(&v).Elem()
+}
+
+// callFunc calls functionRv, the function of p, with args, through p's
+// thunk if it has one.
+func (p *Promise) callFunc(functionRv reflect.Value, args []reflect.Value) []reflect.Value {
+	if p.call != nil {
+		return p.call(args)
+	}
+	return functionRv.Call(args)
+}
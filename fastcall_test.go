@@ -0,0 +1,65 @@
+package promise
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThunkOf(t *testing.T) {
+	require.NotNil(t, thunkOf(reflect.ValueOf(func() {})))
+	require.NotNil(t, thunkOf(reflect.ValueOf(func(n int) (int, error) { return n, nil })))
+	require.Nil(t, thunkOf(reflect.ValueOf(func(a, b int) int { return a + b })))
+	require.Nil(t, thunkOf(reflect.ValueOf(func(ns ...int) int { return len(ns) })))
+}
+
+func TestFastPaths(t *testing.T) {
+	var n int
+	require.NoError(t, New(func() int { return 1 }).Then(func(n int) int { return n + 1 }).Wait(&n))
+	require.Equal(t, 2, n)
+
+	var s string
+	require.NoError(t, New(func() (string, error) { return "a", nil }).Then(func(s string) (string, error) {
+		return s + "b", nil
+	}).Wait(&s))
+	require.Equal(t, "ab", s)
+
+	failure := errors.New("failed")
+	require.Equal(t, failure, cause(New(func() error { return failure }).Wait()))
+	require.NoError(t, New(func() error { return nil }).Wait())
+
+	var v interface{}
+	require.NoError(t, Resolved(interface{}(nil)).Then(func(v interface{}) (interface{}, error) {
+		return v, nil
+	}).Wait(&v))
+	require.Nil(t, v)
+}
+
+func TestTypedFastPaths(t *testing.T) {
+	pt := ThenOf(NewT(func() (error, error) { return nil, nil }), func(err error) (bool, error) {
+		return err == nil, nil
+	})
+	ok, err := pt.Wait()
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func BenchmarkThenFastPath(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var n int
+		if err := New(func() int { return i }).Then(func(n int) (int, error) { return n * 2, nil }).Wait(&n); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkThenReflection(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var n int8
+		if err := New(func() int8 { return int8(i) }).Then(func(n int8) (int8, error) { return n * 2, nil }).Wait(&n); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package promise
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// A FaultRule describes a fault to inject into every promise whose name
+// matches Pattern.
+type FaultRule struct {
+	// Pattern is matched against the promise name. A '*' matches any
+	// sequence of characters, including none.
+	Pattern string
+	// Probability is the chance, between 0 and 1, that the rule fires for
+	// a matching promise.
+	Probability float64
+	// Delay is slept before the promise body runs when the rule fires,
+	// on the clock the promise reads the time from.
+	Delay time.Duration
+	// Err, if non-nil, fails the promise instead of running its body when
+	// the rule fires.
+	Err error
+}
+
+// A FaultInjector delays or fails promises to simulate slow or failing
+// dependencies. Rules are evaluated in order and every rule that fires is
+// applied.
+type FaultInjector struct {
+	Rules []FaultRule
+}
+
+var faultInjector atomic.Value
+
+// SetFaultInjector installs fi as the package-wide fault injector.
+// Passing nil disables fault injection.
+func SetFaultInjector(fi *FaultInjector) {
+	faultInjector.Store(fi)
+}
+
+// injectFault applies the installed fault injector to p, sleeping on p's
+// clock for delays. It rejects p by panicking with the rule's error.
+func injectFault(p *Promise) {
+	fi, _ := faultInjector.Load().(*FaultInjector)
+	if fi == nil {
+		return
+	}
+	name := p.Name()
+	for _, rule := range fi.Rules {
+		if !matchName(rule.Pattern, name) {
+			continue
+		}
+		if randFloat64() >= rule.Probability {
+			continue
+		}
+		if rule.Delay > 0 {
+			sleep(p.clockFor(), rule.Delay)
+		}
+		if rule.Err != nil {
+			panic(rejection{rule.Err})
+		}
+	}
+}
+
+// matchName reports whether name matches pattern, where '*' matches any
+// sequence of characters.
+func matchName(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return strings.HasSuffix(name, last)
+}
+
+// funcName returns the fully qualified name of the function held by
+// functionRv, such as "net/http.Get".
+func funcName(functionRv reflect.Value) string {
+	fn := runtime.FuncForPC(functionRv.Pointer())
+	if fn == nil {
+		return ""
+	}
+	return fn.Name()
+}
@@ -0,0 +1,75 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func injectedFailure() int {
+	return 1
+}
+
+func TestFaultInjectorFailsMatchingPromises(t *testing.T) {
+	injected := errors.New("injected")
+	SetFaultInjector(&FaultInjector{Rules: []FaultRule{{
+		Pattern:     "*.injectedFailure",
+		Probability: 1,
+		Err:         injected,
+	}}})
+	defer SetFaultInjector(nil)
+
+	var result int
+	err := New(injectedFailure).Wait(&result)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "injected")
+
+	err = New(func() int { return 2 }).Wait(&result)
+	require.NoError(t, err)
+	require.Equal(t, 2, result)
+}
+
+func TestFaultInjectorDelaysMatchingPromises(t *testing.T) {
+	SetFaultInjector(&FaultInjector{Rules: []FaultRule{{
+		Pattern:     "*TestFaultInjectorDelaysMatchingPromises*",
+		Probability: 1,
+		Delay:       50 * time.Millisecond,
+	}}})
+	defer SetFaultInjector(nil)
+
+	start := time.Now()
+	err := New(func() {}).Then(func() {}).Wait()
+	require.NoError(t, err)
+	require.True(t, time.Since(start) >= 100*time.Millisecond)
+}
+
+func TestMatchName(t *testing.T) {
+	require.True(t, matchName("net/http.Get", "net/http.Get"))
+	require.True(t, matchName("*.Get", "net/http.Get"))
+	require.True(t, matchName("net/*", "net/http.Get"))
+	require.True(t, matchName("*http*", "net/http.Get"))
+	require.True(t, matchName("*", ""))
+	require.False(t, matchName("*.Post", "net/http.Get"))
+	require.False(t, matchName("net/http.Get", "net/http.GetX"))
+	require.False(t, matchName("*ab*ab", "xab"))
+}
+
+func TestFaultInjectorDelaysOnClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+	SetFaultInjector(&FaultInjector{Rules: []FaultRule{{
+		Pattern:     "*TestFaultInjectorDelaysOnClock*",
+		Probability: 1,
+		Delay:       time.Hour,
+	}}})
+	defer SetFaultInjector(nil)
+
+	p := New(func() {})
+	pollUntil(t, func() bool { return clock.pending() == 1 })
+	require.Equal(t, StatePending, p.State())
+	clock.Advance(time.Hour)
+	require.NoError(t, p.Wait())
+}
@@ -0,0 +1,75 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var boolType = typeOf[bool]()
+
+// Filter calls predicate on every element of slice concurrently and
+// resolves with a slice of the elements it kept, in their original order.
+// The first predicate to fail rejects the returned promise.
+//
+// predicate accepts an element and returns either a bool, optionally
+// followed by an error, or a *Promise resolving with a bool for checks
+// that are themselves asynchronous, such as probing an endpoint.
+func Filter(slice interface{}, predicate interface{}) *Promise {
+	sliceRv := reflect.ValueOf(slice)
+	if sliceRv.Kind() != reflect.Slice {
+		panic(fmt.Errorf("expected a slice, got %v", sliceRv.Kind()))
+	}
+	predicateRv := reflect.ValueOf(predicate)
+	if predicateRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", predicateRv.Kind()))
+	}
+	resultType, _ := getResultType(predicateRv.Type())
+	async := len(resultType) == 1 && resultType[0] == promisePtrType
+	if !async && (len(resultType) != 1 || resultType[0] != boolType) {
+		panic(fmt.Errorf("predicate must return a bool or a *Promise, got %s", predicateRv.Type()))
+	}
+
+	elemType := sliceRv.Type().Elem()
+	keeps := make([]*Promise, sliceRv.Len())
+	for i := range keeps {
+		keeps[i] = resolvedAs(elemType, sliceRv.Index(i).Interface()).Then(predicate)
+		if async {
+			keeps[i] = expectTypes(keeps[i], boolType)
+		}
+	}
+	sliceType := sliceRv.Type()
+	collect := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{reflect.SliceOf(boolType)}, []reflect.Type{sliceType}, true),
+		func(args []reflect.Value) []reflect.Value {
+			kept := reflect.MakeSlice(sliceType, 0, args[0].Len())
+			for i := 0; i < args[0].Len(); i++ {
+				if args[0].Index(i).Bool() {
+					kept = reflect.Append(kept, sliceRv.Index(i))
+				}
+			}
+			return []reflect.Value{kept}
+		})
+	return All(keeps...).Then(collect.Interface())
+}
+
+// expectTypes returns a promise that settles like p, a Then that flattened
+// the *Promise its function returned, but is rejected if p resolves with
+// values of other types than types.
+func expectTypes(p *Promise, types ...reflect.Type) *Promise {
+	d := NewDeferred(types...)
+	p.observe()
+	p.graph.addChild(p, d.Promise)
+	d.watchContext(p.ctx)
+	p.whenSettled(func() {
+		if p.err != nil {
+			d.Reject(p.err)
+			return
+		}
+		if !typesMatch(p.resultType, types) {
+			d.Reject(fmt.Errorf("promise returned by function resolves with %v, expected %v", p.resultType, types))
+			return
+		}
+		d.settle(p.results, nil)
+	})
+	return d.Promise
+}
@@ -0,0 +1,61 @@
+package promise
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	var kept []int
+	err := Filter([]int{1, 2, 3, 4, 5, 6}, func(i int) bool {
+		time.Sleep(time.Duration(6-i) * time.Millisecond)
+		return i%2 == 0
+	}).Wait(&kept)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 4, 6}, kept)
+}
+
+func TestFilterAsyncPredicate(t *testing.T) {
+	probe := func(endpoint string) *Promise {
+		return New(func() (bool, error) {
+			if endpoint == "down" {
+				return false, nil
+			}
+			return strings.HasPrefix(endpoint, "http"), nil
+		})
+	}
+	var healthy []string
+	err := Filter([]string{"http://a", "down", "ftp://b", "http://c"}, probe).Wait(&healthy)
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://a", "http://c"}, healthy)
+}
+
+func TestFilterRejects(t *testing.T) {
+	err := Filter([]int{1, 2}, func(i int) (bool, error) {
+		if i == 2 {
+			return false, errors.New("probe failed")
+		}
+		return true, nil
+	}).Wait(new([]int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "probe failed")
+
+	err = Filter([]int{1}, func(i int) *Promise { return Resolved("yes") }).Wait(new([]int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected [bool]")
+}
+
+func TestFilterEmpty(t *testing.T) {
+	var kept []int
+	require.NoError(t, Filter([]int{}, func(int) bool { return true }).Wait(&kept))
+	require.Empty(t, kept)
+}
+
+func TestFilterChecksPredicate(t *testing.T) {
+	require.Panics(t, func() { Filter(1, func(int) bool { return true }) })
+	require.Panics(t, func() { Filter([]int{}, func(int) int { return 0 }) })
+}
@@ -0,0 +1,35 @@
+package promise
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Finally returns a promise that calls f once p settles, whether it
+// resolved or was rejected, and then settles the same way as p. It is
+// meant for releasing resources, such as closing a response body, at the
+// end of a chain. f runs even if p is canceled or its context is done. If
+// f panics, the returned promise is rejected instead.
+func (p *Promise) Finally(f func()) *Promise {
+	p.checkCopy()
+	p.observe()
+	if f == nil {
+		panic(errors.New("expected Function, got nil"))
+	}
+	next := newPooledPromise(finallyCall, "")
+	functionRv := reflect.ValueOf(f)
+	next.name = funcName(functionRv)
+	next.resultType = p.resultType
+	next.sliceType = p.sliceType
+	p.chain(next, functionRv)
+	return next
+}
+
+func (p *Promise) finallyCall(prior *Promise, functionRv reflect.Value) {
+	prior.await()
+	p.markStarted()
+	injectFault(p)
+	functionRv.Call(nil)
+	p.slice = prior.slice
+	p.settle(prior.results, prior.err)
+}
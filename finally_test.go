@@ -0,0 +1,74 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinallyPassesThroughResults(t *testing.T) {
+	released := false
+	p := New(func() (int, string) {
+		return 1, "garlic"
+	}).Finally(func() {
+		released = true
+	})
+	var n int
+	var s string
+	require.NoError(t, p.Wait(&n, &s))
+	require.True(t, released)
+	require.Equal(t, 1, n)
+	require.Equal(t, "garlic", s)
+}
+
+func TestFinallyPassesThroughError(t *testing.T) {
+	released := false
+	p := New(func() error {
+		return errors.New("failed")
+	}).Finally(func() {
+		released = true
+	})
+	err := p.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed")
+	require.True(t, released)
+}
+
+func TestFinallyPanicRejects(t *testing.T) {
+	p := New(func() int {
+		return 1
+	}).Finally(func() {
+		panic("cleanup failed")
+	})
+	err := p.Wait(new(int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cleanup failed")
+}
+
+func TestFinallyRunsWhenCanceled(t *testing.T) {
+	var released int32
+	d := NewDeferred(typeOf[int]())
+	p := d.Promise.Finally(func() { atomic.StoreInt32(&released, 1) })
+	after := p.Then(func(int) {})
+	d.Cancel()
+	require.Equal(t, ErrCanceled, cause(p.Wait(new(int))))
+	require.Equal(t, int32(1), atomic.LoadInt32(&released))
+	require.Equal(t, ErrCanceled, cause(after.Wait()))
+}
+
+func TestFinallyRunsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	var released int32
+	p := NewCtx(ctx, func(ctx context.Context) int {
+		<-release
+		return 1
+	}).Finally(func() { atomic.StoreInt32(&released, 1) })
+	cancel()
+	require.Equal(t, context.Canceled, cause(p.Wait(new(int))))
+	require.Equal(t, int32(1), atomic.LoadInt32(&released))
+}
@@ -0,0 +1,52 @@
+package promise
+
+import (
+	"errors"
+	"reflect"
+)
+
+// typedPromise is implemented by *PromiseT.
+type typedPromise interface {
+	Promise() *Promise
+	valueType() reflect.Type
+}
+
+var typedPromiseType = typeOf[typedPromise]()
+
+// flattenType reports whether a Then function with results resultType
+// returns a promise for the Then to flatten, and the result types of the
+// Then if so. They are nil, and dynamic true, for an untyped *Promise,
+// whose types are only known once it has been returned.
+func flattenType(resultType []reflect.Type) (flatten bool, types []reflect.Type, dynamic bool) {
+	if len(resultType) != 1 {
+		return false, resultType, false
+	}
+	switch t := resultType[0]; {
+	case t == promisePtrType:
+		return true, nil, true
+	case t.Implements(typedPromiseType):
+		return true, []reflect.Type{reflect.Zero(t).Interface().(typedPromise).valueType()}, false
+	}
+	return false, resultType, false
+}
+
+// adopt settles p like promise, the *Promise or *PromiseT its function
+// returned, once promise settles.
+func (p *Promise) adopt(promise reflect.Value) {
+	if promise.IsNil() {
+		panic(rejection{errors.New("function returned a nil promise")})
+	}
+	inner, ok := promise.Interface().(*Promise)
+	if !ok {
+		inner = promise.Interface().(typedPromise).Promise()
+	}
+	inner.observe()
+	inner.whenSettled(func() {
+		if p.dynamic {
+			p.mu.Lock()
+			p.resultType = inner.resultType
+			p.mu.Unlock()
+		}
+		p.settle(inner.results, inner.err)
+	})
+}
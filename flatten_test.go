@@ -0,0 +1,71 @@
+package promise
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThenFlattensPromise(t *testing.T) {
+	p := Resolved(2).Then(func(n int) *Promise {
+		return New(func() (int, string) {
+			return n * 3, "entries"
+		})
+	})
+	var n int
+	var s string
+	require.NoError(t, p.Wait(&n, &s))
+	require.Equal(t, 6, n)
+	require.Equal(t, "entries", s)
+}
+
+func TestThenFlattensTypedPromise(t *testing.T) {
+	p := Resolved("index").Then(func(index string) *PromiseT[int] {
+		return NewT(func() (int, error) {
+			return len(index), nil
+		})
+	})
+	require.Equal(t, []reflect.Type{typeOf[int]()}, p.resultType)
+	n, err := Typed[int](p).Wait()
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+}
+
+func TestThenFlattensRejection(t *testing.T) {
+	inner := errors.New("inner")
+	p := Resolved(1).Then(func(int) *Promise {
+		return Rejected(inner)
+	})
+	require.Equal(t, inner, cause(p.Wait()))
+}
+
+func TestThenNilPromiseRejects(t *testing.T) {
+	p := Resolved(1).Then(func(int) *Promise {
+		return nil
+	})
+	require.Contains(t, p.Wait().Error(), "function returned a nil promise")
+}
+
+func TestThenOnFlattenedPromise(t *testing.T) {
+	p := Resolved(1).Then(func(n int) *Promise {
+		return Resolved(n + 1)
+	})
+	var n int
+	require.NoError(t, p.Then(func(n int) int { return n * 10 }).Wait(&n))
+	require.Equal(t, 20, n)
+
+	mismatch := p.Then(func(s string) string { return s })
+	require.Error(t, mismatch.Wait())
+}
+
+func TestWaitFlattenedPromiseTypeMismatch(t *testing.T) {
+	p := Resolved(1).Then(func(n int) *Promise {
+		return Resolved("one")
+	})
+	var n int
+	require.Panics(t, func() {
+		_ = p.Wait(&n)
+	})
+}
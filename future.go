@@ -0,0 +1,59 @@
+package promise
+
+import "fmt"
+
+// A Future is the smallest interface common to promise and future
+// packages: a value, or an error, that can be awaited. Adapting to it lets
+// code using another package, such as the promises of chebyrash/promise
+// v1, exchange results with this one while migrating.
+type Future interface {
+	Await() (interface{}, error)
+}
+
+// FromFuture returns a promise that resolves with the value f resolves
+// with, as an interface{}.
+func FromFuture(f Future) *Promise {
+	return New(f.Await)
+}
+
+// FromFutureT is like FromFuture, but rejects the promise unless the value
+// is a T.
+func FromFutureT[T any](f Future) *PromiseT[T] {
+	return NewT(func() (T, error) {
+		var zero T
+		value, err := f.Await()
+		if err != nil {
+			return zero, err
+		}
+		if value == nil {
+			return zero, nil
+		}
+		typed, ok := value.(T)
+		if !ok {
+			return zero, fmt.Errorf("future resolved with %T, expected %s", value, typeOf[T]())
+		}
+		return typed, nil
+	})
+}
+
+// Future returns p as a Future. p must resolve with at most one value;
+// the Future of a promise without results resolves with nil.
+func (p *Promise) Future() Future {
+	p.checkCopy()
+	if len(p.resultType) > 1 {
+		panic(fmt.Errorf("promise returns %d values, a Future holds one", len(p.resultType)))
+	}
+	return promiseFuture{p}
+}
+
+type promiseFuture struct {
+	p *Promise
+}
+
+func (f promiseFuture) Await() (interface{}, error) {
+	values, err := f.p.Result()
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	return values[0], nil
+}
@@ -0,0 +1,47 @@
+package promise
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// otherFuture stands in for a future from another package.
+type otherFuture struct {
+	value interface{}
+	err   error
+}
+
+func (f otherFuture) Await() (interface{}, error) {
+	return f.value, f.err
+}
+
+func TestFromFuture(t *testing.T) {
+	var result interface{}
+	require.NoError(t, FromFuture(otherFuture{value: 1}).Wait(&result))
+	require.Equal(t, 1, result)
+
+	n, err := FromFutureT[int](otherFuture{value: 2}).Wait()
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	_, err = FromFutureT[string](otherFuture{value: 2}).Wait()
+	require.Error(t, err)
+
+	failure := fmt.Errorf("failure")
+	_, err = FromFutureT[int](otherFuture{err: failure}).Wait()
+	require.Equal(t, failure, cause(err))
+}
+
+func TestPromiseFuture(t *testing.T) {
+	value, err := Resolved("one").Future().Await()
+	require.NoError(t, err)
+	require.Equal(t, "one", value)
+
+	value, err = New(func() {}).Future().Await()
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	require.Panics(t, func() { Resolved(1, 2).Future() })
+}
@@ -0,0 +1,97 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// typeOf returns the reflect.Type of the type parameter T, which works
+// for interface types as well as concrete ones.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// ThenT is a typed variant of Then for promises that resolve with a single
+// value. The signature of f is checked at compile time, and p's result type
+// is checked against I when ThenT is called. The chained promise resolves
+// with the O returned by f.
+func ThenT[I, O any](p *Promise, f func(I) (O, error)) *Promise {
+	in := typeOf[I]()
+	if len(p.resultType) != 1 || !p.resultType[0].AssignableTo(in) {
+		panic(fmt.Errorf("promise returns %v, but provided function accepts %s", p.resultType, in))
+	}
+	return p.Then(f)
+}
+
+// JoinInto waits for p and returns its results as the fields of a T. T must
+// be a struct with one exported field per result of p, in result order, so
+// wide All fan-ins can be read as a single typed value.
+func JoinInto[T any](p *Promise) (T, error) {
+	var joined T
+	rv := reflect.ValueOf(&joined).Elem()
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Errorf("JoinInto: expected struct type, got %s", rv.Type()))
+	}
+	if rv.NumField() != len(p.resultType) {
+		panic(fmt.Errorf("Promise returns %d values, %s has %d fields", len(p.resultType), rv.Type(), rv.NumField()))
+	}
+	out := make([]interface{}, rv.NumField())
+	for i := range out {
+		field := rv.Field(i)
+		if !field.CanSet() {
+			panic(fmt.Errorf("JoinInto: field %s of %s is not exported", rv.Type().Field(i).Name, rv.Type()))
+		}
+		out[i] = field.Addr().Interface()
+	}
+	err := p.Wait(out...)
+	return joined, err
+}
+
+// Await waits for p, which must resolve with a single value assignable to
+// T, and returns that value. A mismatch is returned as an error rather than
+// panicking, as is p's error.
+func Await[T any](p *Promise) (T, error) {
+	var a T
+	if err := checkAwait("Await", p, typeOf[T]()); err != nil {
+		return a, err
+	}
+	err := p.Wait(&a)
+	return a, err
+}
+
+// Await2 is like Await for a promise that resolves with two values.
+func Await2[A, B any](p *Promise) (A, B, error) {
+	var a A
+	var b B
+	if err := checkAwait("Await2", p, typeOf[A](), typeOf[B]()); err != nil {
+		return a, b, err
+	}
+	err := p.Wait(&a, &b)
+	return a, b, err
+}
+
+// Await3 is like Await for a promise that resolves with three values.
+func Await3[A, B, C any](p *Promise) (A, B, C, error) {
+	var a A
+	var b B
+	var c C
+	if err := checkAwait("Await3", p, typeOf[A](), typeOf[B](), typeOf[C]()); err != nil {
+		return a, b, c, err
+	}
+	err := p.Wait(&a, &b, &c)
+	return a, b, c, err
+}
+
+// checkAwait returns an error naming fn if p's results can't be delivered
+// to values of types.
+func checkAwait(fn string, p *Promise, types ...reflect.Type) error {
+	p.checkCopy()
+	ok := len(p.resultType) == len(types)
+	for i := 0; ok && i < len(types); i++ {
+		ok = p.accepts(p.resultType[i], types[i])
+	}
+	if !ok {
+		return fmt.Errorf("%s: promise returns %v, expected %v", fn, p.resultType, types)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThenT(t *testing.T) {
+	p := New(func() int {
+		return 21
+	})
+	doubled := ThenT(p, func(x int) (string, error) {
+		return strconv.Itoa(x * 2), nil
+	})
+	var result string
+	err := doubled.Wait(&result)
+	require.NoError(t, err)
+	require.Equal(t, "42", result)
+}
+
+func TestThenTPanicsOnMismatchedPromise(t *testing.T) {
+	p := New(func() string {
+		return "garlic"
+	})
+	require.Panics(t, func() {
+		ThenT(p, func(x int) (int, error) {
+			return x, nil
+		})
+	})
+}
+
+func TestJoinInto(t *testing.T) {
+	type joined struct {
+		Count int
+		Name  string
+		Ok    bool
+	}
+	all := All(
+		New(func() int { return 3 }),
+		New(func() string { return "garlic" }),
+		New(func() bool { return true }),
+	)
+	result, err := JoinInto[joined](all)
+	require.NoError(t, err)
+	require.Equal(t, joined{Count: 3, Name: "garlic", Ok: true}, result)
+}
+
+func TestJoinIntoPanicsOnFieldMismatch(t *testing.T) {
+	type joined struct {
+		Count int
+		Name  int
+	}
+	all := All(
+		New(func() int { return 3 }),
+		New(func() string { return "garlic" }),
+	)
+	require.Panics(t, func() {
+		_, _ = JoinInto[joined](all)
+	})
+}
+
+func TestAwait(t *testing.T) {
+	n, err := Await[int](Resolved(1))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	s, ok, err := Await2[string, bool](Resolved("a", true))
+	require.NoError(t, err)
+	require.Equal(t, "a", s)
+	require.True(t, ok)
+
+	x, y, z, err := Await3[int, fmt.Stringer, interface{}](Resolved(1, time.Second, nil))
+	require.NoError(t, err)
+	require.Equal(t, 1, x)
+	require.Equal(t, "1s", y.String())
+	require.Nil(t, z)
+
+	_, err = Await[string](Resolved(1))
+	require.EqualError(t, err, "Await: promise returns [int], expected [string]")
+	_, _, err = Await2[int, int](Resolved(1))
+	require.EqualError(t, err, "Await2: promise returns [int], expected [int int]")
+
+	_, err = Await[int](Rejected(errors.New("failed"), typeOf[int]()))
+	require.EqualError(t, cause(err), "failed")
+}
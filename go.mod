@@ -1,8 +1,13 @@
 module github.com/garlicnation/promises/v2
 
-go 1.12
+go 1.18
 
 require (
-	github.com/pkg/errors v0.8.1
 	github.com/stretchr/testify v1.4.0
+	gopkg.in/yaml.v2 v2.2.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 )
@@ -0,0 +1,27 @@
+package promise
+
+import "sync/atomic"
+
+// inFlight counts the goroutines started by spawn that are still running.
+var inFlight int64
+
+// spawn runs f on a new goroutine, counted by InFlight until f returns.
+// Every goroutine the package starts, other than the workers of a Pool,
+// goes through spawn.
+func spawn(f func()) {
+	atomic.AddInt64(&inFlight, 1)
+	go func() {
+		defer atomic.AddInt64(&inFlight, -1)
+		f()
+	}()
+}
+
+// InFlight returns the number of goroutines the package started that are
+// still running: those running promise functions under the default
+// scheduler, and those waiting on contexts, channels, rate limiters and
+// other promises on behalf of a promise. The workers of a Pool, which
+// live as long as the Pool, aren't counted. A count that doesn't drop
+// back once every promise has settled points at a leak.
+func InFlight() int {
+	return int(atomic.LoadInt64(&inFlight))
+}
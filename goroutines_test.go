@@ -0,0 +1,18 @@
+package promise
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlight(t *testing.T) {
+	block := make(chan struct{})
+	p := New(func() { <-block })
+	// Goroutines left by other tests may still be running.
+	during := InFlight()
+	require.GreaterOrEqual(t, during, 1)
+	close(block)
+	require.NoError(t, p.Wait())
+	pollUntil(t, func() bool { return InFlight() < during })
+}
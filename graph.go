@@ -0,0 +1,134 @@
+package promise
+
+import "sync"
+
+// A Graph is a set of promises connected by Then and the combinators. New
+// starts a graph, promises chained from a member join it, and a combinator
+// joins the graph of its first input.
+type Graph struct {
+	root *Promise
+
+	mu      sync.Mutex
+	pending int
+	hooks   []GraphHooks
+	// events are delivered to hooks in order by whichever goroutine finds
+	// delivering false.
+	events     []func(GraphHooks)
+	delivering bool
+	// transform is set by SetResultTransform
+	transform func(p *Promise, results []interface{}) []interface{}
+	// clock, if set by SetClock, replaces the package's clock for members
+	clock Clock
+}
+
+// GraphHooks are called as a graph changes. Calls to the hooks of a graph
+// are made one at a time, in the order the events happened.
+type GraphHooks struct {
+	// OnChildAdded is called when child is chained from parent.
+	OnChildAdded func(parent, child *Promise)
+	// OnGraphSettled is called whenever every promise in the graph has
+	// settled. Chaining from a settled graph makes it pending again.
+	OnGraphSettled func(g *Graph)
+}
+
+func newGraph(root *Promise) *Graph {
+	g := &Graph{root: root, pending: 1}
+	root.graph = g
+	return g
+}
+
+// Graph returns the graph p belongs to.
+func (p *Promise) Graph() *Graph {
+	return p.graph
+}
+
+// Root returns the promise that started g.
+func (g *Graph) Root() *Promise {
+	return g.root
+}
+
+// AddHooks registers hooks for events that happen in g from now on.
+func (g *Graph) AddHooks(hooks GraphHooks) {
+	g.mu.Lock()
+	g.hooks = append(g.hooks, hooks)
+	g.mu.Unlock()
+}
+
+// addChild records that child is chained from parent, a member of g. The
+// child joins g unless it already belongs to a graph.
+func (g *Graph) addChild(parent, child *Promise) {
+	child.mu.Lock()
+	first := len(child.parents) == 0
+	child.parents = append(child.parents, parent)
+	child.mu.Unlock()
+	child.inheritPriority(parent, first)
+	// The child keeps its parent from being recycled, and a pending parent
+	// the child, until the parent's continuations are done with it.
+	parent.acquire()
+	parent.mu.Lock()
+	parent.children = append(parent.children, child)
+	if parent.signal.Pending() {
+		child.acquire()
+	}
+	parent.mu.Unlock()
+	g.mu.Lock()
+	if child.graph == nil {
+		child.graph = g
+		g.pending++
+		if g.clock != nil {
+			// The child was created just now, before it knew its clock.
+			created := g.clock.Now()
+			child.mu.Lock()
+			child.created = created
+			child.mu.Unlock()
+		}
+	}
+	g.enqueue(func(h GraphHooks) {
+		if h.OnChildAdded != nil {
+			h.OnChildAdded(parent, child)
+		}
+	})
+}
+
+// nodeSettled records that a member of g settled.
+func (g *Graph) nodeSettled() {
+	g.mu.Lock()
+	g.pending--
+	if g.pending != 0 {
+		g.mu.Unlock()
+		return
+	}
+	g.enqueue(func(h GraphHooks) {
+		if h.OnGraphSettled != nil {
+			h.OnGraphSettled(g)
+		}
+	})
+}
+
+// enqueue queues an event for delivery to the hooks and delivers pending
+// events unless another goroutine already is. It must be called with g.mu
+// held, and releases it.
+func (g *Graph) enqueue(event func(GraphHooks)) {
+	if len(g.hooks) == 0 {
+		g.mu.Unlock()
+		return
+	}
+	g.events = append(g.events, event)
+	if g.delivering {
+		g.mu.Unlock()
+		return
+	}
+	g.delivering = true
+	for len(g.events) > 0 {
+		event := g.events[0]
+		g.events = g.events[1:]
+		hooks := g.hooks
+		g.mu.Unlock()
+		for _, h := range hooks {
+			event(h)
+		}
+		g.mu.Lock()
+	}
+	g.delivering = false
+	g.mu.Unlock()
+}
@@ -0,0 +1,58 @@
+package promise
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphHooks(t *testing.T) {
+	release := make(chan struct{})
+	root := New(func() int {
+		<-release
+		return 1
+	})
+	g := root.Graph()
+	require.Equal(t, root, g.Root())
+
+	var mu sync.Mutex
+	var edges [][2]*Promise
+	settled := make(chan *Graph, 1)
+	g.AddHooks(GraphHooks{
+		OnChildAdded: func(parent, child *Promise) {
+			mu.Lock()
+			edges = append(edges, [2]*Promise{parent, child})
+			mu.Unlock()
+		},
+		OnGraphSettled: func(g *Graph) {
+			settled <- g
+		},
+	})
+
+	double := root.Then(func(x int) int { return x * 2 })
+	triple := root.Then(func(x int) int { return x * 3 })
+	all := All(double, triple)
+	require.Equal(t, g, double.Graph())
+	require.Equal(t, g, all.Graph())
+
+	close(release)
+	require.Equal(t, g, <-settled)
+	require.NoError(t, all.Wait(new(int), new(int)))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, [][2]*Promise{
+		{root, double},
+		{root, triple},
+		{double, all},
+		{triple, all},
+	}, edges)
+}
+
+func TestSeparateNewCallsStartSeparateGraphs(t *testing.T) {
+	a := New(func() {})
+	b := New(func() {})
+	require.True(t, a.Graph() != b.Graph())
+	require.True(t, a.Graph() == All(a, b).Graph())
+}
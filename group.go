@@ -0,0 +1,147 @@
+package promise
+
+import "sync"
+
+// A Group collects promises started one at a time, for fan-out loops
+// that discover their work as they go, where All needs every promise up
+// front:
+//
+//	var g Group
+//	g.SetLimit(8)
+//	for _, url := range urls {
+//		g.Go(fetch, url)
+//	}
+//	var pages []Page
+//	err := g.Wait(&pages)
+//
+// The zero Group is ready to use, has no limit, and must not be copied
+// after first use.
+type Group struct {
+	mu       sync.Mutex
+	limit    int
+	running  int
+	queued   []*groupTask
+	promises []*Promise
+	settled  int
+	// idle is closed once every promise has settled, for Wait
+	idle chan struct{}
+	err  error
+}
+
+// A groupTask is a promise of a Group and the function starting it.
+type groupTask struct {
+	p       *Promise
+	start   func()
+	started bool
+}
+
+// SetLimit limits the number of functions started by Go that run at once
+// to n; a call of Go beyond it returns a pending promise whose function
+// starts once another one finishes. A limit of zero or less removes the
+// limit.
+func (g *Group) SetLimit(n int) {
+	g.mu.Lock()
+	g.limit = n
+	starts := g.dequeue()
+	g.mu.Unlock()
+	for _, start := range starts {
+		start()
+	}
+}
+
+// Go returns a promise that calls f with args, like New, and adds it to g.
+func (g *Group) Go(f interface{}, args ...interface{}) *Promise {
+	p, start := newCall(f, nil, args)
+	g.add(p, start)
+	return p
+}
+
+// add adds p to g, and calls start once the limit allows. A nil start
+// means p is running already, regardless of the limit.
+func (g *Group) add(p *Promise, start func()) {
+	p.observe()
+	task := &groupTask{p: p, start: start}
+	g.mu.Lock()
+	g.promises = append(g.promises, p)
+	var starts []func()
+	if start == nil {
+		task.started = true
+		g.running++
+	} else {
+		g.queued = append(g.queued, task)
+		starts = g.dequeue()
+	}
+	g.mu.Unlock()
+	p.whenSettled(func() { g.finished(task) })
+	for _, start := range starts {
+		start()
+	}
+}
+
+// dequeue marks as running, and returns, the queued starts the limit
+// leaves room for. g.mu must be held.
+func (g *Group) dequeue() []func() {
+	var starts []func()
+	for len(g.queued) > 0 && (g.limit <= 0 || g.running < g.limit) {
+		task := g.queued[0]
+		g.queued[0] = nil
+		g.queued = g.queued[1:]
+		if task.p.isSettled() {
+			// Canceled before it started.
+			continue
+		}
+		task.started = true
+		g.running++
+		starts = append(starts, task.start)
+	}
+	return starts
+}
+
+// finished records the outcome of one of g's promises, and starts the next
+// queued one.
+func (g *Group) finished(task *groupTask) {
+	g.mu.Lock()
+	if task.started {
+		g.running--
+	}
+	g.settled++
+	if err := task.p.err; err != nil && g.err == nil {
+		g.err = err
+	}
+	if g.settled == len(g.promises) && g.idle != nil {
+		close(g.idle)
+		g.idle = nil
+	}
+	starts := g.dequeue()
+	g.mu.Unlock()
+	for _, start := range starts {
+		start()
+	}
+}
+
+// Wait blocks until every promise added to g has settled, including those
+// added while it waits, and returns the error of the first to be rejected.
+// If none was, it fills out with their results as All(promises...).Wait
+// would, in the order they were added, so a group of functions that each
+// return one T can fill a *[]T. out is left alone if g has no promises.
+func (g *Group) Wait(out ...interface{}) error {
+	g.mu.Lock()
+	for g.settled < len(g.promises) {
+		if g.idle == nil {
+			g.idle = make(chan struct{})
+		}
+		idle := g.idle
+		g.mu.Unlock()
+		<-idle
+		g.mu.Lock()
+	}
+	promises, err := g.promises, g.err
+	g.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(promises) == 0 || len(out) == 0 {
+		return nil
+	}
+	return All(promises...).Wait(out...)
+}
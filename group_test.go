@@ -0,0 +1,66 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	var g Group
+	var crawl func(depth int) int
+	crawl = func(depth int) int {
+		if depth < 3 {
+			// Work found along the way joins the group.
+			g.Go(crawl, depth+1)
+			g.Go(crawl, depth+1)
+		}
+		return depth
+	}
+	g.Go(crawl, 0)
+	var depths []int
+	require.NoError(t, g.Wait(&depths))
+	require.Len(t, depths, 15)
+	require.Equal(t, 0, depths[0])
+}
+
+func TestGroupError(t *testing.T) {
+	var g Group
+	g.Go(func() int { return 1 })
+	g.Go(func() (int, error) { return 0, errors.New("failed") })
+	var values []int
+	require.EqualError(t, g.Wait(&values), "failed")
+	require.Nil(t, values)
+}
+
+func TestGroupLimit(t *testing.T) {
+	var g Group
+	g.SetLimit(2)
+	release := NewDeferred()
+	started := make(chan int, 6)
+	for i := 0; i < 6; i++ {
+		g.Go(func(i int) int {
+			started <- i
+			release.Wait()
+			return i
+		}, i)
+	}
+	<-started
+	<-started
+	select {
+	case i := <-started:
+		t.Fatalf("function %d started beyond the limit", i)
+	case <-time.After(10 * time.Millisecond):
+	}
+	release.Resolve()
+	var values []int
+	require.NoError(t, g.Wait(&values))
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5}, values)
+}
+
+func TestGroupEmpty(t *testing.T) {
+	var g Group
+	require.NoError(t, g.Wait())
+}
@@ -0,0 +1,73 @@
+package promise
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ThenDirect is like Then, but f runs on the goroutine that settles p,
+// or the one calling ThenDirect if p has already settled, instead of
+// being scheduled. That saves the cost of scheduling for cheap
+// transforms in deep chains, but f holds up everything else waiting on p
+// while it runs, so it must be quick and must not block.
+func (p *Promise) ThenDirect(f interface{}) *Promise {
+	return p.then(f, func(next *Promise) {
+		next.direct = true
+	})
+}
+
+// directThreshold is the cost, in nanoseconds, under which Then functions
+// are handed off directly. Zero disables the heuristic.
+var directThreshold int64
+
+// costs maps the code pointer of every Then function measured to its
+// average cost in nanoseconds, as an *int64.
+var costs sync.Map
+
+// SetDirectHandoff makes Then continuations whose functions have so far
+// run in less than threshold on average behave as if chained with
+// ThenDirect. A threshold of zero, the default, turns the heuristic off.
+// Functions are told apart by their code, so every closure created by
+// the same function literal shares a cost. Each call forgets the costs
+// measured so far, so they are measured afresh against the new threshold.
+func SetDirectHandoff(threshold time.Duration) {
+	atomic.StoreInt64(&directThreshold, int64(threshold))
+	costs.Range(func(key, _ interface{}) bool {
+		costs.Delete(key)
+		return true
+	})
+}
+
+// isCheap reports whether functionRv is cheap enough to be handed off
+// directly.
+func isCheap(functionRv reflect.Value) bool {
+	threshold := atomic.LoadInt64(&directThreshold)
+	if threshold == 0 || !functionRv.IsValid() {
+		return false
+	}
+	cost, ok := costs.Load(functionRv.Pointer())
+	return ok && atomic.LoadInt64(cost.(*int64)) < threshold
+}
+
+// measureCost starts timing a call of functionRv for the direct handoff
+// heuristic and returns a function that records it.
+func measureCost(functionRv reflect.Value) (done func()) {
+	if atomic.LoadInt64(&directThreshold) == 0 {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := int64(time.Since(start))
+		cost, loaded := costs.LoadOrStore(functionRv.Pointer(), &elapsed)
+		if !loaded {
+			return
+		}
+		// Keep an exponentially weighted moving average, so the cost
+		// follows changes in the function's behavior.
+		average := cost.(*int64)
+		old := atomic.LoadInt64(average)
+		atomic.StoreInt64(average, old+(elapsed-old)/8)
+	}
+}
@@ -0,0 +1,45 @@
+package promise
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThenDirect(t *testing.T) {
+	release := make(chan struct{})
+	parent := make(chan int64, 1)
+	p := New(func() int {
+		<-release
+		parent <- goroutineID()
+		return 1
+	})
+	child := make(chan int64, 1)
+	next := p.ThenDirect(func(i int) int {
+		child <- goroutineID()
+		return i + 1
+	})
+	close(release)
+	var result int
+	require.NoError(t, next.Wait(&result))
+	require.Equal(t, 2, result)
+	require.Equal(t, <-parent, <-child, "the continuation runs on the goroutine settling its prior")
+}
+
+func TestSetDirectHandoff(t *testing.T) {
+	SetDirectHandoff(time.Second)
+	defer SetDirectHandoff(0)
+
+	ids := make(chan int64, 2)
+	cheap := func(i int) int {
+		ids <- goroutineID()
+		return i
+	}
+	// The first call is scheduled, and measures the function's cost.
+	require.NoError(t, Resolved(1).Then(cheap).Wait(new(int)))
+	require.NotEqual(t, goroutineID(), <-ids)
+
+	require.NoError(t, Resolved(1).Then(cheap).Wait(new(int)))
+	require.Equal(t, goroutineID(), <-ids, "a cheap continuation of a settled promise runs right away")
+}
@@ -0,0 +1,148 @@
+package promise
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HedgeConfig configures the attempts of Hedge.
+type HedgeConfig struct {
+	// Attempts is the most times f is called, counting the first call.
+	Attempts int
+	// Delay is how long Hedge waits, on the package's Clock, for the
+	// latest attempt before starting another one. With no delay, every
+	// attempt starts straight away.
+	Delay time.Duration
+	// Budget, if not nil, must hold a token for every attempt after the
+	// first. Once it runs out, Hedge waits on the attempts in flight.
+	Budget *Budget
+}
+
+// Hedge returns a promise for New(f, args...) that calls f again whenever
+// config.Delay passes, or the attempts in flight have all failed, without
+// an answer, up to config.Attempts times in all. It resolves with the first
+// attempt to succeed and cancels the others, or is rejected with the error
+// of the last attempt once they have all failed. Hedge panics if
+// config.Attempts is less than 1.
+func Hedge(config HedgeConfig, f interface{}, args ...interface{}) *Promise {
+	if config.Attempts < 1 {
+		panic(fmt.Errorf("Hedge needs at least 1 attempt, got %d", config.Attempts))
+	}
+	h := &hedge{config: config, f: f, args: args, launched: 1}
+	first := New(f, args...)
+	h.d = NewDeferred(first.resultType...)
+	h.d.name = "Hedge"
+	h.d.whenSettled(h.stop)
+	h.launch(first)
+	return h.d.Promise
+}
+
+// Speculate returns a promise for New(f, args...) that calls f attempts
+// times at once, taking a token from budget, if not nil, for every call
+// after the first. It resolves with the first call to succeed and cancels
+// the others, or is rejected with the error of the last call once they
+// have all failed. It is Hedge with no delay.
+func Speculate(attempts int, budget *Budget, f interface{}, args ...interface{}) *Promise {
+	return Hedge(HedgeConfig{Attempts: attempts, Budget: budget}, f, args...)
+}
+
+type hedge struct {
+	config HedgeConfig
+	f      interface{}
+	args   []interface{}
+	d      *Deferred
+
+	mu       sync.Mutex
+	attempts []*Promise
+	launched int
+	pending  int
+	// exhausted is set once no more attempts will be started.
+	exhausted bool
+	timer     Timer
+	err       error
+}
+
+// launch waits on attempt p, and starts or schedules the next attempt.
+func (h *hedge) launch(p *Promise) {
+	h.mu.Lock()
+	h.attempts = append(h.attempts, p)
+	h.pending++
+	h.exhausted = h.exhausted || h.launched == h.config.Attempts
+	now := !h.exhausted && h.config.Delay <= 0
+	if !h.exhausted && !now {
+		h.timer = afterFunc(h.config.Delay, h.next)
+	}
+	h.mu.Unlock()
+	p.observe()
+	p.graph.addChild(p, h.d.Promise)
+	p.whenSettled(func() { h.settled(p) })
+	if h.d.isSettled() && !p.isSettled() {
+		p.Cancel()
+	}
+	if now {
+		h.next()
+	}
+}
+
+// next starts another attempt if the budget allows it.
+func (h *hedge) next() {
+	h.mu.Lock()
+	if h.exhausted || h.d.isSettled() {
+		h.mu.Unlock()
+		return
+	}
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	if h.config.Budget != nil && !h.config.Budget.TryTake() {
+		h.exhausted = true
+		failed, err := h.pending == 0, h.err
+		h.mu.Unlock()
+		if failed {
+			h.d.settle(nil, err)
+		}
+		return
+	}
+	h.launched++
+	h.mu.Unlock()
+	h.launch(New(h.f, h.args...))
+}
+
+// settled handles the outcome of attempt p.
+func (h *hedge) settled(p *Promise) {
+	if p.err == nil {
+		h.d.settle(p.results, nil)
+		return
+	}
+	h.mu.Lock()
+	h.pending--
+	h.err = p.err
+	idle, exhausted := h.pending == 0, h.exhausted
+	h.mu.Unlock()
+	switch {
+	case idle && exhausted:
+		h.d.settle(nil, p.err)
+	case idle:
+		h.next()
+	}
+}
+
+// stop stops the timer and cancels the attempts still in flight once the
+// hedged promise has settled.
+func (h *hedge) stop() {
+	h.mu.Lock()
+	h.exhausted = true
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	attempts := h.attempts
+	h.mu.Unlock()
+	for _, p := range attempts {
+		if !p.isSettled() {
+			p.Cancel()
+		}
+	}
+}
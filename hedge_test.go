@@ -0,0 +1,89 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHedge(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	slow := make(chan struct{})
+	defer close(slow)
+	var attempts int32
+	fetch := func() (int, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-slow
+			return 1, nil
+		}
+		return 2, nil
+	}
+	p := Hedge(HedgeConfig{Attempts: 2, Delay: time.Second}, fetch)
+	pollUntil(t, func() bool { return atomic.LoadInt32(&attempts) == 1 && clock.pending() == 1 })
+	clock.Advance(time.Second)
+	var result int
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, 2, result, "the hedged attempt answers first")
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+
+	require.Panics(t, func() { Hedge(HedgeConfig{}, fetch) })
+}
+
+func TestHedgeStartsNextAttemptOnFailure(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	failure := errors.New("failure")
+	var attempts int32
+	fetch := func() (int, error) {
+		atomic.AddInt32(&attempts, 1)
+		return 0, failure
+	}
+	p := Hedge(HedgeConfig{Attempts: 3, Delay: time.Hour}, fetch)
+	require.Equal(t, failure, cause(p.Wait(new(int))))
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	require.Equal(t, 0, clock.pending())
+}
+
+func TestHedgeBudget(t *testing.T) {
+	b := NewBudget(1, 0)
+	failure := errors.New("failure")
+	var attempts int32
+	fetch := func() (int, error) {
+		atomic.AddInt32(&attempts, 1)
+		return 0, failure
+	}
+	require.Equal(t, failure, cause(Speculate(3, b, fetch).Wait(new(int))))
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts), "only one extra attempt fits in the budget")
+	require.Equal(t, failure, cause(Speculate(3, b, fetch).Wait(new(int))))
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestSpeculateCancelsLosers(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	started := make(chan struct{}, 3)
+	var attempts int32
+	p := Speculate(3, nil, func() (int, error) {
+		n := atomic.AddInt32(&attempts, 1)
+		started <- struct{}{}
+		if n == 3 {
+			return 3, nil
+		}
+		<-block
+		return int(n), nil
+	})
+	var result int
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, 3, result)
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+}
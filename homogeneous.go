@@ -0,0 +1,41 @@
+package promise
+
+import "reflect"
+
+// homogeneousSliceType returns []T if every promise resolves with a single
+// value of type T, and nil otherwise.
+func homogeneousSliceType(promises []*Promise) reflect.Type {
+	if len(promises[0].resultType) != 1 {
+		return nil
+	}
+	elem := promises[0].resultType[0]
+	for _, prior := range promises[1:] {
+		if len(prior.resultType) != 1 || prior.resultType[0] != elem {
+			return nil
+		}
+	}
+	return reflect.SliceOf(elem)
+}
+
+// collectSlice gathers the values of the settled homogeneous priors into
+// one pre-sized slice of p.sliceType. The returned results index into
+// that slice, so Wait into a []T and variadic Then handlers can use it
+// directly instead of assembling it value by value.
+func (p *Promise) collectSlice(priors []*Promise) []reflect.Value {
+	slice := reflect.MakeSlice(p.sliceType, len(priors), len(priors))
+	results := make([]reflect.Value, len(priors))
+	for i, prior := range priors {
+		results[i] = slice.Index(i)
+		results[i].Set(prior.results[0])
+	}
+	p.slice = slice
+	return results
+}
+
+// copySlice returns a copy of slice, so every consumer of a homogeneous
+// All gets its own backing array.
+func copySlice(slice reflect.Value) reflect.Value {
+	copied := reflect.MakeSlice(slice.Type(), slice.Len(), slice.Len())
+	reflect.Copy(copied, slice)
+	return copied
+}
@@ -0,0 +1,63 @@
+package promise
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHomogeneousAllCollectsSlice(t *testing.T) {
+	all := All(
+		New(func() int { return 1 }),
+		New(func() int { return 2 }),
+		New(func() int { return 3 }),
+	)
+	require.Equal(t, reflect.TypeOf([]int{}), all.sliceType)
+
+	var first, second []int
+	require.NoError(t, all.Wait(&first))
+	require.NoError(t, all.Wait(&second))
+	require.Equal(t, []int{1, 2, 3}, first)
+	first[0] = 100
+	require.Equal(t, []int{1, 2, 3}, second, "waiters must not share a backing array")
+
+	var a, b, c int
+	require.NoError(t, all.Wait(&a, &b, &c))
+	require.Equal(t, []int{1, 2, 3}, []int{a, b, c})
+
+	sum := all.Then(func(values ...int) int {
+		values[0] = 0
+		total := 0
+		for _, v := range values {
+			total += v
+		}
+		return total
+	})
+	var total int
+	require.NoError(t, sum.Wait(&total))
+	require.Equal(t, 5, total)
+	require.NoError(t, all.Wait(&second))
+	require.Equal(t, []int{1, 2, 3}, second)
+}
+
+func TestHeterogeneousAllHasNoSliceType(t *testing.T) {
+	all := All(New(func() int { return 1 }), New(func() string { return "" }))
+	require.Nil(t, all.sliceType)
+	all = All(New(func() (int, int) { return 1, 2 }))
+	require.Nil(t, all.sliceType)
+}
+
+func BenchmarkHomogeneousAllIntoSlice(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		promises := make([]*Promise, 10)
+		for j := range promises {
+			promises[j] = New(func(x int) int { return x }, j)
+		}
+		var values []int
+		if err := All(promises...).Wait(&values); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
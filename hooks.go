@@ -0,0 +1,125 @@
+package promise
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Hooks are called at points in the life of every promise, to build
+// logging, leak detection or tracing on. Any of them may be nil. They are
+// called on the goroutines creating, running and settling promises, so
+// they must be safe for concurrent use, and must not block or wait on
+// promises.
+type Hooks struct {
+	// OnCreate is called when a promise is created, before it is
+	// chained to the promises it depends on, so the event has no
+	// parents yet.
+	OnCreate func(e HookEvent)
+	// OnStart is called when the function of a promise starts running.
+	// Promises without a function, such as those of All or NewDeferred,
+	// never start.
+	OnStart func(e HookEvent)
+	// OnSettle is called once a promise settles, with the error it was
+	// rejected with, if any.
+	OnSettle func(e HookEvent)
+}
+
+// A HookEvent describes the promise a hook is called for.
+type HookEvent struct {
+	Promise *Promise
+	Name    string
+	// Kind is how the promise was created: "New", "Then", "All",
+	// "Race", "Any", "Catch", "Finally", or "Signal" for promises
+	// settled by the package or from outside, such as those of
+	// NewDeferred and WithTimeout.
+	Kind string
+	// Parents are the promises the promise was chained from.
+	Parents []*Promise
+	Timings Timings
+	Err     error
+}
+
+type hookPoint int
+
+const (
+	hookCreate hookPoint = iota
+	hookStart
+	hookSettle
+)
+
+var kindNames = map[promiseType]string{
+	simpleCall:  "New",
+	thenCall:    "Then",
+	allCall:     "All",
+	raceCall:    "Race",
+	anyCall:     "Any",
+	signalCall:  "Signal",
+	catchCall:   "Catch",
+	finallyCall: "Finally",
+}
+
+var (
+	// hooksMu serializes changes to hooks, which holds the registered
+	// []*Hooks and is replaced rather than modified.
+	hooksMu sync.Mutex
+	hooks   atomic.Value
+)
+
+// RegisterHooks has h called for every promise from now on, and returns
+// a function that stops calling it.
+func RegisterHooks(h Hooks) (unregister func()) {
+	registered := &h
+	hooksMu.Lock()
+	current, _ := hooks.Load().([]*Hooks)
+	hooks.Store(append(current[:len(current):len(current)], registered))
+	hooksMu.Unlock()
+	return func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		current, _ := hooks.Load().([]*Hooks)
+		remaining := make([]*Hooks, 0, len(current))
+		for _, h := range current {
+			if h != registered {
+				remaining = append(remaining, h)
+			}
+		}
+		hooks.Store(remaining)
+	}
+}
+
+// callHooks calls the registered hooks for point in p's life.
+func callHooks(p *Promise, point hookPoint) {
+	registered, _ := hooks.Load().([]*Hooks)
+	if len(registered) == 0 {
+		return
+	}
+	e := p.hookEvent()
+	for _, h := range registered {
+		var f func(HookEvent)
+		switch point {
+		case hookCreate:
+			f = h.OnCreate
+		case hookStart:
+			f = h.OnStart
+		case hookSettle:
+			f = h.OnSettle
+		}
+		if f != nil {
+			f(e)
+		}
+	}
+}
+
+// hookEvent describes p for hooks.
+func (p *Promise) hookEvent() HookEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return HookEvent{
+		Promise: p,
+		Name:    p.name,
+		Kind:    kindNames[p.t],
+		Parents: append([]*Promise(nil), p.parents...),
+		Timings: Timings{Created: p.created, Started: p.started, Settled: p.settled},
+		Err:     p.err,
+	}
+}
@@ -0,0 +1,70 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	type event struct {
+		point string
+		HookEvent
+	}
+	var mu sync.Mutex
+	var all []event
+	record := func(point string) func(HookEvent) {
+		return func(e HookEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			all = append(all, event{point, e})
+		}
+	}
+	unregister := RegisterHooks(Hooks{
+		OnCreate: record("create"),
+		OnStart:  record("start"),
+		OnSettle: record("settle"),
+	})
+	failure := errors.New("failed")
+	release := make(chan struct{})
+	first := New(func() int {
+		<-release
+		return 1
+	})
+	second := first.Then(func(int) error { return failure })
+	close(release)
+	require.Error(t, second.Wait())
+	unregister()
+	third := New(func() {})
+	third.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Promises left running by other tests are reported too.
+	var events []string
+	var settled []HookEvent
+	for _, e := range all {
+		switch e.Promise {
+		case first, second, third:
+			events = append(events, e.point+" "+e.Kind)
+			if e.point == "settle" {
+				settled = append(settled, e.HookEvent)
+			}
+		}
+	}
+	// New may start before the Then is created.
+	require.ElementsMatch(t, []string{
+		"create New", "create Then",
+		"start New", "settle New",
+		"start Then", "settle Then",
+	}, events)
+	require.Equal(t, "create New", events[0])
+	require.Equal(t, []string{"settle New", "start Then", "settle Then"}, events[3:])
+	last := settled[1]
+	require.Equal(t, second, last.Promise)
+	require.Equal(t, []*Promise{first}, last.Parents)
+	require.Equal(t, failure, last.Err)
+	require.False(t, last.Timings.Settled.IsZero())
+}
@@ -0,0 +1,63 @@
+package core
+
+import "sync"
+
+// A Cell holds the outcome of a computation once it has settled, for
+// promises that resolve with a single value of type T. The zero value is
+// ready to use.
+type Cell[T any] struct {
+	mu     sync.Mutex
+	signal Signal
+	value  T
+	err    error
+}
+
+// Settle records value and err as the outcome of c and runs the functions
+// registered with OnSettled. Only the first call has any effect, and
+// reports true.
+func (c *Cell[T]) Settle(value T, err error) bool {
+	c.mu.Lock()
+	if !c.signal.Pending() {
+		c.mu.Unlock()
+		return false
+	}
+	if err == nil {
+		c.value = value
+	}
+	c.err = err
+	callbacks, _ := c.signal.Settle()
+	c.mu.Unlock()
+	Run(callbacks)
+	return true
+}
+
+// OnSettled runs f once c has settled, on the goroutine settling it, or
+// right away if it already has.
+func (c *Cell[T]) OnSettled(f func()) {
+	c.mu.Lock()
+	queued := c.signal.Add(f)
+	c.mu.Unlock()
+	if queued == nil {
+		f()
+	}
+}
+
+// Done returns a channel that is closed once c has settled.
+func (c *Cell[T]) Done() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.signal.Done()
+}
+
+// Wait blocks until c has settled and returns its outcome.
+func (c *Cell[T]) Wait() (T, error) {
+	if c.signal.Pending() {
+		<-c.Done()
+	}
+	return c.value, c.err
+}
+
+// IsSettled reports whether c has settled.
+func (c *Cell[T]) IsSettled() bool {
+	return !c.signal.Pending()
+}
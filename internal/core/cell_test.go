@@ -0,0 +1,64 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCell(t *testing.T) {
+	var c Cell[int]
+	var calls []int
+	c.OnSettled(func() { calls = append(calls, 1) })
+	require.False(t, c.IsSettled())
+
+	require.True(t, c.Settle(42, nil))
+	require.False(t, c.Settle(1, errors.New("late")))
+	c.OnSettled(func() { calls = append(calls, 2) })
+
+	value, err := c.Wait()
+	require.NoError(t, err)
+	require.Equal(t, 42, value)
+	require.True(t, c.IsSettled())
+	require.Equal(t, []int{1, 2}, calls)
+}
+
+func TestCellError(t *testing.T) {
+	var c Cell[string]
+	go c.Settle("ignored", errors.New("failed"))
+	<-c.Done()
+	value, err := c.Wait()
+	require.EqualError(t, err, "failed")
+	require.Equal(t, "", value)
+}
+
+func TestSignal(t *testing.T) {
+	var s Signal
+	require.True(t, s.Pending())
+	var calls []int
+	first := s.Add(func() { calls = append(calls, 1) })
+	removed := s.Add(func() { calls = append(calls, 2) })
+	s.Add(func() { calls = append(calls, 3) })
+	s.Remove(removed)
+	require.Equal(t, 2, s.Waiting())
+	done := s.Done()
+
+	callbacks, ok := s.Settle()
+	require.True(t, ok)
+	require.NotNil(t, first)
+	_, ok = s.Settle()
+	require.False(t, ok)
+	<-done
+	<-s.Done()
+	Run(callbacks)
+	require.Zero(t, s.Waiting())
+	require.Equal(t, []int{1, 3}, calls)
+	require.Nil(t, s.Add(func() {}))
+	require.Equal(t, Settled, s.State())
+
+	s.Retire()
+	require.Equal(t, Retired, s.State())
+	s.Reset()
+	require.True(t, s.Pending())
+}
@@ -0,0 +1,130 @@
+// Package core is the completion engine shared by the promise packages:
+// it records whether a computation has settled, hands out a channel that
+// is closed once it has, and runs the callbacks waiting on it. It uses no
+// reflection, so the typed package can build on it without importing
+// reflect.
+package core
+
+import "sync/atomic"
+
+// The states of a Signal.
+const (
+	Pending int32 = iota
+	Settled
+	// Retired signals belong to promises waiting in a pool to be reused.
+	Retired
+)
+
+// closedChan is handed out by Done for signals that settled before
+// anything asked for their channel.
+var closedChan = make(chan struct{})
+
+func init() {
+	close(closedChan)
+}
+
+// A Signal is the settle-once state of a computation. It has no lock of
+// its own, so that it can share the lock guarding the rest of its owner:
+// every method but State and Pending must be called with that lock held.
+// The zero Signal is pending.
+type Signal struct {
+	state int32
+	// done, created on demand by Done, is closed once the signal settles
+	done      chan struct{}
+	callbacks []*Callback
+}
+
+// A Callback is a function waiting on a Signal, as returned by Add.
+type Callback struct {
+	f func()
+}
+
+// State returns the state of s. It may be called without holding the
+// owner's lock.
+func (s *Signal) State() int32 {
+	return atomic.LoadInt32(&s.state)
+}
+
+// Pending reports whether s has yet to settle. It may be called without
+// holding the owner's lock.
+func (s *Signal) Pending() bool {
+	return s.State() == Pending
+}
+
+// Add queues f to run once s settles and returns its Callback, or returns
+// nil without queuing f if s has already settled, in which case the
+// caller runs f itself once it has released its lock.
+func (s *Signal) Add(f func()) *Callback {
+	if s.state != Pending {
+		return nil
+	}
+	c := &Callback{f}
+	s.callbacks = append(s.callbacks, c)
+	return c
+}
+
+// Remove drops c, if s hasn't settled and handed it out yet.
+func (s *Signal) Remove(c *Callback) {
+	for i, other := range s.callbacks {
+		if other == c {
+			// Copied rather than shifted in place, as Run may be
+			// calling an earlier snapshot of the slice.
+			callbacks := make([]*Callback, 0, len(s.callbacks)-1)
+			callbacks = append(callbacks, s.callbacks[:i]...)
+			s.callbacks = append(callbacks, s.callbacks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Waiting returns the number of callbacks queued on s.
+func (s *Signal) Waiting() int {
+	return len(s.callbacks)
+}
+
+// Done returns a channel that is closed once s settles. It is only
+// allocated for signals that are waited on before they settle.
+func (s *Signal) Done() <-chan struct{} {
+	if s.done != nil {
+		return s.done
+	}
+	if s.state != Pending {
+		return closedChan
+	}
+	s.done = make(chan struct{})
+	return s.done
+}
+
+// Settle settles s, closing its channel, and returns the callbacks
+// waiting on it for the caller to Run once it has released its lock. It
+// reports false, and does nothing, if s isn't pending.
+func (s *Signal) Settle() ([]*Callback, bool) {
+	if s.state != Pending {
+		return nil, false
+	}
+	atomic.StoreInt32(&s.state, Settled)
+	if s.done != nil {
+		close(s.done)
+	}
+	callbacks := s.callbacks
+	s.callbacks = nil
+	return callbacks, true
+}
+
+// Reset makes s pending again, for a promise taken from a pool.
+func (s *Signal) Reset() {
+	*s = Signal{}
+}
+
+// Retire marks s as belonging to a promise put back in a pool.
+func (s *Signal) Retire() {
+	*s = Signal{}
+	atomic.StoreInt32(&s.state, Retired)
+}
+
+// Run calls the functions of callbacks in order.
+func Run(callbacks []*Callback) {
+	for _, c := range callbacks {
+		c.f()
+	}
+}
@@ -0,0 +1,14 @@
+package promise
+
+import "reflect"
+
+// ResultTypes returns the types of the values p resolves with, in order.
+// The returned slice is a copy and may be modified.
+func (p *Promise) ResultTypes() []reflect.Type {
+	return append([]reflect.Type{}, p.resultType...)
+}
+
+// NumResults returns the number of values p resolves with.
+func (p *Promise) NumResults() int {
+	return len(p.resultType)
+}
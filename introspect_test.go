@@ -0,0 +1,24 @@
+package promise
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultTypes(t *testing.T) {
+	p := New(func() (int, io.Reader, error) {
+		return 0, nil, nil
+	})
+	require.Equal(t, 2, p.NumResults())
+	types := p.ResultTypes()
+	require.Equal(t, []reflect.Type{reflect.TypeOf(0), typeOf[io.Reader]()}, types)
+
+	types[0] = nil
+	require.Equal(t, reflect.TypeOf(0), p.ResultTypes()[0], "ResultTypes returns a copy")
+
+	require.Equal(t, 0, New(func() {}).NumResults())
+	require.Equal(t, 3, All(p, New(func() string { return "" })).NumResults())
+}
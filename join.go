@@ -0,0 +1,30 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Join returns a promise that waits for all of promises, like All, and
+// then calls handler with their results, like Then on the promise All
+// returns:
+//
+//	page := Join(render, fetchUser(id), fetchPosts(id))
+//
+// Unlike chaining Then onto All, handler is checked against the results
+// of promises before anything is chained from them, so a mismatch panics
+// without leaving an All behind.
+func Join(handler interface{}, promises ...*Promise) *Promise {
+	functionRv, _ := funcValue(handler)
+	if functionRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", functionRv.Kind()))
+	}
+	var results []reflect.Type
+	for _, p := range promises {
+		results = append(results, p.resultType...)
+	}
+	if _, _, err := thenArgs(functionRv.Type(), signatureOf(functionRv.Type()).in, results, reflect.Type.AssignableTo); err != nil {
+		panic(err)
+	}
+	return All(promises...).Then(handler)
+}
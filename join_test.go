@@ -0,0 +1,38 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoin(t *testing.T) {
+	user := New(func() string { return "ada" })
+	posts := New(func() (int, error) { return 3, nil })
+	page := Join(func(name string, n int) string {
+		return fmt.Sprintf("%s has %d posts", name, n)
+	}, user, posts)
+	var s string
+	require.NoError(t, page.Wait(&s))
+	require.Equal(t, "ada has 3 posts", s)
+}
+
+func TestJoinRejectsWithFirstError(t *testing.T) {
+	failed := New(func() (string, error) { return "", errors.New("no user") })
+	called := false
+	page := Join(func(string, int) { called = true }, failed, New(func() int { return 1 }))
+	require.EqualError(t, cause(page.Wait()), "no user")
+	require.False(t, called)
+}
+
+func TestJoinChecksHandlerUpFront(t *testing.T) {
+	user := New(func() string { return "ada" })
+	require.Panics(t, func() { Join(func(string) {}, user, New(func() int { return 1 })) })
+	require.Panics(t, func() { Join(func(string, string) {}, user, New(func() int { return 1 })) })
+	require.Panics(t, func() { Join("render", user) })
+	user.mu.Lock()
+	defer user.mu.Unlock()
+	require.Empty(t, user.children)
+}
@@ -0,0 +1,142 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// AllWithLimit is like All over the promises returned by factories, except
+// that it calls at most n factories ahead of the promises that have
+// settled, so at most n of the underlying tasks run at once. It resolves
+// with the results of every promise, in the order of factories, or rejects
+// as soon as one of them fails, without calling the remaining factories.
+//
+// Every factory must return a promise with the same result types as the
+// first, which is called right away.
+func AllWithLimit(n int, factories ...func() *Promise) *Promise {
+	if len(factories) == 0 {
+		return New(empty)
+	}
+	if n < 1 {
+		panic(fmt.Errorf("limit must be at least 1, got %d", n))
+	}
+	p := newPromise(allCall, "AllWithLimit")
+	l := &limiter{p: p, factories: factories, priors: make([]*Promise, len(factories))}
+	first := l.call(0)
+	p.resultType = make([]reflect.Type, 0, len(first.resultType)*len(factories))
+	for range factories {
+		p.resultType = append(p.resultType, first.resultType...)
+	}
+	if len(first.resultType) == 1 {
+		p.sliceType = reflect.SliceOf(first.resultType[0])
+	}
+	l.remaining = len(factories)
+	l.next = 1
+	l.wait(0, first)
+	// Settling promises call further factories too, so l.next is shared.
+	for {
+		l.mu.Lock()
+		if l.next >= n || l.next >= len(factories) {
+			l.mu.Unlock()
+			break
+		}
+		i := l.next
+		l.next++
+		l.mu.Unlock()
+		l.wait(i, l.call(i))
+	}
+	return p
+}
+
+type limiter struct {
+	p         *Promise
+	factories []func() *Promise
+	priors    []*Promise
+
+	mu        sync.Mutex
+	next      int
+	remaining int
+	// joinMu serializes joining priors to p, which can happen from
+	// several goroutines
+	joinMu sync.Mutex
+}
+
+// call calls factory i, turning a panic or a nil promise into a rejected
+// promise.
+func (l *limiter) call(i int) (prior *Promise) {
+	defer func() {
+		if r := recover(); r != nil {
+			prior = Rejected(panicError(r), l.p.resultType...)
+		}
+	}()
+	prior = l.factories[i]()
+	if prior == nil {
+		panic(fmt.Errorf("factory %d returned a nil promise", i))
+	}
+	return prior
+}
+
+// wait joins prior, the promise of factory i, to the result and settles
+// the result or calls the next factory once it settles.
+func (l *limiter) wait(i int, prior *Promise) {
+	l.joinMu.Lock()
+	l.priors[i] = prior
+	prior.observe()
+	prior.graph.addChild(prior, l.p)
+	l.p.watchContext(prior.ctx)
+	l.joinMu.Unlock()
+	prior.whenSettled(func() {
+		schedule(func() {
+			l.settled(i, prior)
+		})
+	})
+}
+
+func (l *limiter) settled(i int, prior *Promise) {
+	p := l.p
+	if prior.err != nil {
+		p.settle(nil, prior.err)
+		return
+	}
+	if !typesMatch(prior.resultType, l.priors[0].resultType) {
+		p.settle(nil, fmt.Errorf("promise %d has an unexpected return type, expected all promises passed to AllWithLimit to return the same type", i))
+		return
+	}
+	l.mu.Lock()
+	l.remaining--
+	remaining := l.remaining
+	next := -1
+	if l.next < len(l.factories) && !p.isSettled() {
+		next = l.next
+		l.next++
+	}
+	l.mu.Unlock()
+	if next >= 0 {
+		l.wait(next, l.call(next))
+	}
+	if remaining != 0 {
+		return
+	}
+	if p.sliceType != nil {
+		p.settle(p.collectSlice(l.priors), nil)
+		return
+	}
+	results := make([]reflect.Value, 0, len(p.resultType))
+	for _, prior := range l.priors {
+		results = append(results, prior.results...)
+	}
+	p.settle(results, nil)
+}
+
+func typesMatch(a, b []reflect.Type) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllWithLimit(t *testing.T) {
+	var running, peak, calls int32
+	factories := make([]func() *Promise, 20)
+	for i := range factories {
+		i := i
+		factories[i] = func() *Promise {
+			atomic.AddInt32(&calls, 1)
+			return New(func() int {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					old := atomic.LoadInt32(&peak)
+					if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return i
+			})
+		}
+	}
+	var results []int
+	require.NoError(t, AllWithLimit(3, factories...).Wait(&results))
+	require.Len(t, results, 20)
+	for i, result := range results {
+		require.Equal(t, i, result)
+	}
+	require.LessOrEqual(t, atomic.LoadInt32(&peak), int32(3))
+	require.Equal(t, int32(20), atomic.LoadInt32(&calls))
+}
+
+func TestAllWithLimitStopsOnFailure(t *testing.T) {
+	var calls int32
+	factories := make([]func() *Promise, 10)
+	for i := range factories {
+		i := i
+		factories[i] = func() *Promise {
+			atomic.AddInt32(&calls, 1)
+			return New(func() (int, error) {
+				if i == 1 {
+					return 0, errors.New("failed")
+				}
+				return i, nil
+			})
+		}
+	}
+	err := AllWithLimit(1, factories...).Wait(new([]int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed")
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestAllWithLimitRejectsMismatchedTypes(t *testing.T) {
+	err := AllWithLimit(2,
+		func() *Promise { return Resolved(1) },
+		func() *Promise { return Resolved("two") },
+	).Wait(new(int), new(int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "promise 1 has an unexpected return type")
+}
+
+func TestAllWithLimitFactoryPanics(t *testing.T) {
+	err := AllWithLimit(2,
+		func() *Promise { return Resolved(1) },
+		func() *Promise { panic("no promise for you") },
+	).Wait(new(int), new(int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no promise for you")
+}
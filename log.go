@@ -0,0 +1,35 @@
+package promise
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// A Logger receives diagnostics from the package, such as rejected
+// promises that nothing observed. *log.Logger satisfies Logger.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+type loggerHolder struct {
+	Logger
+}
+
+var logger atomic.Value
+
+func init() {
+	SetLogger(nil)
+}
+
+// SetLogger routes the package's diagnostics to l. Passing nil restores
+// the default, which writes to the standard library's log package.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = log.New(log.Writer(), "", log.LstdFlags)
+	}
+	logger.Store(loggerHolder{l})
+}
+
+func logf(format string, args ...interface{}) {
+	logger.Load().(loggerHolder).Printf(format, args...)
+}
@@ -0,0 +1,68 @@
+package promise
+
+import "sync"
+
+// A ManualScheduler is a Scheduler for tests that queues promise
+// functions until the test runs them, one at a time and in the order they
+// were submitted, on the test's own goroutine:
+//
+//	s := NewManualScheduler()
+//	SetScheduler(s)
+//	defer SetScheduler(nil)
+//	p := New(fetch).Then(parse)
+//	s.Step() // runs fetch
+//	s.Step() // runs parse
+//
+// That makes orderings that would otherwise depend on goroutine timing,
+// such as which input of Race settles first, deterministic. Work the
+// package doesn't schedule, such as timers and context watchers, still
+// runs in the background. A function that blocks on a promise that only a
+// later step settles deadlocks the test.
+type ManualScheduler struct {
+	mu    sync.Mutex
+	queue []func()
+}
+
+// NewManualScheduler returns a ManualScheduler with nothing queued.
+func NewManualScheduler() *ManualScheduler {
+	return &ManualScheduler{}
+}
+
+// Submit queues f. It implements Scheduler.
+func (s *ManualScheduler) Submit(f func()) {
+	s.mu.Lock()
+	s.queue = append(s.queue, f)
+	s.mu.Unlock()
+}
+
+// Len returns the number of functions waiting to run.
+func (s *ManualScheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// Step runs the function submitted first, and reports false if there was
+// none.
+func (s *ManualScheduler) Step() bool {
+	s.mu.Lock()
+	if len(s.queue) == 0 {
+		s.mu.Unlock()
+		return false
+	}
+	f := s.queue[0]
+	s.queue = s.queue[1:]
+	s.mu.Unlock()
+	f()
+	return true
+}
+
+// RunUntilIdle runs queued functions, including those submitted while it
+// runs, until none are left, and returns how many it ran.
+func (s *ManualScheduler) RunUntilIdle() int {
+	n := 0
+	for s.Step() {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,78 @@
+package promise
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManualScheduler(t *testing.T) {
+	s := NewManualScheduler()
+	SetScheduler(s)
+	defer SetScheduler(nil)
+
+	var order []string
+	first := New(func() string {
+		order = append(order, "first")
+		return "first"
+	})
+	second := New(func() string {
+		order = append(order, "second")
+		return "second"
+	})
+	race := Race(second, first)
+
+	require.Equal(t, 2, s.Len())
+	require.Equal(t, StatePending, race.State())
+	require.True(t, s.Step())
+	require.Equal(t, []string{"first"}, order)
+	require.Equal(t, StateFulfilled, first.State())
+	// The loser is canceled as soon as the race is won, before it runs.
+	require.Equal(t, StateRejected, second.State())
+
+	s.RunUntilIdle()
+	require.False(t, s.Step())
+	var winner string
+	require.NoError(t, race.Wait(&winner))
+	require.Equal(t, "first", winner)
+	require.Equal(t, []string{"first"}, order)
+}
+
+func TestManualSchedulerRunsChains(t *testing.T) {
+	s := NewManualScheduler()
+	SetScheduler(s)
+	defer SetScheduler(nil)
+
+	p := New(func() int { return 1 }).Then(func(i int) int { return i + 1 })
+	require.GreaterOrEqual(t, s.RunUntilIdle(), 1)
+	var result int
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, 2, result)
+}
+
+func TestCombinatorsDontSchedule(t *testing.T) {
+	s := NewManualScheduler()
+	SetScheduler(s)
+	defer SetScheduler(nil)
+
+	deferreds := make([]*Deferred, 100)
+	inputs := make([]*Promise, len(deferreds))
+	for i := range deferreds {
+		deferreds[i] = NewDeferred(typeOf[int]())
+		inputs[i] = deferreds[i].Promise
+	}
+	all, anyOf, race := All(inputs...), Any(inputs...), Race(inputs...)
+	for i, d := range deferreds {
+		d.Resolve(i)
+	}
+	require.Equal(t, 0, s.Len())
+
+	var values []int
+	require.NoError(t, all.Wait(&values))
+	require.Len(t, values, len(inputs))
+	var first int
+	require.NoError(t, anyOf.Wait(&first))
+	require.Equal(t, 0, first)
+	require.NoError(t, race.Wait(&first))
+	require.Equal(t, 0, first)
+}
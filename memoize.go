@@ -0,0 +1,167 @@
+package promise
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A KeyFunc maps the arguments of a memoized call to the key its promise
+// is cached under. Calls whose arguments map to equal keys share a
+// promise.
+type KeyFunc func(args []interface{}) interface{}
+
+// Memoize returns a function that calls New(f, args...) once per distinct
+// key of args and returns the same promise to every call with that key.
+// A promise that is rejected is forgotten, so the next call with its key
+// tries again.
+//
+// key defaults to ValueKey, which compares arguments by value, so slices,
+// maps and structs holding pointers can be used as arguments.
+//
+// Resolved promises are never evicted: they, and their results, stay
+// cached for as long as the returned function is reachable. Memoize calls
+// over a bounded set of keys, or use a Cache, whose entries expire.
+func Memoize(f interface{}, key KeyFunc) func(args ...interface{}) *Promise {
+	if key == nil {
+		key = ValueKey
+	}
+	var mu sync.Mutex
+	cache := map[interface{}]promiseRef{}
+	return func(args ...interface{}) *Promise {
+		k := key(args)
+		mu.Lock()
+		defer mu.Unlock()
+		if r, ok := cache[k]; ok {
+			if r.stale() {
+				// Dropped so that later calls don't panic too.
+				delete(cache, k)
+				panic(errUsedAfterRelease)
+			}
+			// A rejected promise may still be cached if it has only
+			// just settled.
+			if p := r.p; !(p.isSettled() && p.err != nil) {
+				return p
+			}
+		}
+		p := New(f, args...)
+		cache[k] = p.ref()
+		p.whenSettled(func() {
+			if p.err == nil {
+				return
+			}
+			mu.Lock()
+			if cache[k].p == p {
+				delete(cache, k)
+			}
+			mu.Unlock()
+		})
+		return p
+	}
+}
+
+// ValueKey is the default KeyFunc. It encodes the contents of args,
+// following pointers and interfaces and encoding map entries independent
+// of their order, so arguments that are deeply equal get the same key, and
+// others get different keys. Functions and channels are compared by
+// identity, and values nested more deeply than a few dozen pointers,
+// which may be cyclic, are not told apart.
+func ValueKey(args []interface{}) interface{} {
+	var e keyEncoder
+	for _, arg := range args {
+		e.value(reflect.ValueOf(arg), true, 0)
+	}
+	return e.String()
+}
+
+// maxKeyDepth bounds how deep ValueKey follows pointers, which guards
+// against cyclic data.
+const maxKeyDepth = 32
+
+// A keyEncoder writes the encoding of values compared by ValueKey.
+type keyEncoder struct {
+	strings.Builder
+}
+
+func (e *keyEncoder) uint(u uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], u)
+	e.Write(buf[:])
+}
+
+func (e *keyEncoder) string(s string) {
+	e.uint(uint64(len(s)))
+	e.WriteString(s)
+}
+
+// value encodes v, preceded by its type if withType, as for values held in
+// interfaces, whose type may vary.
+func (e *keyEncoder) value(v reflect.Value, withType bool, depth int) {
+	if !v.IsValid() {
+		e.uint(0)
+		return
+	}
+	e.uint(uint64(v.Kind()))
+	if withType {
+		e.string(v.Type().String())
+	}
+	if depth > maxKeyDepth {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.uint(1)
+		} else {
+			e.uint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.uint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.uint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.uint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		e.uint(math.Float64bits(real(v.Complex())))
+		e.uint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.uint(0)
+			return
+		}
+		e.uint(1)
+		e.value(v.Elem(), v.Kind() == reflect.Interface, depth+1)
+	case reflect.Slice, reflect.Array:
+		e.uint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			e.value(v.Index(i), false, depth+1)
+		}
+	case reflect.Struct:
+		e.string(v.Type().String())
+		for i := 0; i < v.NumField(); i++ {
+			e.value(v.Field(i), false, depth+1)
+		}
+	case reflect.Map:
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var entry keyEncoder
+			entry.value(iter.Key(), false, depth+1)
+			entry.value(iter.Value(), false, depth+1)
+			entries = append(entries, entry.String())
+		}
+		sort.Strings(entries)
+		e.uint(uint64(len(entries)))
+		for _, entry := range entries {
+			e.string(entry)
+		}
+	default:
+		// Functions, channels and unsafe pointers.
+		e.uint(uint64(v.Pointer()))
+	}
+}
@@ -0,0 +1,75 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memoQuery struct {
+	Table  string
+	Filter map[string]string
+	Limit  *int
+}
+
+func TestMemoizeSharesPromisesByValue(t *testing.T) {
+	var calls int32
+	lookup := Memoize(func(q memoQuery, ids []int) int {
+		atomic.AddInt32(&calls, 1)
+		return len(ids)
+	}, nil)
+
+	limit, sameLimit := 10, 10
+	a := lookup(memoQuery{"users", map[string]string{"a": "1", "b": "2"}, &limit}, []int{1, 2})
+	b := lookup(memoQuery{"users", map[string]string{"b": "2", "a": "1"}, &sameLimit}, []int{1, 2})
+	c := lookup(memoQuery{"users", map[string]string{"a": "1"}, &limit}, []int{1, 2})
+	require.True(t, a == b)
+	require.False(t, a == c)
+	require.NoError(t, All(a, c).Wait(new(int), new(int)))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestMemoizeCustomKey(t *testing.T) {
+	var calls int32
+	fetch := Memoize(func(url string) string {
+		atomic.AddInt32(&calls, 1)
+		return url
+	}, func(args []interface{}) interface{} {
+		return len(args[0].(string))
+	})
+	require.True(t, fetch("http://a") == fetch("http://b"))
+	require.NoError(t, fetch("http://a").Wait(new(string)))
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestMemoizeForgetsRejections(t *testing.T) {
+	var calls int32
+	flaky := Memoize(func(id int) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return errors.New("flaky")
+		}
+		return nil
+	}, nil)
+	require.Error(t, flaky(1).Wait())
+	require.NoError(t, flaky(1).Wait())
+	require.NoError(t, flaky(1).Wait())
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestValueKey(t *testing.T) {
+	require.Equal(t, ValueKey([]interface{}{[]int{1, 2}}), ValueKey([]interface{}{[]int{1, 2}}))
+	require.NotEqual(t, ValueKey([]interface{}{[]int{1, 2}}), ValueKey([]interface{}{[]int{2, 1}}))
+	require.NotEqual(t, ValueKey([]interface{}{"ab", "c"}), ValueKey([]interface{}{"a", "bc"}))
+	require.NotEqual(t, ValueKey([]interface{}{1}), ValueKey([]interface{}{"1"}))
+	require.NotEqual(t, ValueKey([]interface{}{nil}), ValueKey([]interface{}{0}))
+	require.NotEqual(t, ValueKey([]interface{}{1}), ValueKey([]interface{}{int64(1)}))
+	require.NotEqual(t, ValueKey([]interface{}{[]interface{}{1}}), ValueKey([]interface{}{[]interface{}{uint(1)}}))
+	require.Equal(t, ValueKey([]interface{}{map[int]string{1: "a", 2: "b"}}), ValueKey([]interface{}{map[int]string{2: "b", 1: "a"}}))
+
+	type node struct{ Next *node }
+	cyclic := &node{}
+	cyclic.Next = cyclic
+	require.NotPanics(t, func() { ValueKey([]interface{}{cyclic}) })
+}
@@ -0,0 +1,77 @@
+package promise
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MermaidOptions customizes the diagrams written by Graph.Mermaid. The
+// zero value is ready to use.
+type MermaidOptions struct {
+	// Direction is the flowchart direction, such as "TD" or "LR". It
+	// defaults to "TD".
+	Direction string
+	// Label, if set, returns the text of a promise's node. It defaults to
+	// the promise's short name.
+	Label func(p *Promise) string
+}
+
+// Mermaid writes g as a Mermaid flowchart, with an edge from every
+// promise to each promise chained from it. Nodes are colored by whether
+// their promise is pending, resolved or rejected at the time of the call,
+// so a diagram of a running graph shows where it is stuck.
+func (g *Graph) Mermaid(w io.Writer, opts MermaidOptions) error {
+	direction := opts.Direction
+	if direction == "" {
+		direction = "TD"
+	}
+	label := opts.Label
+	if label == nil {
+		label = shortName
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "flowchart %s\n", direction)
+	ids := map[*Promise]string{}
+	queue := []*Promise{g.root}
+	ids[g.root] = "p0"
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		fmt.Fprintf(&b, "    %s[\"%s\"]:::%s\n", ids[p], mermaidEscape(label(p)), mermaidClasses[p.State()])
+		p.mu.Lock()
+		children := append([]*Promise{}, p.children...)
+		p.mu.Unlock()
+		for _, child := range children {
+			if _, ok := ids[child]; !ok {
+				ids[child] = fmt.Sprintf("p%d", len(ids))
+				queue = append(queue, child)
+			}
+			fmt.Fprintf(&b, "    %s --> %s\n", ids[p], ids[child])
+		}
+	}
+	b.WriteString("    classDef pending fill:#fff3bf,stroke:#f08c00\n")
+	b.WriteString("    classDef resolved fill:#d3f9d8,stroke:#2f9e44\n")
+	b.WriteString("    classDef rejected fill:#ffe3e3,stroke:#e03131\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidClasses are the names of the Mermaid classes nodes get for each
+// state.
+var mermaidClasses = map[State]string{
+	StatePending:   "pending",
+	StateFulfilled: "resolved",
+	StateRejected:  "rejected",
+}
+
+// shortName returns p's name without its package path.
+func shortName(p *Promise) string {
+	return p.Name()[strings.LastIndex(p.Name(), "/")+1:]
+}
+
+// mermaidEscape makes s safe to use in a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
@@ -0,0 +1,51 @@
+package promise
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphMermaid(t *testing.T) {
+	signal := Signal()
+	root := signal.Promise()
+	ok := root.Then(func() {})
+	failed := root.Then(func() error { return errors.New("failed") })
+	pending := All(ok, failed).Catch(func(error) {})
+
+	var b strings.Builder
+	require.NoError(t, root.Graph().Mermaid(&b, MermaidOptions{}))
+	require.Equal(t, `flowchart TD
+    p0["Signal"]:::pending
+    p0 --> p1
+    p0 --> p2
+    p1["v2.TestGraphMermaid.func1"]:::pending
+    p1 --> p3
+    p2["v2.TestGraphMermaid.func2"]:::pending
+    p2 --> p3
+    p3["All"]:::pending
+    p3 --> p4
+    p4["v2.TestGraphMermaid.func3"]:::pending
+    classDef pending fill:#fff3bf,stroke:#f08c00
+    classDef resolved fill:#d3f9d8,stroke:#2f9e44
+    classDef rejected fill:#ffe3e3,stroke:#e03131
+`, b.String())
+
+	signal.Resolve()
+	require.NoError(t, pending.Wait())
+	b.Reset()
+	err := root.Graph().Mermaid(&b, MermaidOptions{
+		Direction: "LR",
+		Label: func(p *Promise) string {
+			return `"` + mermaidClasses[p.State()] + `"`
+		},
+	})
+	require.NoError(t, err)
+	require.Contains(t, b.String(), "flowchart LR\n")
+	require.Contains(t, b.String(), `p0["#quot;resolved#quot;"]:::resolved`)
+	require.Contains(t, b.String(), `p2["#quot;rejected#quot;"]:::rejected`)
+	require.Contains(t, b.String(), `p3["#quot;rejected#quot;"]:::rejected`)
+	require.Contains(t, b.String(), `p4["#quot;resolved#quot;"]:::resolved`)
+}
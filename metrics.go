@@ -0,0 +1,143 @@
+package promise
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives counts and latencies from every promise in the
+// program, so services can export them to their monitoring system. Its
+// methods are called on the goroutines creating, scheduling and settling
+// promises, and must be safe for concurrent use and not block.
+type Metrics interface {
+	// Created is called for every promise created.
+	Created()
+	// Settled is called once for every promise that settles, with the
+	// error it was rejected with, if any, and when it went through each
+	// stage of its life. Waiters on the promise may already have been
+	// woken by then.
+	Settled(name string, err error, timings Timings)
+	// Panicked is called when a promise function panics and the panic is
+	// turned into a rejection.
+	Panicked()
+	// QueueDepth is called with the number of promise functions submitted
+	// to the scheduler that haven't started running, whenever it changes.
+	QueueDepth(depth int)
+}
+
+type metricsHolder struct {
+	Metrics
+}
+
+var metrics atomic.Value
+
+// queued counts the functions submitted to the scheduler that haven't
+// started.
+var queued int64
+
+// SetMetrics reports the package's metrics to m. Passing nil stops
+// reporting.
+func SetMetrics(m Metrics) {
+	metrics.Store(metricsHolder{m})
+}
+
+func loadMetrics() Metrics {
+	m, _ := metrics.Load().(metricsHolder)
+	return m.Metrics
+}
+
+func metricsCreated() {
+	if m := loadMetrics(); m != nil {
+		m.Created()
+	}
+}
+
+func metricsSettled(p *Promise) {
+	if m := loadMetrics(); m != nil {
+		m.Settled(p.Name(), p.err, p.Timings())
+	}
+}
+
+func metricsPanicked() {
+	if m := loadMetrics(); m != nil {
+		m.Panicked()
+	}
+}
+
+// queueAdd adjusts the number of queued functions by delta.
+func queueAdd(delta int64) {
+	depth := atomic.AddInt64(&queued, delta)
+	if m := loadMetrics(); m != nil {
+		m.QueueDepth(int(depth))
+	}
+}
+
+// ExpvarMetrics is a Metrics that publishes its counts with the expvar
+// package, under a map named by NewExpvarMetrics. The map holds the
+// counters created, settled, rejected and panicked, the gauges in_flight
+// and queue_depth, and cumulative histograms of queue delay and execution
+// time as keys such as execution_le_10ms.
+type ExpvarMetrics struct {
+	vars *expvar.Map
+
+	inFlight   expvar.Int
+	queueDepth expvar.Int
+}
+
+// latencyBuckets are the upper bounds of the ExpvarMetrics histograms.
+var latencyBuckets = []time.Duration{
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// NewExpvarMetrics returns an ExpvarMetrics published under name. Like
+// expvar.NewMap, it panics if name is already published.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	m := &ExpvarMetrics{vars: expvar.NewMap(name)}
+	m.vars.Set("in_flight", &m.inFlight)
+	m.vars.Set("queue_depth", &m.queueDepth)
+	return m
+}
+
+// Created implements Metrics.
+func (m *ExpvarMetrics) Created() {
+	m.vars.Add("created", 1)
+	m.inFlight.Add(1)
+}
+
+// Settled implements Metrics.
+func (m *ExpvarMetrics) Settled(name string, err error, timings Timings) {
+	m.vars.Add("settled", 1)
+	m.inFlight.Add(-1)
+	if err != nil {
+		m.vars.Add("rejected", 1)
+	}
+	if !timings.Started.IsZero() {
+		m.observe("queue_delay", timings.QueueDelay())
+		m.observe("execution", timings.Execution())
+	}
+}
+
+// Panicked implements Metrics.
+func (m *ExpvarMetrics) Panicked() {
+	m.vars.Add("panicked", 1)
+}
+
+// QueueDepth implements Metrics.
+func (m *ExpvarMetrics) QueueDepth(depth int) {
+	m.queueDepth.Set(int64(depth))
+}
+
+// observe adds d to every bucket of the histogram prefix it fits in.
+func (m *ExpvarMetrics) observe(prefix string, d time.Duration) {
+	for _, bound := range latencyBuckets {
+		if d <= bound {
+			m.vars.Add(prefix+"_le_"+bound.String(), 1)
+		}
+	}
+	m.vars.Add(prefix+"_count", 1)
+}
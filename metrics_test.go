@@ -0,0 +1,114 @@
+package promise
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMetrics struct {
+	mu       sync.Mutex
+	created  int
+	settled  map[string]error
+	panicked int
+	maxDepth int
+}
+
+func (m *recordingMetrics) Created() {
+	m.mu.Lock()
+	m.created++
+	m.mu.Unlock()
+}
+
+func (m *recordingMetrics) Settled(name string, err error, timings Timings) {
+	m.mu.Lock()
+	m.settled[name] = err
+	m.mu.Unlock()
+}
+
+func (m *recordingMetrics) Panicked() {
+	m.mu.Lock()
+	m.panicked++
+	m.mu.Unlock()
+}
+
+func (m *recordingMetrics) QueueDepth(depth int) {
+	m.mu.Lock()
+	if depth > m.maxDepth {
+		m.maxDepth = depth
+	}
+	m.mu.Unlock()
+}
+
+func metricsOK() int { return 1 }
+
+func metricsFails() (int, error) { return 0, errors.New("boom") }
+
+func metricsPanics() int { panic("oops") }
+
+func TestMetrics(t *testing.T) {
+	m := &recordingMetrics{settled: map[string]error{}}
+	SetMetrics(m)
+	defer SetMetrics(nil)
+
+	ok := New(metricsOK)
+	fails := New(metricsFails)
+	panics := New(metricsPanics)
+	require.NoError(t, ok.Wait(new(int)))
+	require.Error(t, fails.Wait(new(int)))
+	require.Error(t, panics.Wait(new(int)))
+
+	// Metrics hear of a settled promise after its waiters are woken.
+	pollUntil(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.settled) >= 3
+	})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	require.GreaterOrEqual(t, m.created, 3)
+	require.GreaterOrEqual(t, m.panicked, 1)
+	require.GreaterOrEqual(t, m.maxDepth, 1)
+	require.NoError(t, m.settled[ok.name])
+	require.EqualError(t, m.settled[fails.name], "boom")
+	require.Error(t, m.settled[panics.name])
+}
+
+// pollUntil waits up to a second for cond to hold.
+func pollUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("condition not met within a second")
+}
+
+// expvarMetrics is created once, as expvar names can't be published twice.
+var expvarMetrics = NewExpvarMetrics("promise_test_metrics")
+
+func TestExpvarMetrics(t *testing.T) {
+	SetMetrics(expvarMetrics)
+	defer SetMetrics(nil)
+
+	require.NoError(t, New(metricsOK).Wait(new(int)))
+	require.Error(t, New(metricsFails).Wait(new(int)))
+
+	vars := expvar.Get("promise_test_metrics").(*expvar.Map)
+	pollUntil(t, func() bool {
+		settled, _ := vars.Get("settled").(*expvar.Int)
+		return settled != nil && settled.Value() >= 2
+	})
+	require.GreaterOrEqual(t, vars.Get("created").(*expvar.Int).Value(), int64(2))
+	require.GreaterOrEqual(t, vars.Get("settled").(*expvar.Int).Value(), int64(2))
+	require.GreaterOrEqual(t, vars.Get("rejected").(*expvar.Int).Value(), int64(1))
+	require.GreaterOrEqual(t, vars.Get("execution_count").(*expvar.Int).Value(), int64(2))
+	require.GreaterOrEqual(t, vars.Get("execution_le_10s").(*expvar.Int).Value(), int64(2))
+	require.NotNil(t, vars.Get("queue_depth"))
+}
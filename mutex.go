@@ -0,0 +1,181 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrLockTimeout is the error the promise returned by LockTimeout or
+// RLockTimeout is rejected with if the lock isn't acquired in time.
+var ErrLockTimeout = errors.New("timed out acquiring lock")
+
+// A Mutex is a mutual exclusion lock whose Lock returns a promise, so
+// acquiring it composes with other promises instead of blocking a
+// goroutine. The zero value is an unlocked mutex. Waiters acquire the
+// lock in the order they called Lock.
+type Mutex struct {
+	rw RWMutex
+}
+
+// Lock returns a promise that resolves, with no values, once m is locked
+// on behalf of the caller. Rejecting the promise before then, for example
+// with Cancel, gives up on acquiring the lock.
+//
+// A promise that loses a Race with a timeout still acquires the lock
+// later; use LockCtx or LockTimeout, or cancel it, instead.
+func (m *Mutex) Lock() *Promise {
+	return m.rw.Lock()
+}
+
+// LockCtx is like Lock, except that the promise is rejected with ctx's
+// error if ctx is done before the lock is acquired.
+func (m *Mutex) LockCtx(ctx context.Context) *Promise {
+	return m.rw.LockCtx(ctx)
+}
+
+// LockTimeout is like Lock, except that the promise is rejected with
+// ErrLockTimeout if the lock isn't acquired within d.
+func (m *Mutex) LockTimeout(d time.Duration) *Promise {
+	return m.rw.LockTimeout(d)
+}
+
+// Unlock unlocks m. It panics if m is not locked.
+func (m *Mutex) Unlock() {
+	m.rw.Unlock()
+}
+
+// An RWMutex is a reader/writer lock whose lock methods return promises,
+// like Mutex. Any number of readers or a single writer can hold it.
+// Waiters acquire it in the order they asked, so a waiting writer holds
+// back readers that arrive after it. The zero value is an unlocked mutex.
+type RWMutex struct {
+	mu      sync.Mutex
+	readers int
+	writer  bool
+	queue   []lockWaiter
+}
+
+type lockWaiter struct {
+	p     *Promise
+	write bool
+}
+
+// Lock returns a promise that resolves once m is locked for writing.
+func (m *RWMutex) Lock() *Promise {
+	return m.acquire(true, "RWMutex.Lock", nil, 0)
+}
+
+// LockCtx is like Lock, except that the promise is rejected with ctx's
+// error if ctx is done before the lock is acquired.
+func (m *RWMutex) LockCtx(ctx context.Context) *Promise {
+	return m.acquire(true, "RWMutex.Lock", ctx, 0)
+}
+
+// LockTimeout is like Lock, except that the promise is rejected with
+// ErrLockTimeout if the lock isn't acquired within d.
+func (m *RWMutex) LockTimeout(d time.Duration) *Promise {
+	return m.acquire(true, "RWMutex.Lock", nil, d)
+}
+
+// RLock returns a promise that resolves once m is locked for reading.
+func (m *RWMutex) RLock() *Promise {
+	return m.acquire(false, "RWMutex.RLock", nil, 0)
+}
+
+// RLockCtx is like RLock, except that the promise is rejected with ctx's
+// error if ctx is done before the lock is acquired.
+func (m *RWMutex) RLockCtx(ctx context.Context) *Promise {
+	return m.acquire(false, "RWMutex.RLock", ctx, 0)
+}
+
+// RLockTimeout is like RLock, except that the promise is rejected with
+// ErrLockTimeout if the lock isn't acquired within d.
+func (m *RWMutex) RLockTimeout(d time.Duration) *Promise {
+	return m.acquire(false, "RWMutex.RLock", nil, d)
+}
+
+// Unlock releases m from writing. It panics if m is not locked for
+// writing.
+func (m *RWMutex) Unlock() {
+	m.release(true)
+}
+
+// RUnlock releases one reader of m. It panics if m is not locked for
+// reading.
+func (m *RWMutex) RUnlock() {
+	m.release(false)
+}
+
+func (m *RWMutex) acquire(write bool, name string, ctx context.Context, timeout time.Duration) *Promise {
+	p := newPromise(signalCall, name)
+	newGraph(p)
+	p.watchContext(ctx)
+	if timeout > 0 {
+		timer := afterFunc(timeout, func() {
+			p.settle(nil, ErrLockTimeout)
+		})
+		p.Defer(func() { timer.Stop() })
+	}
+	m.mu.Lock()
+	m.queue = append(m.queue, lockWaiter{p: p, write: write})
+	granted := m.grant()
+	m.mu.Unlock()
+	m.resolve(granted)
+	return p
+}
+
+func (m *RWMutex) release(write bool) {
+	m.mu.Lock()
+	switch {
+	case write && !m.writer:
+		m.mu.Unlock()
+		panic(errors.New("promise: Unlock of unlocked RWMutex"))
+	case !write && m.readers == 0:
+		m.mu.Unlock()
+		panic(errors.New("promise: RUnlock of unlocked RWMutex"))
+	case write:
+		m.writer = false
+	default:
+		m.readers--
+	}
+	granted := m.grant()
+	m.mu.Unlock()
+	m.resolve(granted)
+}
+
+// grant hands the lock to the waiters at the front of the queue that can
+// hold it now, skipping waiters that gave up. m.mu must be held.
+func (m *RWMutex) grant() []lockWaiter {
+	var granted []lockWaiter
+	for len(m.queue) > 0 {
+		w := m.queue[0]
+		if w.p.isSettled() {
+			m.queue = m.queue[1:]
+			continue
+		}
+		if m.writer || (w.write && m.readers > 0) {
+			break
+		}
+		m.queue = m.queue[1:]
+		granted = append(granted, w)
+		if w.write {
+			m.writer = true
+			break
+		}
+		m.readers++
+	}
+	return granted
+}
+
+// resolve settles the promises of granted waiters. A waiter that gave up
+// in the meantime releases the lock it was handed.
+func (m *RWMutex) resolve(granted []lockWaiter) {
+	for _, w := range granted {
+		if !w.p.settle([]reflect.Value{}, nil) {
+			m.release(w.write)
+		}
+	}
+}
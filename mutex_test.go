@@ -0,0 +1,90 @@
+package promise
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutexSerializesHolders(t *testing.T) {
+	var m Mutex
+	var mu sync.Mutex
+	order := []int{}
+	done := make([]*Promise, 5)
+	for i := range done {
+		i := i
+		done[i] = m.Lock().Then(func() {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			m.Unlock()
+		})
+	}
+	require.NoError(t, All(done...).Wait())
+	require.Equal(t, []int{0, 1, 2, 3, 4}, order)
+}
+
+func TestMutexLockTimeout(t *testing.T) {
+	var m Mutex
+	require.NoError(t, m.Lock().Wait())
+	err := m.LockTimeout(10 * time.Millisecond).Wait()
+	require.Equal(t, ErrLockTimeout, cause(err))
+
+	// The waiter that gave up doesn't keep the lock from the next one.
+	next := m.Lock()
+	m.Unlock()
+	require.NoError(t, next.Wait())
+	m.Unlock()
+}
+
+func TestMutexLockCtx(t *testing.T) {
+	var m Mutex
+	require.NoError(t, m.Lock().Wait())
+	ctx, cancel := context.WithCancel(context.Background())
+	p := m.LockCtx(ctx)
+	cancel()
+	require.Equal(t, context.Canceled, cause(p.Wait()))
+	m.Unlock()
+	require.NoError(t, m.Lock().Wait())
+}
+
+func TestMutexCanceledWaiterIsSkipped(t *testing.T) {
+	var m Mutex
+	require.NoError(t, m.Lock().Wait())
+	canceled := m.Lock()
+	next := m.Lock()
+	canceled.Cancel()
+	m.Unlock()
+	require.NoError(t, next.Wait())
+	require.Error(t, canceled.Wait())
+}
+
+func TestMutexUnlockOfUnlocked(t *testing.T) {
+	var m Mutex
+	require.Panics(t, m.Unlock)
+	var rw RWMutex
+	require.Panics(t, rw.RUnlock)
+}
+
+func TestRWMutex(t *testing.T) {
+	var m RWMutex
+	require.NoError(t, All(m.RLock(), m.RLock()).Wait())
+
+	writer := m.Lock()
+	lateReader := m.RLock()
+	require.False(t, writer.isSettled())
+	m.RUnlock()
+	require.False(t, writer.isSettled())
+	m.RUnlock()
+	require.NoError(t, writer.Wait())
+	require.False(t, lateReader.isSettled())
+
+	m.Unlock()
+	require.NoError(t, lateReader.Wait())
+	err := m.LockTimeout(10 * time.Millisecond).Wait()
+	require.Equal(t, ErrLockTimeout, cause(err))
+	m.RUnlock()
+}
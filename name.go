@@ -0,0 +1,90 @@
+package promise
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxChainDepth bounds how many ancestors chainPath lists.
+const maxChainDepth = 16
+
+// NewNamed is like New, but names the promise name rather than after f.
+func NewNamed(name string, f interface{}, args ...interface{}) *Promise {
+	return newWith(f, options{args: args, name: name, named: true})
+}
+
+// WithName names p, replacing the name of its function, so that it can be
+// told apart in String, Describe, DumpPending, SLOs and the errors
+// returned by Wait. It returns p for chaining:
+//
+//	New(fetch, url).WithName("fetch google").Then(readBody).WithName("read body")
+//
+// Hooks that already saw p under its old name, such as a fault injector
+// when its function has started, aren't told of the change.
+func (p *Promise) WithName(name string) *Promise {
+	p.checkCopy()
+	p.mu.Lock()
+	p.name = name
+	p.named = true
+	p.mu.Unlock()
+	return p
+}
+
+// Name returns the name p was given with NewNamed or WithName, or else the
+// name of its function or the combinator that created it.
+func (p *Promise) Name() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.name
+}
+
+// label returns the name p is shown as in a chain, and whether it was
+// named explicitly.
+func (p *Promise) label() (label string, named bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.named {
+		return p.name, true
+	}
+	if p.name == "" {
+		return "<anonymous>", false
+	}
+	return p.name[strings.LastIndex(p.name, "/")+1:], false
+}
+
+// ancestry labels p and the promises it was chained from, first to last,
+// following the first parent of promises with several. It reports whether
+// any of them was named explicitly.
+func (p *Promise) ancestry() (labels []string, named bool) {
+	for q := p; q != nil && len(labels) < maxChainDepth; {
+		label, isNamed := q.label()
+		labels = append(labels, label)
+		named = named || isNamed
+		q.mu.Lock()
+		var parent *Promise
+		if len(q.parents) > 0 {
+			parent = q.parents[0]
+		}
+		q.mu.Unlock()
+		q = parent
+	}
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return labels, named
+}
+
+// executionError wraps err, the error p was rejected with, for Wait and
+// friends. When any promise in p's chain was named, the message includes
+// the chain so the failure can be attributed. In RepanicOnWait mode, a
+// rejection by a panic panics again instead.
+func (p *Promise) executionError(err error) error {
+	repanic(err)
+	if debugging() {
+		err = p.debugError(err)
+	}
+	if labels, named := p.ancestry(); named {
+		return fmt.Errorf("error during promise execution (%s): %w", strings.Join(labels, " → "), err)
+	}
+	return fmt.Errorf("error during promise execution: %w", err)
+}
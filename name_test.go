@@ -0,0 +1,41 @@
+package promise
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNamed(t *testing.T) {
+	p := NewNamed("fetch google", func() int { return 1 })
+	require.Equal(t, "fetch google", p.Name())
+	require.NoError(t, p.Wait(new(int)))
+	require.True(t, strings.HasPrefix(p.String(), "promise fetch google (int) fulfilled"), p.String())
+}
+
+func TestWithNameChainInErrors(t *testing.T) {
+	p := NewNamed("fetch google", func() string { return "body" }).
+		Then(func(s string) (int, error) { return 0, errors.New("truncated") }).WithName("read body").
+		Then(func(n int) int { return n })
+	err := p.Wait(new(int))
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "error during promise execution (fetch google → read body → "), err.Error())
+	require.True(t, strings.HasSuffix(err.Error(), "TestWithNameChainInErrors.func3): truncated"), err.Error())
+}
+
+func TestUnnamedChainKeepsPlainErrors(t *testing.T) {
+	err := New(func() error { return errors.New("boom") }).Wait()
+	require.EqualError(t, err, "error during promise execution: boom")
+}
+
+func TestDescribeChain(t *testing.T) {
+	d := NewDeferred(typeOf[int]())
+	p := d.Promise.WithName("fetch").Then(func(n int) int { return n }).WithName("checksum")
+	desc := p.Describe()
+	require.Contains(t, desc, "promise checksum\n  chain:   fetch → checksum\n")
+	require.Contains(t, desc, "state:   pending")
+	d.Resolve(1)
+	require.NoError(t, p.Wait(new(int)))
+}
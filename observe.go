@@ -0,0 +1,11 @@
+package promise
+
+// observeSettled feeds a freshly settled promise to the package's
+// accounting hooks.
+func observeSettled(p *Promise) {
+	countSettled(p)
+	recordSLO(p)
+	sampleSettled(p)
+	metricsSettled(p)
+	callHooks(p, hookSettle)
+}
@@ -0,0 +1,138 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// An Option configures a promise created by NewWith.
+type Option func(o *options)
+
+// options are the settings of a promise created by NewWith. The package's
+// other constructors fill them in directly.
+type options struct {
+	args      []interface{}
+	name      string
+	named     bool
+	timeout   time.Duration
+	priority  int
+	executor  Scheduler
+	noRecover bool
+	// ctx, if set, is passed to the function ahead of args, as by NewCtx
+	ctx context.Context
+	// limiter, if set, hands out a token before the function starts, as
+	// for NewLimited
+	limiter RateLimiter
+	// call, if set, calls the function in place of reflection
+	call thunk
+	// strict, if set, checks args as in strict mode even with it off
+	strict bool
+	// concurrency bounds the chunks Batch processes at once
+	concurrency int
+}
+
+// NewWith is like New, but configured by opts:
+//
+//	p := NewWith(fetch, Args(url), WithName("fetch"), WithTimeout(2*time.Second))
+func NewWith(f interface{}, opts ...Option) *Promise {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newWith(f, o)
+}
+
+// Args passes args to the function of a promise created by NewWith.
+func Args(args ...interface{}) Option {
+	return func(o *options) {
+		o.args = append(o.args, args...)
+	}
+}
+
+// WithName names the promise, as NewNamed does.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name, o.named = name, true
+	}
+}
+
+// WithTimeout rejects the promise with ErrTimeout if its function runs for
+// longer than d. The function keeps running after the timeout, and its
+// results are discarded.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithExecutor runs the promise's function on s rather than on the
+// scheduler set with SetScheduler, such as to keep blocking calls on a
+// pool of their own. Promises chained from the promise aren't affected.
+func WithExecutor(s Scheduler) Option {
+	return func(o *options) {
+		o.executor = s
+	}
+}
+
+// WithNoRecover lets a panic in the promise's function crash the program
+// rather than rejecting the promise with a *PanicError, for code where a
+// panic is always a bug.
+func WithNoRecover() Option {
+	return func(o *options) {
+		o.noRecover = true
+	}
+}
+
+// newWith implements NewWith and the package's other constructors.
+func newWith(f interface{}, o options) *Promise {
+	var leading []reflect.Value
+	var ctxRv reflect.Value
+	if o.ctx != nil {
+		functionRv := reflect.ValueOf(f)
+		if functionRv.Kind() != reflect.Func {
+			panic(fmt.Errorf("expected Function, got %s", functionRv.Kind()))
+		}
+		if functionRv.Type().NumIn() == 0 || functionRv.Type().In(0) != contextType {
+			panic(fmt.Errorf("expected first argument of type %s, got %s", contextType, functionRv.Type()))
+		}
+		ctxRv = reflect.New(contextType).Elem()
+		leading = []reflect.Value{ctxRv}
+	}
+	c := newCaller(f)
+	c.strict = o.strict
+	p, start := c.newCall(leading, o.args)
+	if o.named {
+		p.name, p.named = o.name, true
+	}
+	if o.priority != 0 {
+		p.WithPriority(o.priority)
+	}
+	if o.timeout > 0 {
+		x := p.extras()
+		x.timeout, x.timeoutErr = o.timeout, ErrTimeout
+	}
+	if o.executor != nil || o.noRecover {
+		x := p.extras()
+		x.executor, x.noRecover = o.executor, o.noRecover
+	}
+	if o.call != nil {
+		p.call = o.call
+	}
+	if o.ctx != nil {
+		// The function gets its own context so that Cancel can abort
+		// it. The context is filled in once p exists, for Checkpoint,
+		// and before start, so the function sees it.
+		fnCtx, cancel := context.WithCancel(o.ctx)
+		ctxRv.Set(reflect.ValueOf(context.WithValue(fnCtx, promiseKey{}, p)))
+		p.extras().cancelCtx = cancel
+		p.watchContext(o.ctx)
+	}
+	if o.limiter != nil {
+		p.waitForToken(o.limiter, start)
+		return p
+	}
+	start()
+	return p
+}
@@ -0,0 +1,49 @@
+package promise
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWith(t *testing.T) {
+	p := NewWith(func(a, b int) int { return a + b }, Args(1, 2), WithName("add"))
+	var sum int
+	require.NoError(t, p.Wait(&sum))
+	require.Equal(t, 3, sum)
+	require.Equal(t, "add", p.Name())
+}
+
+func TestNewWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	p := NewWith(func() int {
+		<-release
+		return 1
+	}, WithTimeout(10*time.Millisecond))
+	require.Equal(t, ErrTimeout, cause(p.Wait(new(int))))
+}
+
+func TestNewWithExecutor(t *testing.T) {
+	s := NewManualScheduler()
+	p := NewWith(func(n int) int { return n * 2 }, Args(21), WithExecutor(s))
+	require.Equal(t, 1, s.Len())
+	require.False(t, p.isSettled())
+	s.RunUntilIdle()
+	var n int
+	require.NoError(t, p.Wait(&n))
+	require.Equal(t, 42, n)
+}
+
+func TestNewWithNoRecover(t *testing.T) {
+	s := NewManualScheduler()
+	p := NewWith(func() {
+		panic("bug")
+	}, WithExecutor(s), WithNoRecover())
+	require.PanicsWithValue(t, "bug", func() {
+		s.Step()
+	})
+	require.False(t, p.isSettled())
+	p.Cancel()
+}
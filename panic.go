@@ -0,0 +1,122 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// A rejection is panicked by the package itself to reject the running
+// promise with err, such as when a prior promise failed. Unlike a panic in
+// user code, it is never passed to the panic translator.
+type rejection struct {
+	err error
+}
+
+type panicTranslator struct {
+	translate func(interface{}) error
+}
+
+var translator atomic.Value
+
+// SetPanicTranslator installs f to turn values recovered from panicking
+// promise functions into errors, so panics carrying structured values can
+// become meaningful domain errors. If f is nil or returns nil, the default
+// is used: the value is wrapped in a *PanicError.
+func SetPanicTranslator(f func(interface{}) error) {
+	translator.Store(panicTranslator{f})
+}
+
+// A PanicMode decides what happens when the function of a promise panics.
+type PanicMode int32
+
+const (
+	// RecoverPanics rejects the promise with a *PanicError, or the error
+	// the panic translator returns. It is the default.
+	RecoverPanics PanicMode = iota
+	// RepanicOnWait rejects the promise as RecoverPanics does, but Wait
+	// and the package's other waiting calls panic with the *PanicError
+	// instead of returning it, so the panic surfaces on the goroutine
+	// that waits for the result. Handlers such as Catch still see the
+	// error.
+	RepanicOnWait
+	// CrashOnPanic doesn't recover panics at all, so they crash the
+	// program as in a plain goroutine, as WithNoRecover does for a single
+	// promise.
+	CrashOnPanic
+)
+
+var panicMode int32
+
+// SetPanicMode sets what happens when the function of a promise panics,
+// for code where a panic always means a bug that mustn't be turned into
+// an error. Promises already rejected by a panic are unaffected.
+func SetPanicMode(mode PanicMode) {
+	atomic.StoreInt32(&panicMode, int32(mode))
+}
+
+func currentPanicMode() PanicMode {
+	return PanicMode(atomic.LoadInt32(&panicMode))
+}
+
+// recovers reports whether r, recovered from the function of p, rejects p
+// rather than being panicked again.
+func (p *Promise) recovers(r interface{}) bool {
+	if _, ok := r.(rejection); ok {
+		return true
+	}
+	return !p.peekExtras().noRecover && currentPanicMode() != CrashOnPanic
+}
+
+// repanic panics with err's *PanicError, if it has one, in RepanicOnWait
+// mode.
+func repanic(err error) {
+	if currentPanicMode() != RepanicOnWait {
+		return
+	}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		panic(panicErr)
+	}
+}
+
+// A PanicError rejects a promise whose function panicked. It keeps the
+// value passed to panic and the stack of the panicking goroutine, so
+// callers can recover both with errors.As.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+	// site is where the panic happened, in debug mode
+	site string
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("panic: %+v", err.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (err *PanicError) Unwrap() error {
+	cause, _ := err.Value.(error)
+	return cause
+}
+
+// panicError returns the error a promise is rejected with after r was
+// recovered from its function. It must be called by the deferred function
+// that recovered r, so the stack still shows where the panic happened.
+func panicError(r interface{}) error {
+	if rej, ok := r.(rejection); ok {
+		return rej.err
+	}
+	metricsPanicked()
+	if t, _ := translator.Load().(panicTranslator); t.translate != nil {
+		if err := t.translate(r); err != nil {
+			return err
+		}
+	}
+	err := &PanicError{Value: r, Stack: debug.Stack()}
+	if debugging() {
+		err.site = panicSite()
+	}
+	return err
+}
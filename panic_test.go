@@ -0,0 +1,109 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type abort struct {
+	status int
+}
+
+type statusError struct {
+	status int
+}
+
+func (err statusError) Error() string {
+	return fmt.Sprintf("status %d", err.status)
+}
+
+func TestPanicTranslator(t *testing.T) {
+	SetPanicTranslator(func(r interface{}) error {
+		if a, ok := r.(abort); ok {
+			return statusError{a.status}
+		}
+		return nil
+	})
+	defer SetPanicTranslator(nil)
+
+	err := New(func() {
+		panic(abort{status: 404})
+	}).Wait()
+	require.Equal(t, statusError{404}, cause(err))
+
+	err = New(func() {
+		panic("plain")
+	}).Wait()
+	require.Contains(t, err.Error(), "plain")
+}
+
+func TestPanicTranslatorSkipsPropagatedErrors(t *testing.T) {
+	translated := 0
+	SetPanicTranslator(func(r interface{}) error {
+		translated++
+		return nil
+	})
+	defer SetPanicTranslator(nil)
+
+	failed := errors.New("failed")
+	err := New(func() error {
+		return failed
+	}).Then(func() {}).Wait()
+	require.Equal(t, failed, cause(err))
+	require.Equal(t, 0, translated)
+}
+
+func TestPanicError(t *testing.T) {
+	err := New(func() {
+		panic(abort{status: 500})
+	}).Wait()
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	require.Equal(t, abort{status: 500}, panicErr.Value)
+	require.Contains(t, string(panicErr.Stack), "TestPanicError")
+
+	failure := fmt.Errorf("failure")
+	err = New(func() {
+		panic(failure)
+	}).Wait()
+	require.True(t, errors.As(err, &panicErr))
+	require.True(t, errors.Is(err, failure))
+}
+
+func TestRepanicOnWait(t *testing.T) {
+	SetPanicMode(RepanicOnWait)
+	defer SetPanicMode(RecoverPanics)
+	p := New(func() int {
+		panic("bug")
+	}).Then(func(n int) int { return n })
+	require.Panics(t, func() {
+		_ = p.Wait(new(int))
+	})
+
+	var caught error
+	require.NoError(t, p.Catch(func(err error) int {
+		caught = err
+		return 0
+	}).Wait(new(int)))
+	var panicErr *PanicError
+	require.True(t, errors.As(caught, &panicErr))
+
+	failed := Rejected(errors.New("plain"))
+	require.Error(t, failed.Wait())
+}
+
+func TestCrashOnPanic(t *testing.T) {
+	SetPanicMode(CrashOnPanic)
+	defer SetPanicMode(RecoverPanics)
+	s := NewManualScheduler()
+	p := NewWith(func() {
+		panic("bug")
+	}, WithExecutor(s))
+	require.PanicsWithValue(t, "bug", func() {
+		s.Step()
+	})
+	p.Cancel()
+}
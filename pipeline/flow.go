@@ -0,0 +1,296 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	promise "github.com/garlicnation/promises/v2"
+)
+
+// Buffer lets a stage of a Flow run up to n results ahead of the stage
+// after it. Stages default to a buffer of 0, so each result waits until
+// the next stage takes it. Buffer has no effect on Pipeline.Run.
+func Buffer(n int) Option {
+	if n < 0 {
+		panic(fmt.Errorf("buffer must not be negative, got %d", n))
+	}
+	return func(s *StageDef) {
+		s.buffer = n
+	}
+}
+
+type stepKind int
+
+const (
+	mapStep stepKind = iota
+	filterStep
+	batchStep
+	sinkStep
+)
+
+// A step is a stage of a Flow.
+type step struct {
+	StageDef
+	kind stepKind
+	// size is the size of the batches of a batchStep
+	size int
+}
+
+// A Flow streams the values of a source through a sequence of stages, as
+// for ETL jobs whose inputs don't fit in memory or arrive over time:
+//
+//	done := pipeline.Source(readLines).
+//		Map("parse", parseRecord, pipeline.Parallelism(8)).
+//		Filter("valid", isValid).
+//		Batch(100).
+//		Sink("store", storeBatch, pipeline.Parallelism(2))
+//
+// Every stage runs Parallelism instances, each taking the next value
+// as soon as it is free, so stages with a parallelism above 1 may
+// reorder values. Each instance runs as a promise, under the scheduler
+// set with promise.SetScheduler, for as long as the flow runs.
+type Flow struct {
+	source reflect.Value
+	elem   reflect.Type
+	steps  []step
+}
+
+// Source starts a flow with the values f emits. f takes a function that
+// it calls with each value, all of one type, and returns once there are
+// no more, optionally with an error that fails the flow. Once the flow
+// fails, emitting values does nothing.
+func Source(f interface{}) *Flow {
+	fn := reflect.ValueOf(f)
+	t := fn.Type()
+	if fn.Kind() != reflect.Func || t.NumIn() != 1 || t.In(0).Kind() != reflect.Func ||
+		t.In(0).NumIn() != 1 || t.In(0).NumOut() != 0 ||
+		t.NumOut() > 1 || t.NumOut() == 1 && t.Out(0) != errorType {
+		panic(fmt.Errorf("source must be a func(emit func(T)), optionally returning an error, got %s", t))
+	}
+	return &Flow{source: fn, elem: t.In(0).In(0)}
+}
+
+// Map adds a stage called name that passes each value through fn, which
+// takes a single value and returns a single result, optionally followed
+// by an error that fails the flow. It returns f for chaining.
+func (f *Flow) Map(name string, fn interface{}, opts ...Option) *Flow {
+	s := Stage(name, fn, opts...)
+	f.check(s)
+	f.steps = append(f.steps, step{StageDef: s, kind: mapStep})
+	f.elem = s.fn.Type().Out(0)
+	return f
+}
+
+// Filter adds a stage called name that drops the values for which fn,
+// which takes a single value, returns false. fn may also return an error
+// that fails the flow. It returns f for chaining.
+func (f *Flow) Filter(name string, fn interface{}, opts ...Option) *Flow {
+	s := Stage(name, fn, opts...)
+	f.check(s)
+	if s.fn.Type().Out(0).Kind() != reflect.Bool {
+		panic(fmt.Errorf("stage %s: filter must return a bool, got %s", name, s.fn.Type()))
+	}
+	f.steps = append(f.steps, step{StageDef: s, kind: filterStep})
+	return f
+}
+
+// Batch adds a stage that gathers values into slices of size, the last
+// of which may be shorter. Batches are gathered by a single instance,
+// whatever the Parallelism. It returns f for chaining.
+func (f *Flow) Batch(size int, opts ...Option) *Flow {
+	if size < 1 {
+		panic(fmt.Errorf("batch size must be at least 1, got %d", size))
+	}
+	s := StageDef{name: fmt.Sprintf("batch of %d", size), parallelism: 1}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	s.parallelism = 1
+	f.steps = append(f.steps, step{StageDef: s, kind: batchStep, size: size})
+	f.elem = reflect.SliceOf(f.elem)
+	return f
+}
+
+// Sink ends the flow with a stage called name that consumes each value
+// with fn, which takes a single value and returns nothing or an error
+// that fails the flow, and starts the flow. The returned promise
+// resolves once every value has been consumed, or is rejected with the
+// first error or panic of any stage, which stops the others.
+func (f *Flow) Sink(name string, fn interface{}, opts ...Option) *promise.Promise {
+	sinkFn := reflect.ValueOf(fn)
+	t := sinkFn.Type()
+	if sinkFn.Kind() != reflect.Func || t.NumIn() != 1 || t.IsVariadic() ||
+		t.NumOut() > 1 || t.NumOut() == 1 && t.Out(0) != errorType {
+		panic(fmt.Errorf("stage %s: sink must take one value and return nothing or an error, got %s", name, t))
+	}
+	s := StageDef{name: name, fn: sinkFn, parallelism: 1}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	f.check(s)
+	f.steps = append(f.steps, step{StageDef: s, kind: sinkStep})
+	return f.run()
+}
+
+// check panics if s can't take the values of the stage before it.
+func (f *Flow) check(s StageDef) {
+	if s.newShard != nil {
+		panic(fmt.Errorf("stage %s: StageState is not supported in a Flow", s.name))
+	}
+	if in := s.fn.Type().In(0); !f.elem.AssignableTo(in) {
+		panic(fmt.Errorf("stage %s takes %s, but is fed %s", s.name, in, f.elem))
+	}
+}
+
+// errStopped ends the instances of a flow that has failed. It never
+// rejects the flow, which has already been rejected with the failure.
+var errStopped = errors.New("flow stopped")
+
+// run starts the source and an instance of every stage for each of its
+// parallelism, connected by channels.
+func (f *Flow) run() *promise.Promise {
+	stop := make(chan struct{})
+	var once sync.Once
+	halt := func(error) { once.Do(func() { close(stop) }) }
+
+	var workers []*promise.Promise
+	start := func(fn func() error) {
+		w := promise.New(func() error {
+			if err := fn(); err != errStopped {
+				return err
+			}
+			return nil
+		})
+		w.OnError(halt)
+		workers = append(workers, w)
+	}
+
+	source := make(chan reflect.Value)
+	start(func() error { return f.emit(source, stop) })
+	out := source
+	for _, s := range f.steps {
+		in := out
+		out = nil
+		if s.kind != sinkStep {
+			out = make(chan reflect.Value, s.buffer)
+		}
+		closer := &closer{out: out, remaining: int32(s.parallelism)}
+		for i := 0; i < s.parallelism; i++ {
+			s, out := s, out
+			start(func() error {
+				defer closer.done()
+				if s.kind == batchStep {
+					return batch(s.size, in, out, stop)
+				}
+				return s.consume(in, out, stop)
+			})
+		}
+	}
+	return promise.All(workers...)
+}
+
+// emit runs the source, sending what it emits to out.
+func (f *Flow) emit(out chan<- reflect.Value, stop <-chan struct{}) error {
+	defer close(out)
+	emitFn := reflect.MakeFunc(f.source.Type().In(0), func(args []reflect.Value) []reflect.Value {
+		select {
+		case out <- args[0]:
+		case <-stop:
+		}
+		return nil
+	})
+	results := f.source.Call([]reflect.Value{emitFn})
+	if len(results) == 1 && !results[0].IsNil() {
+		return results[0].Interface().(error)
+	}
+	return nil
+}
+
+// consume runs one instance of a map, filter or sink stage, taking values
+// from in and sending results to out.
+func (s step) consume(in <-chan reflect.Value, out chan<- reflect.Value, stop <-chan struct{}) error {
+	for {
+		var v reflect.Value
+		var ok bool
+		select {
+		case v, ok = <-in:
+			if !ok {
+				return nil
+			}
+		case <-stop:
+			return errStopped
+		}
+		results := s.fn.Call([]reflect.Value{v})
+		if last := len(results) - 1; last >= 0 && results[last].Type() == errorType {
+			if !results[last].IsNil() {
+				return results[last].Interface().(error)
+			}
+			results = results[:last]
+		}
+		switch s.kind {
+		case sinkStep:
+			continue
+		case filterStep:
+			if !results[0].Bool() {
+				continue
+			}
+		case mapStep:
+			v = results[0]
+		}
+		select {
+		case out <- v:
+		case <-stop:
+			return errStopped
+		}
+	}
+}
+
+// batch gathers the values from in into slices of size and sends them to
+// out.
+func batch(size int, in <-chan reflect.Value, out chan<- reflect.Value, stop <-chan struct{}) error {
+	var current reflect.Value
+	send := func() bool {
+		select {
+		case out <- current:
+			current = reflect.Value{}
+			return true
+		case <-stop:
+			return false
+		}
+	}
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				if current.IsValid() && !send() {
+					return errStopped
+				}
+				return nil
+			}
+			if !current.IsValid() {
+				current = reflect.MakeSlice(reflect.SliceOf(v.Type()), 0, size)
+			}
+			current = reflect.Append(current, v)
+			if current.Len() == size && !send() {
+				return errStopped
+			}
+		case <-stop:
+			return errStopped
+		}
+	}
+}
+
+// A closer closes out once every instance of a stage is done.
+type closer struct {
+	out       chan reflect.Value
+	remaining int32
+}
+
+func (c *closer) done() {
+	if atomic.AddInt32(&c.remaining, -1) == 0 && c.out != nil {
+		close(c.out)
+	}
+}
@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func count(n int) func(emit func(int)) {
+	return func(emit func(int)) {
+		for i := 1; i <= n; i++ {
+			emit(i)
+		}
+	}
+}
+
+func TestFlow(t *testing.T) {
+	var batches [][]string
+	done := Source(count(7)).
+		Map("double", func(i int) int { return i * 2 }).
+		Filter("skip six", func(i int) bool { return i != 6 }).
+		Map("format", strconv.Itoa).
+		Batch(2).
+		Sink("collect", func(batch []string) { batches = append(batches, batch) })
+	require.NoError(t, done.Wait())
+	require.Equal(t, [][]string{{"2", "4"}, {"8", "10"}, {"12", "14"}}, batches)
+}
+
+func TestFlowParallelStages(t *testing.T) {
+	var mu sync.Mutex
+	var got []int
+	var peak, running int32
+	done := Source(count(100)).
+		Map("square", func(i int) int {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			return i * i
+		}, Parallelism(4), Buffer(8)).
+		Sink("collect", func(i int) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, i)
+		}, Parallelism(2))
+	require.NoError(t, done.Wait())
+	require.LessOrEqual(t, atomic.LoadInt32(&peak), int32(4))
+	sort.Ints(got)
+	require.Len(t, got, 100)
+	require.Equal(t, 10000, got[99])
+}
+
+func TestFlowStopsOnError(t *testing.T) {
+	done := Source(count(1000)).
+		Map("check", func(i int) (int, error) {
+			if i == 3 {
+				return 0, errors.New("bad input")
+			}
+			return i, nil
+		}).
+		Sink("discard", func(int) {})
+	err := done.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "bad input")
+}
+
+func TestFlowSourceError(t *testing.T) {
+	done := Source(func(emit func(int)) error {
+		emit(1)
+		return errors.New("source failed")
+	}).Sink("discard", func(int) error { return nil })
+	err := done.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "source failed")
+}
+
+func TestFlowPanicsOnMismatchedStages(t *testing.T) {
+	require.Panics(t, func() {
+		Source(count(1)).Map("format", strconv.Itoa).Sink("sum", func(int) {})
+	})
+	require.Panics(t, func() {
+		Source(count(1)).Filter("not a predicate", func(i int) int { return i })
+	})
+	require.Panics(t, func() { Source(func() {}) })
+	require.Panics(t, func() { Source(count(1)).Batch(0) })
+}
@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var registry = struct {
+	sync.RWMutex
+	funcs map[string]interface{}
+}{funcs: map[string]interface{}{}}
+
+// Register makes f available to pipeline specs under name. Registering a
+// name twice replaces the earlier function.
+func Register(name string, f interface{}) {
+	registry.Lock()
+	registry.funcs[name] = f
+	registry.Unlock()
+}
+
+// A Spec declares a pipeline in configuration rather than code.
+type Spec struct {
+	Stages []StageSpec `yaml:"stages" json:"stages"`
+}
+
+// A StageSpec declares one stage of a Spec.
+type StageSpec struct {
+	// Name names the stage.
+	Name string `yaml:"name" json:"name"`
+	// Func is the name a function was registered under with Register.
+	Func string `yaml:"func" json:"func"`
+	// Parallelism, if set, is passed to the Parallelism option.
+	Parallelism int `yaml:"parallelism,omitempty" json:"parallelism,omitempty"`
+}
+
+// Load builds a pipeline from a YAML or JSON spec such as:
+//
+//	stages:
+//	  - name: parse
+//	    func: atoi
+//	    parallelism: 8
+//	  - name: double
+//	    func: double
+func Load(data []byte) (*Pipeline, error) {
+	var spec Spec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return nil, fmt.Errorf("parsing pipeline spec: %w", err)
+	}
+	return FromSpec(spec)
+}
+
+// FromSpec builds a pipeline from spec, looking up its functions among
+// those registered with Register.
+func FromSpec(spec Spec) (p *Pipeline, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid pipeline spec: %v", r)
+		}
+	}()
+	if len(spec.Stages) == 0 {
+		return nil, errors.New("pipeline spec has no stages")
+	}
+	stages := make([]StageDef, len(spec.Stages))
+	registry.RLock()
+	defer registry.RUnlock()
+	for i, s := range spec.Stages {
+		f, ok := registry.funcs[s.Func]
+		if !ok {
+			return nil, fmt.Errorf("stage %s: no function registered as %q", stageName(i, s), s.Func)
+		}
+		var opts []Option
+		if s.Parallelism != 0 {
+			opts = append(opts, Parallelism(s.Parallelism))
+		}
+		stages[i] = Stage(stageName(i, s), f, opts...)
+	}
+	return New(stages...), nil
+}
+
+// stageName returns the name of the i'th stage of a spec, defaulting to
+// its function.
+func stageName(i int, s StageSpec) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if s.Func != "" {
+		return s.Func
+	}
+	return fmt.Sprint(i)
+}
@@ -0,0 +1,59 @@
+package pipeline
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	Register("atoi", strconv.Atoi)
+	Register("double", func(i int) int { return i * 2 })
+	Register("itoa", strconv.Itoa)
+}
+
+func TestLoadYAML(t *testing.T) {
+	p, err := Load([]byte(`
+stages:
+  - name: parse
+    func: atoi
+    parallelism: 4
+  - func: double
+`))
+	require.NoError(t, err)
+	require.Equal(t, "parse", p.Stages()[0].Name())
+	require.Equal(t, 4, p.Stages()[0].parallelism)
+	require.Equal(t, "double", p.Stages()[1].Name())
+
+	var results []int
+	require.NoError(t, p.Run([]string{"1", "2"}).Wait(&results))
+	require.Equal(t, []int{2, 4}, results)
+}
+
+func TestLoadJSON(t *testing.T) {
+	p, err := Load([]byte(`{"stages": [{"name": "parse", "func": "atoi"}, {"name": "format", "func": "itoa"}]}`))
+	require.NoError(t, err)
+	var results []string
+	require.NoError(t, p.Run([]string{"01"}).Wait(&results))
+	require.Equal(t, []string{"1"}, results)
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := Load([]byte(`stages: [{func: missing}]`))
+	require.EqualError(t, err, `stage missing: no function registered as "missing"`)
+
+	_, err = Load([]byte(`stages: [{func: atoi}, {func: atoi}]`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid pipeline spec")
+
+	_, err = Load([]byte(`stages: [{func: atoi, parallelism: -1}]`))
+	require.Error(t, err)
+
+	_, err = Load([]byte(`stages: []`))
+	require.EqualError(t, err, "pipeline spec has no stages")
+
+	_, err = Load([]byte(`stages: [{func: atoi, typo: 1}]`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "parsing pipeline spec")
+}
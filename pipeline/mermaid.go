@@ -0,0 +1,26 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Mermaid writes p as a left-to-right Mermaid flowchart with one node per
+// stage, labeled with its parallelism when that is more than one.
+func (p *Pipeline) Mermaid(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, s := range p.stages {
+		label := s.name
+		if s.parallelism > 1 {
+			label = fmt.Sprintf("%s ×%d", s.name, s.parallelism)
+		}
+		fmt.Fprintf(&b, "    s%d[\"%s\"]\n", i, strings.ReplaceAll(label, `"`, "#quot;"))
+		if i > 0 {
+			fmt.Fprintf(&b, "    s%d --> s%d\n", i-1, i)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,23 @@
+package pipeline
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineMermaid(t *testing.T) {
+	p := New(
+		Stage("parse", strconv.Atoi, Parallelism(8)),
+		Stage(`"format"`, strconv.Itoa),
+	)
+	var b strings.Builder
+	require.NoError(t, p.Mermaid(&b))
+	require.Equal(t, `flowchart LR
+    s0["parse ×8"]
+    s1["#quot;format#quot;"]
+    s0 --> s1
+`, b.String())
+}
@@ -0,0 +1,236 @@
+// Package pipeline runs batches of inputs through a sequence of named
+// stages built on promises. Every input flows through the stages on its
+// own, so different inputs can be in different stages at the same time,
+// while each stage limits how many of its instances run at once. A Flow
+// streams values from a source through stages the same way, for inputs
+// that aren't all known up front.
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	promise "github.com/garlicnation/promises/v2"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// A StageDef is a stage declared with Stage.
+type StageDef struct {
+	name        string
+	fn          reflect.Value
+	parallelism int
+	// buffer is the capacity of the channel a Flow stage sends results on
+	buffer int
+	// newShard and merge are set by StageState
+	newShard func() interface{}
+	merge    func(shards []interface{})
+}
+
+// Name returns the name of the stage.
+func (s StageDef) Name() string {
+	return s.name
+}
+
+// An Option configures a stage.
+type Option func(*StageDef)
+
+// Parallelism lets up to n instances of a stage run at once, each on a
+// different input. Stages default to a parallelism of 1.
+func Parallelism(n int) Option {
+	if n < 1 {
+		panic(fmt.Errorf("parallelism must be at least 1, got %d", n))
+	}
+	return func(s *StageDef) {
+		s.parallelism = n
+	}
+}
+
+// StageState gives every instance of a stage that runs at the same time
+// its own shard of state, created by newShard, which the stage function
+// takes as its second argument. Once every input of a run has passed the
+// stage, merge is called with the shards of that run, so a stage can
+// accumulate counters or caches without synchronizing on its own.
+func StageState(newShard func() interface{}, merge func(shards []interface{})) Option {
+	return func(s *StageDef) {
+		s.newShard = newShard
+		s.merge = merge
+	}
+}
+
+// Stage declares a stage called name that runs f on each input. f takes
+// a single argument, followed by its shard of state if the stage has
+// StageState, and returns a single result, optionally followed by an
+// error that rejects the run.
+func Stage(name string, f interface{}, opts ...Option) StageDef {
+	fn := reflect.ValueOf(f)
+	if fn.Kind() != reflect.Func {
+		panic(fmt.Errorf("stage %s: expected Function, got %v", name, fn.Kind()))
+	}
+	s := StageDef{name: name, fn: fn, parallelism: 1}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	t := fn.Type()
+	if s.newShard != nil {
+		if t.NumIn() != 2 || t.IsVariadic() {
+			panic(fmt.Errorf("stage %s: function must take an argument and its state", name))
+		}
+	} else if t.NumIn() != 1 || t.IsVariadic() {
+		panic(fmt.Errorf("stage %s: function must take exactly one argument", name))
+	}
+	if t.NumOut() != 1 && (t.NumOut() != 2 || t.Out(1) != errorType) {
+		panic(fmt.Errorf("stage %s: function must return one value and optionally an error", name))
+	}
+	return s
+}
+
+// A Pipeline is a sequence of stages, each fed the results of the one
+// before.
+type Pipeline struct {
+	stages []StageDef
+	// slots bounds the instances of each stage running at once, across
+	// every run of the pipeline. Each slot is numbered, and an instance
+	// holding a slot uses the shard of state with its number.
+	slots []chan int
+}
+
+// New returns a pipeline of stages, in order. It panics if a stage can't
+// accept the results of the stage before it.
+func New(stages ...StageDef) *Pipeline {
+	if len(stages) == 0 {
+		panic(errors.New("pipeline requires at least one stage"))
+	}
+	for i := 1; i < len(stages); i++ {
+		out := stages[i-1].fn.Type().Out(0)
+		in := stages[i].fn.Type().In(0)
+		if !out.AssignableTo(in) {
+			panic(fmt.Errorf("stage %s takes %s, but stage %s returns %s", stages[i].name, in, stages[i-1].name, out))
+		}
+	}
+	p := &Pipeline{stages: stages, slots: make([]chan int, len(stages))}
+	for i, s := range stages {
+		p.slots[i] = make(chan int, s.parallelism)
+		for slot := 0; slot < s.parallelism; slot++ {
+			p.slots[i] <- slot
+		}
+	}
+	return p
+}
+
+// Stages returns the stages of p, in order.
+func (p *Pipeline) Stages() []StageDef {
+	return append([]StageDef{}, p.stages...)
+}
+
+// Run feeds every element of inputs, which must be a slice, through the
+// pipeline. The returned promise resolves with a slice of the last
+// stage's results in the order of inputs, or rejects with the first
+// error from any stage.
+func (p *Pipeline) Run(inputs interface{}) *promise.Promise {
+	rv := reflect.ValueOf(inputs)
+	if rv.Kind() != reflect.Slice {
+		panic(fmt.Errorf("expected a slice of inputs, got %v", rv.Kind()))
+	}
+	in := p.stages[0].fn.Type().In(0)
+	if !rv.Type().Elem().AssignableTo(in) {
+		panic(fmt.Errorf("stage %s takes %s, but inputs are %s", p.stages[0].name, in, rv.Type().Elem()))
+	}
+	items := make([]*promise.Promise, rv.Len())
+	states := make([]*runState, len(p.stages))
+	for j, s := range p.stages {
+		states[j] = newRunState(s, len(items))
+	}
+	for i := range items {
+		d := promise.NewDeferred(rv.Type().Elem())
+		d.Resolve(rv.Index(i).Interface())
+		item := d.Promise
+		for j := range p.stages {
+			item = item.Then(p.instance(j, item.ResultTypes()[0], states[j]).Interface())
+			states[j].watch(item)
+		}
+		items[i] = item
+	}
+	out := p.stages[len(p.stages)-1].fn.Type().Out(0)
+	collect := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{reflect.SliceOf(out)}, []reflect.Type{reflect.SliceOf(out)}, true),
+		func(args []reflect.Value) []reflect.Value {
+			results := reflect.MakeSlice(reflect.SliceOf(out), args[0].Len(), args[0].Len())
+			reflect.Copy(results, args[0])
+			return []reflect.Value{results}
+		})
+	return promise.All(items...).Then(collect.Interface())
+}
+
+// instance returns a function taking arg that runs stage i once a slot
+// for it is free.
+func (p *Pipeline) instance(i int, arg reflect.Type, state *runState) reflect.Value {
+	s := p.stages[i]
+	t := s.fn.Type()
+	outs := make([]reflect.Type, t.NumOut())
+	for j := range outs {
+		outs[j] = t.Out(j)
+	}
+	return reflect.MakeFunc(reflect.FuncOf([]reflect.Type{arg}, outs, false), func(args []reflect.Value) []reflect.Value {
+		slot := <-p.slots[i]
+		defer func() { p.slots[i] <- slot }()
+		if state.shards != nil {
+			shard := reflect.New(t.In(1)).Elem()
+			if v := state.shards[slot]; v != nil {
+				shard.Set(reflect.ValueOf(v))
+			}
+			args = append(args, shard)
+		}
+		return s.fn.Call(args)
+	})
+}
+
+// A runState tracks the shards of state of one stage for one run.
+type runState struct {
+	shards []interface{}
+	merge  func(shards []interface{})
+
+	mu sync.Mutex
+	// remaining counts the inputs yet to pass the stage
+	remaining int
+}
+
+func newRunState(s StageDef, inputs int) *runState {
+	state := &runState{merge: s.merge, remaining: inputs}
+	if s.newShard == nil {
+		return state
+	}
+	state.shards = make([]interface{}, s.parallelism)
+	for i := range state.shards {
+		state.shards[i] = s.newShard()
+	}
+	if inputs == 0 {
+		state.done()
+	}
+	return state
+}
+
+// watch counts item, the promise of an input passing the stage, towards
+// merging the shards.
+func (state *runState) watch(item *promise.Promise) {
+	if state.shards == nil {
+		return
+	}
+	item.OnComplete(func([]interface{}, error) {
+		state.mu.Lock()
+		state.remaining--
+		last := state.remaining == 0
+		state.mu.Unlock()
+		if last {
+			state.done()
+		}
+	})
+}
+
+func (state *runState) done() {
+	if state.merge != nil {
+		state.merge(state.shards)
+	}
+}
@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineRun(t *testing.T) {
+	p := New(
+		Stage("parse", strconv.Atoi),
+		Stage("double", func(i int) int { return i * 2 }),
+		Stage("format", strconv.Itoa),
+	)
+	var results []string
+	require.NoError(t, p.Run([]string{"1", "2", "3"}).Wait(&results))
+	require.Equal(t, []string{"2", "4", "6"}, results)
+}
+
+func TestPipelineRunRejectsOnStageError(t *testing.T) {
+	p := New(Stage("parse", strconv.Atoi))
+	err := p.Run([]string{"1", "x"}).Wait(new([]int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `parsing "x"`)
+}
+
+func TestPipelineRunEmpty(t *testing.T) {
+	p := New(Stage("parse", strconv.Atoi))
+	var results []int
+	require.NoError(t, p.Run([]string{}).Wait(&results))
+	require.Empty(t, results)
+}
+
+func TestParallelismLimitsStageInstances(t *testing.T) {
+	var running, peak int32
+	track := func(s string) string {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return strings.ToUpper(s)
+	}
+	p := New(Stage("upper", track, Parallelism(3)))
+	inputs := make([]string, 12)
+	for i := range inputs {
+		inputs[i] = strconv.Itoa(i)
+	}
+	require.NoError(t, p.Run(inputs).Wait(new([]string)))
+	require.Equal(t, int32(3), atomic.LoadInt32(&peak))
+}
+
+func TestNewChecksStageTypes(t *testing.T) {
+	require.Panics(t, func() {
+		New(Stage("parse", strconv.Atoi), Stage("upper", strings.ToUpper))
+	})
+	require.Panics(t, func() {
+		Stage("bad", func(a, b int) int { return a + b })
+	})
+	require.Panics(t, func() {
+		Stage("bad", func(int) (int, int) { return 0, 0 })
+	})
+	require.Panics(t, func() {
+		New(Stage("parse", strconv.Atoi)).Run([]int{1})
+	})
+	require.Panics(t, func() { Parallelism(0) })
+}
+
+func TestPipelineRunInterfaceInputs(t *testing.T) {
+	p := New(Stage("describe", func(err error) string {
+		if err == nil {
+			return "ok"
+		}
+		return err.Error()
+	}))
+	var results []string
+	require.NoError(t, p.Run([]error{nil, errors.New("failed")}).Wait(&results))
+	require.Equal(t, []string{"ok", "failed"}, results)
+}
+
+func TestStageState(t *testing.T) {
+	var total int
+	var shardCount int
+	count := Stage("count", func(s string, seen map[string]int) string {
+		seen[s]++
+		time.Sleep(time.Millisecond)
+		return s
+	}, Parallelism(4), StageState(
+		func() interface{} { return map[string]int{} },
+		func(shards []interface{}) {
+			shardCount = len(shards)
+			for _, shard := range shards {
+				for _, n := range shard.(map[string]int) {
+					total += n
+				}
+			}
+		},
+	))
+	p := New(count)
+	inputs := make([]string, 20)
+	for i := range inputs {
+		inputs[i] = strconv.Itoa(i % 3)
+	}
+	require.NoError(t, p.Run(inputs).Wait(new([]string)))
+	require.Equal(t, 4, shardCount)
+	require.Equal(t, 20, total)
+}
+
+func TestStageStateRequiresStateArgument(t *testing.T) {
+	require.Panics(t, func() {
+		Stage("count", func(s string) string { return s }, StageState(func() interface{} { return 0 }, nil))
+	})
+}
@@ -0,0 +1,190 @@
+package promise
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// DefaultIdleTimeout is how long a Pool worker waits for work before
+// exiting, unless changed with SetIdleTimeout.
+const DefaultIdleTimeout = 30 * time.Second
+
+// DefaultStarvationLimit is how many functions of a higher priority may
+// run ahead of one already queued in a Pool, per level of priority they
+// have over it, unless changed with SetStarvationLimit.
+const DefaultStarvationLimit = 1000
+
+// A Pool runs submitted functions on at most size worker goroutines.
+// Workers are started on demand and exit after sitting idle for the pool's
+// idle timeout, so a mostly idle pool holds no goroutines. Queued
+// functions run in order of priority, and in the order they were
+// submitted within a priority.
+type Pool struct {
+	size        int
+	idleTimeout time.Duration
+
+	mu      sync.Mutex
+	queue   poolQueue
+	seq     int64
+	starve  int64
+	workers int
+	idle    int
+	// wake hands queued work to idle workers, one token per claimed worker
+	wake chan struct{}
+}
+
+// NewPool returns a pool that runs at most size functions concurrently.
+func NewPool(size int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	return &Pool{
+		size:        size,
+		idleTimeout: DefaultIdleTimeout,
+		starve:      DefaultStarvationLimit,
+		wake:        make(chan struct{}, size),
+	}
+}
+
+// SetIdleTimeout changes how long workers wait for work before exiting.
+// A timeout of zero or less keeps workers alive forever.
+func (p *Pool) SetIdleTimeout(d time.Duration) {
+	p.mu.Lock()
+	p.idleTimeout = d
+	p.mu.Unlock()
+}
+
+// SetStarvationLimit changes how many functions of a higher priority may
+// run ahead of a queued one, per level of priority they have over it.
+// Once that many have, the queued function runs before further ones of the
+// next priority level up, so low priority work is delayed but never starved
+// by a steady stream of higher priority work. A limit of zero makes the
+// pool ignore priorities.
+func (p *Pool) SetStarvationLimit(n int) {
+	if n < 0 {
+		n = 0
+	}
+	p.mu.Lock()
+	p.starve = int64(n)
+	p.mu.Unlock()
+}
+
+// Prewarm starts idle workers until the pool has at least n of them, so a
+// burst of work doesn't pay for spinning workers up. Prewarmed workers are
+// subject to the idle timeout like any other.
+func (p *Pool) Prewarm(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n > p.size {
+		n = p.size
+	}
+	for p.workers < n {
+		p.workers++
+		go p.worker()
+	}
+}
+
+// Workers returns the number of live worker goroutines.
+func (p *Pool) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.workers
+}
+
+// Submit queues f to run on a pool worker. It never blocks.
+func (p *Pool) Submit(f func()) {
+	p.SubmitPriority(f, 0)
+}
+
+// SubmitPriority queues f to run on a pool worker ahead of functions with
+// a lower priority. It never blocks, and implements PriorityScheduler.
+func (p *Pool) SubmitPriority(f func(), priority int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seq++
+	// Every function submitted later ranks one lower, so it takes a
+	// priority starve levels higher to make up for starve functions
+	// submitted in between.
+	rank := int64(priority)*p.starve - p.seq
+	heap.Push(&p.queue, poolTask{f: f, rank: rank, priority: priority, seq: p.seq})
+	switch {
+	case p.idle > 0:
+		p.idle--
+		p.wake <- struct{}{}
+	case p.workers < p.size:
+		p.workers++
+		go p.worker()
+	}
+}
+
+func (p *Pool) worker() {
+	for {
+		p.mu.Lock()
+		if len(p.queue) > 0 {
+			f := heap.Pop(&p.queue).(poolTask).f
+			p.mu.Unlock()
+			f()
+			continue
+		}
+		p.idle++
+		timeout := p.idleTimeout
+		p.mu.Unlock()
+
+		if timeout <= 0 {
+			<-p.wake
+			continue
+		}
+		timer := time.NewTimer(timeout)
+		select {
+		case <-p.wake:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+
+		p.mu.Lock()
+		select {
+		case <-p.wake:
+			// Work was handed over while the timer fired.
+			p.mu.Unlock()
+			continue
+		default:
+		}
+		p.idle--
+		p.workers--
+		p.mu.Unlock()
+		return
+	}
+}
+
+type poolTask struct {
+	f        func()
+	rank     int64
+	priority int
+	seq      int64
+}
+
+// poolQueue is a heap of queued functions, highest rank first, then
+// highest priority, then earliest submitted.
+type poolQueue []poolTask
+
+func (q poolQueue) Len() int { return len(q) }
+func (q poolQueue) Less(i, j int) bool {
+	if q[i].rank != q[j].rank {
+		return q[i].rank > q[j].rank
+	}
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+func (q poolQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *poolQueue) Push(x interface{}) { *q = append(*q, x.(poolTask)) }
+func (q *poolQueue) Pop() interface{} {
+	old := *q
+	task := old[len(old)-1]
+	old[len(old)-1] = poolTask{}
+	*q = old[:len(old)-1]
+	return task
+}
@@ -0,0 +1,85 @@
+package promise
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolRunsSubmittedWork(t *testing.T) {
+	pool := NewPool(4)
+	var wg sync.WaitGroup
+	var count int64
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		pool.Submit(func() {
+			atomic.AddInt64(&count, 1)
+			wg.Done()
+		})
+	}
+	wg.Wait()
+	require.EqualValues(t, 100, count)
+	require.True(t, pool.Workers() <= 4)
+}
+
+func TestPoolPrewarm(t *testing.T) {
+	pool := NewPool(4)
+	pool.Prewarm(3)
+	require.Equal(t, 3, pool.Workers())
+	pool.Prewarm(10)
+	require.Equal(t, 4, pool.Workers())
+}
+
+func TestPoolWorkersExitWhenIdle(t *testing.T) {
+	pool := NewPool(2)
+	pool.SetIdleTimeout(10 * time.Millisecond)
+	pool.Prewarm(2)
+	pollUntil(t, func() bool {
+		return pool.Workers() == 0
+	})
+
+	done := make(chan struct{})
+	pool.Submit(func() { close(done) })
+	<-done
+}
+
+// runOrder submits each of priorities to a single worker pool while its
+// worker is busy, and returns the indices of the functions in the order
+// they ran.
+func runOrder(pool *Pool, priorities ...int) []int {
+	block := make(chan struct{})
+	pool.Submit(func() { <-block })
+	var wg sync.WaitGroup
+	var order []int
+	for i, priority := range priorities {
+		i := i
+		wg.Add(1)
+		pool.SubmitPriority(func() {
+			order = append(order, i)
+			wg.Done()
+		}, priority)
+	}
+	close(block)
+	wg.Wait()
+	return order
+}
+
+func TestPoolRunsHigherPriorityFirst(t *testing.T) {
+	order := runOrder(NewPool(1), 0, -1, 5, 0, 5)
+	require.Equal(t, []int{2, 4, 0, 3, 1}, order)
+}
+
+func TestPoolStarvationLimit(t *testing.T) {
+	pool := NewPool(1)
+	pool.SetStarvationLimit(2)
+	// Two of the later functions a level up overtake the first, but no
+	// more, and two levels up, four do.
+	require.Equal(t, []int{1, 2, 0, 3, 4}, runOrder(pool, 0, 1, 1, 1, 1))
+	require.Equal(t, []int{1, 2, 3, 4, 0, 5}, runOrder(pool, 0, 2, 2, 2, 2, 2))
+
+	pool.SetStarvationLimit(0)
+	require.Equal(t, []int{0, 1, 2}, runOrder(pool, 0, 3, 9))
+}
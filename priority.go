@@ -0,0 +1,143 @@
+package promise
+
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+)
+
+// NewWithPriority is like New, but runs f, and the functions of promises
+// chained from the result, at the given priority, as with WithPriority.
+func NewWithPriority(priority int, f interface{}, args ...interface{}) *Promise {
+	return newWith(f, options{args: args, priority: priority})
+}
+
+// WithPriority sets the priority of p's function for schedulers that
+// implement PriorityScheduler, such as *Pool, which run functions with a
+// higher priority first when workers are scarce. The default is zero, so
+// latency-critical work can be given a positive priority and background
+// work a negative one. Promises chained from p afterwards inherit the
+// priority, the highest of them if chained from several promises. A
+// function is scheduled as soon as the promises it waits on settle, which
+// for New is right away, so WithPriority must be called before then to
+// affect p's own function; use NewWithPriority for that. It returns p for
+// chaining.
+func (p *Promise) WithPriority(priority int) *Promise {
+	p.checkCopy()
+	atomic.StoreInt32(&p.priority, int32(priority))
+	return p
+}
+
+// Priority returns the priority set by WithPriority or inherited by p.
+func (p *Promise) Priority() int {
+	return int(atomic.LoadInt32(&p.priority))
+}
+
+// inheritPriority gives p, which was just chained from parent, parent's
+// priority, unless first is false and p already has a higher one from
+// another parent.
+func (p *Promise) inheritPriority(parent *Promise, first bool) {
+	priority := atomic.LoadInt32(&parent.priority)
+	if first || priority > atomic.LoadInt32(&p.priority) {
+		atomic.StoreInt32(&p.priority, priority)
+	}
+}
+
+// ProcessByPriority calls handler once for every promise as it settles,
+// one call at a time. When several promises have settled while handler
+// was busy, the one with the highest prio is handled first, ties going to
+// the earliest in promises, rather than handling them in the order they
+// settled. The returned promise resolves once every promise was handled,
+// and is rejected if handler panics.
+func ProcessByPriority(promises []*Promise, prio func(Results) int, handler func(index int, r Results)) *Promise {
+	q := &priorityQueue{prio: prio}
+	signal := Signal()
+	if len(promises) == 0 {
+		signal.Resolve()
+		return signal.Promise()
+	}
+	for i, p := range promises {
+		i, p := i, p
+		p.observe()
+		p.whenSettled(func() {
+			if q.push(i, p.settledResults()) {
+				schedule(func() { q.drain(signal, handler, len(promises)) })
+			}
+		})
+	}
+	return signal.Promise()
+}
+
+type prioritized struct {
+	index    int
+	priority int
+	results  Results
+}
+
+// priorityQueue holds settled results waiting for the handler, highest
+// priority first.
+type priorityQueue struct {
+	prio func(Results) int
+
+	mu       sync.Mutex
+	items    []prioritized
+	draining bool
+	handled  int
+}
+
+// push queues results and reports whether the caller must start draining.
+func (q *priorityQueue) push(index int, results Results) bool {
+	item := prioritized{index: index, priority: q.prio(results), results: results}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	heap.Push((*priorityHeap)(&q.items), item)
+	if q.draining {
+		return false
+	}
+	q.draining = true
+	return true
+}
+
+// drain handles queued results until the queue is empty, and settles
+// signal once total results were handled.
+func (q *priorityQueue) drain(signal *SignalHandle, handler func(int, Results), total int) {
+	defer func() {
+		if r := recover(); r != nil {
+			signal.Reject(panicError(r))
+		}
+	}()
+	for {
+		q.mu.Lock()
+		if len(q.items) == 0 {
+			q.draining = false
+			done := q.handled == total
+			q.mu.Unlock()
+			if done {
+				signal.Resolve()
+			}
+			return
+		}
+		item := heap.Pop((*priorityHeap)(&q.items)).(prioritized)
+		q.handled++
+		q.mu.Unlock()
+		handler(item.index, item.results)
+	}
+}
+
+type priorityHeap []prioritized
+
+func (h priorityHeap) Len() int { return len(h) }
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].index < h[j].index
+}
+func (h priorityHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *priorityHeap) Push(x interface{}) { *h = append(*h, x.(prioritized)) }
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,82 @@
+package promise
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessByPriority(t *testing.T) {
+	gate := Signal()
+	promises := []*Promise{Resolved("first")}
+	for _, v := range []string{"low", "high", "medium"} {
+		v := v
+		promises = append(promises, gate.Promise().Then(func() string { return v }))
+	}
+	priorities := map[string]int{"first": 0, "low": 1, "medium": 2, "high": 3}
+
+	var handled []string
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	done := ProcessByPriority(promises, func(r Results) int {
+		return priorities[r.Values[0].(string)]
+	}, func(i int, r Results) {
+		if i == 0 {
+			// Hold the handler until every other promise has settled.
+			close(blocked)
+			<-release
+		}
+		handled = append(handled, r.Values[0].(string))
+	})
+	<-blocked
+	gate.Resolve()
+	require.NoError(t, All(promises...).Wait(new(string), new(string), new(string), new(string)))
+	close(release)
+
+	require.NoError(t, done.Wait())
+	require.Equal(t, []string{"first", "high", "medium", "low"}, handled)
+}
+
+func TestProcessByPriorityHandlerPanics(t *testing.T) {
+	done := ProcessByPriority([]*Promise{Resolved(1)}, func(Results) int { return 0 }, func(int, Results) {
+		panic("handler failed")
+	})
+	err := done.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "handler failed")
+	require.NoError(t, ProcessByPriority(nil, nil, nil).Wait())
+}
+
+func TestWithPriority(t *testing.T) {
+	pool := NewPool(1)
+	SetScheduler(pool)
+	defer SetScheduler(nil)
+	block := make(chan struct{})
+	pool.Submit(func() { <-block })
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+	bulk := NewDeferred()
+	user := NewDeferred()
+	user.Promise.WithPriority(10)
+	background := bulk.Promise.Then(record("bulk"))
+	interactive := user.Promise.Then(record("user"))
+	require.Equal(t, 10, interactive.Priority())
+	both := All(bulk.Promise, user.Promise).Then(record("both"))
+	require.Equal(t, 10, both.Priority())
+	root := NewWithPriority(5, record("root"))
+
+	bulk.Resolve()
+	user.Resolve()
+	close(block)
+	require.NoError(t, All(background, interactive, both, root).Wait())
+	require.Equal(t, []string{"user", "both", "root", "bulk"}, order)
+}
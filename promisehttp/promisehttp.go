@@ -0,0 +1,114 @@
+// Package promisehttp makes HTTP requests with promises. Its promises
+// close response bodies that nobody will read, such as when a request is
+// canceled after its response arrived, and cancel requests whose promises
+// are canceled:
+//
+//	pages := make([]*promise.PromiseT[[]byte], len(urls))
+//	for i, url := range urls {
+//		pages[i] = promisehttp.ReadBody(promisehttp.Get(nil, url))
+//	}
+//	bodies, err := promise.AllOf(pages...).Wait()
+package promisehttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	promise "github.com/garlicnation/promises/v2"
+)
+
+// Get returns a promise for the response to a GET request for url, sent
+// with client. A nil client means http.DefaultClient.
+func Get(client *http.Client, url string) *promise.PromiseT[*http.Response] {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return promise.NewT(func() (*http.Response, error) {
+			return nil, err
+		})
+	}
+	return Do(client, req)
+}
+
+// Do returns a promise for the response to req, sent with client. A nil
+// client means http.DefaultClient. Canceling the promise cancels the
+// request, as does canceling req's context. Once the promise resolves,
+// the caller owns the response body and must close it, or pass the
+// promise to ReadBody. If the promise is rejected, as by Cancel, after
+// the response arrived, the body is closed for it.
+func Do(client *http.Client, req *http.Request) *promise.PromiseT[*http.Response] {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	var mu sync.Mutex
+	var handed *http.Response
+	abandoned := false
+	p := promise.New(func() (*http.Response, error) {
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		resp.Body = &body{ReadCloser: resp.Body, cancel: cancel}
+		mu.Lock()
+		defer mu.Unlock()
+		if abandoned {
+			resp.Body.Close()
+			return nil, promise.ErrCanceled
+		}
+		handed = resp
+		return resp, nil
+	})
+	p.Defer(func() {
+		if p.State() == promise.StateFulfilled {
+			return
+		}
+		mu.Lock()
+		abandoned = true
+		resp := handed
+		mu.Unlock()
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
+	})
+	return promise.Typed[*http.Response](p)
+}
+
+// ReadBody returns a promise for the body of the response p resolves
+// with, which it closes. If the returned promise is canceled before the
+// body is read, the body is closed unread.
+func ReadBody(p *promise.PromiseT[*http.Response]) *promise.PromiseT[[]byte] {
+	read := promise.ThenOf(p, func(resp *http.Response) ([]byte, error) {
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	})
+	read.Promise().Defer(func() {
+		if p.Promise().State() != promise.StateFulfilled {
+			return
+		}
+		// A no-op if the body was read.
+		resp, _ := p.Wait()
+		resp.Body.Close()
+	})
+	return read
+}
+
+// A body is a response body that releases the request's context once it
+// is closed.
+type body struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	once   sync.Once
+	err    error
+}
+
+func (b *body) Close() error {
+	b.once.Do(func() {
+		b.err = b.ReadCloser.Close()
+		b.cancel()
+	})
+	return b.err
+}
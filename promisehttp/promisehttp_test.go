@@ -0,0 +1,82 @@
+package promisehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	promise "github.com/garlicnation/promises/v2"
+)
+
+func TestGetReadBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello "+r.URL.Path)
+	}))
+	defer server.Close()
+	body, err := ReadBody(Get(server.Client(), server.URL+"/world")).Wait()
+	require.NoError(t, err)
+	require.Equal(t, "hello /world", string(body))
+}
+
+func TestGetBadURL(t *testing.T) {
+	_, err := Get(nil, "://nope").Wait()
+	require.Error(t, err)
+}
+
+func TestCancelAbortsRequest(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	p := Get(server.Client(), server.URL)
+	p.Promise().Cancel()
+	_, err := p.Wait()
+	require.True(t, errors.Is(err, promise.ErrCanceled))
+}
+
+// closeTracker is a body that records whether it was closed.
+type closeTracker struct {
+	io.Reader
+	closed int32
+}
+
+func (b *closeTracker) Close() error {
+	atomic.StoreInt32(&b.closed, 1)
+	return nil
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestReadBodyClosesUnreadBody(t *testing.T) {
+	tracker := &closeTracker{Reader: strings.NewReader("unread")}
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: tracker, Request: req}, nil
+	})}
+	s := promise.NewManualScheduler()
+	promise.SetScheduler(s)
+	defer promise.SetScheduler(nil)
+	resp := Get(client, "http://example.com")
+	s.RunUntilIdle()
+	_, err := resp.Wait()
+	require.NoError(t, err)
+
+	// The read is queued but never run.
+	read := ReadBody(resp)
+	read.Promise().Cancel()
+	require.Equal(t, int32(1), atomic.LoadInt32(&tracker.closed))
+}
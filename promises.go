@@ -1,12 +1,17 @@
 package promise
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+	"unsafe"
 
-	"github.com/pkg/errors"
+	"github.com/garlicnation/promises/v2/internal/core"
 )
 
 type promiseType int
@@ -17,25 +22,143 @@ const (
 	allCall
 	raceCall
 	anyCall
+	signalCall
+	catchCall
+	finallyCall
 )
 
 // A Promise represents an asynchronously executing unit of work
 type Promise struct {
-	complete   bool
+	// name identifies the promise to hooks such as the fault injector
+	name string
+	// signal records whether the promise has settled, and holds the
+	// continuations waiting on it. It is guarded by mu, except for its
+	// state, which is read without holding mu.
+	signal     core.Signal
 	err        error
 	t          promiseType
-	functionRv reflect.Value
 	results    []reflect.Value
 	resultType []reflect.Type
-	anyErrs    []error
+	// argTypes, if set, are the types results of the prior promise are
+	// converted to before calling a Then function
+	argTypes []reflect.Type
+	// conversions is set by WithConversions
+	conversions int32
+	// flatten is set for a Then whose function returns a promise, which
+	// the Then settles like
+	flatten bool
+	// dynamic is set if resultType is only known once the promise
+	// settles, as for one flattening an untyped *Promise
+	dynamic bool
+	// priority is set by WithPriority, or inherited from the promises
+	// this one is chained from
+	priority int32
 	// returnsError is true if the last value returns an error
 	returnsError bool
-	cond         sync.Cond
-	counter      int64
-	errCounter   int64
+	// joinErrors is set when that last value is a []error, as for
+	// functions wrapped with JoinErrors
+	joinErrors bool
+	// named is set when name was given by NewNamed or WithName rather
+	// than taken from the function
+	named bool
+	// mu guards the fields of the promise that change after it is
+	// created
+	mu sync.Mutex
+	// sliceType is set for an All whose inputs each resolve with one value
+	// of the same type, and slice holds those values once it resolves. It
+	// is anySliceType for an All of no promises.
+	sliceType reflect.Type
+	slice     reflect.Value
+	// call, if set, calls the promise's function in place of reflection
+	call thunk
+	// ctx, if set, rejects the promise when it is done
+	ctx context.Context
+	// created and settled bound the lifetime of the promise, and started
+	// is when its function was called
+	created time.Time
+	started time.Time
+	settled time.Time
+	counter int64
+	// self is the address the promise was created at, to detect copies.
+	// It is not a pointer so that it doesn't keep the promise reachable.
+	self uintptr
+	// tracked is set for promises created in debug mode, which
+	// DumpPending lists
+	tracked bool
+	// graph is the graph the promise belongs to, and parents the promises
+	// it was chained from
+	graph    *Graph
+	parents  []*Promise
+	children []*Promise
+	// binding, if set, gathers the prior's results into the fields of the
+	// struct the function accepts
+	binding *structBinding
+	// direct is set for continuations run on the goroutine that settles
+	// their prior, as by ThenDirect
+	direct bool
+	// waiting counts the calls blocked waiting for the promise
+	waiting int32
+	// observed is set once anything waits on or chains from the promise
+	observed int32
+	// extra, a *extra, holds the fields only some promises use
+	extra unsafe.Pointer
+	// pooled is set for promises taken from the pool, which go back to it
+	// once refs drops to zero, unless pinned. released is set by Release,
+	// and generation counts the times the promise has been recycled.
+	pooled     bool
+	refs       int32
+	pinned     int32
+	released   int32
+	generation uint32
 	noCopy
 }
 
+// newPromise returns an unsettled promise of type t.
+func newPromise(t promiseType, name string) *Promise {
+	return initPromise(&Promise{}, t, name)
+}
+
+// initPromise sets up p, a zero Promise, as an unsettled promise of type
+// t. It must be called by newPromise or newPooledPromise.
+func initPromise(p *Promise, t promiseType, name string) *Promise {
+	p.name = name
+	p.t = t
+	p.created = currentTime()
+	p.self = uintptr(unsafe.Pointer(p))
+	if debugging() {
+		p.recordStack()
+	}
+	trackPending(p)
+	metricsCreated()
+	callHooks(p, hookCreate)
+	return p
+}
+
+// checkCopy panics if p is a copy of a promise rather than the promise
+// itself. Waiters on a copy would block on a different cond than the one
+// the running promise broadcasts on, and deadlock.
+func (p *Promise) checkCopy() {
+	if p.signal.State() == core.Retired {
+		panic(errUsedAfterRelease)
+	}
+	if p.self == 0 {
+		panic("promise: zero Promise used; create promises with New or another constructor")
+	}
+	if p.self != uintptr(unsafe.Pointer(p)) {
+		panic("promise: Promise value was copied; use *Promise instead")
+	}
+}
+
+// observe records that something is consuming the outcome of p, so its
+// rejection is not reported as unhandled.
+func (p *Promise) observe() {
+	p.checkCopy()
+	atomic.StoreInt32(&p.observed, 1)
+	if t, _ := p.peekExtras().rejection.Load().(*rejectionTracker); t != nil {
+		t.handle()
+	}
+}
+
 // Used to trigger lint rules if a promise is copied
 type noCopy struct{}
 
@@ -44,32 +167,27 @@ func (*noCopy) Unlock() {}
 
 func (p *Promise) raceCall(priors []*Promise, index int) (results []reflect.Value) {
 	prior := priors[index]
-	prior.cond.L.Lock()
-	for !prior.complete {
-		prior.cond.Wait()
-	}
-	prior.cond.L.Unlock()
+	prior.await()
 	if prior.err != nil {
-		panic(errors.Wrap(prior.err, "error encountered in promise"))
+		panic(rejection{fmt.Errorf("error encountered in promise: %w", prior.err)})
 	}
 	remaining := atomic.AddInt64(&p.counter, -1)
 	if remaining == 0 {
-		return prior.results[:]
+		return append([]reflect.Value{}, prior.results...)
 	}
 	return nil
 }
 
 func (p *Promise) allCall(priors []*Promise, index int) (results []reflect.Value) {
 	prior := priors[index]
-	prior.cond.L.Lock()
-	for !prior.complete {
-		prior.cond.Wait()
-	}
-	prior.cond.L.Unlock()
+	prior.await()
 	if prior.err != nil {
-		panic(errors.Wrap(prior.err, "error encountered in promise"))
+		panic(rejection{fmt.Errorf("error encountered in promise: %w", prior.err)})
 	}
 	remaining := atomic.AddInt64(&p.counter, -1)
+	if remaining == 0 && p.sliceType != nil {
+		return p.collectSlice(priors)
+	}
 	if remaining == 0 {
 		size := 0
 		for i := range priors {
@@ -84,52 +202,84 @@ func (p *Promise) allCall(priors []*Promise, index int) (results []reflect.Value
 	return nil
 }
 
-// AnyErr returns when all promises passed to Any fail
-type AnyErr struct {
-	// Errs contains the error of all passed promises
+// AggregateError rejects the promise returned by Any when all of the
+// passed promises fail, like JavaScript's AggregateError, and the promise
+// returned by AllCollect when any of them fails.
+type AggregateError struct {
+	// Errs contains the error of all passed promises, in the order they
+	// were passed, with nil for those that resolved
 	Errs []error
 	// LastErr contains the error of the last promise to fail.
 	LastErr error
 }
 
-func (err *AnyErr) Error() string {
-	return fmt.Sprintf("all %d promises failed. last err=%v", len(err.Errs), err.LastErr)
+// AnyErr is the former name of AggregateError.
+//
+// Deprecated: Use AggregateError.
+type AnyErr = AggregateError
+
+func (err *AggregateError) Error() string {
+	if len(err.Errs) == 0 {
+		return "all promises failed: no promises were passed"
+	}
+	msgs := make([]string, 0, len(err.Errs))
+	for i, e := range err.Errs {
+		if e != nil {
+			msgs = append(msgs, fmt.Sprintf("promise %d: %v", i, e))
+		}
+	}
+	if len(msgs) < len(err.Errs) {
+		return fmt.Sprintf("%d of %d promises failed: %s", len(msgs), len(err.Errs), strings.Join(msgs, "; "))
+	}
+	return fmt.Sprintf("all %d promises failed: %s", len(err.Errs), strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the individual failures matches target.
+func (err *AggregateError) Is(target error) bool {
+	for _, e := range err.Errs {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *Promise) anyCall(priors []*Promise, index int) (results []reflect.Value) {
 	prior := priors[index]
-	prior.cond.L.Lock()
-	for !prior.complete {
-		prior.cond.Wait()
-	}
-	prior.cond.L.Unlock()
+	prior.await()
 	if prior.err != nil {
-		remaining := atomic.AddInt64(&p.errCounter, -1)
-		p.anyErrs[index] = prior.err
+		x := p.peekExtras()
+		remaining := atomic.AddInt64(&x.errCounter, -1)
+		x.anyErrs[index] = prior.err
 		if remaining != 0 {
 			return nil
 		}
-		panic(AnyErr{Errs: p.anyErrs[:], LastErr: prior.err})
+		panic(rejection{&AggregateError{Errs: x.anyErrs[:], LastErr: prior.err}})
 	}
 	remaining := atomic.AddInt64(&p.counter, -1)
 	if remaining == 0 {
-		return prior.results[:]
+		return append([]reflect.Value{}, prior.results...)
 	}
 	return nil
 }
 
 func empty() {}
 
+// anySliceType is the sliceType of an All of no promises.
+var anySliceType = reflect.TypeOf([]interface{}(nil))
+
 // All returns a promise that resolves if all of the passed promises
-// succeed or fails if any of the passed promises panics.
+// succeed or fails if any of the passed promises panics. Use AllCollect
+// to see every failure rather than only the first. All of no promises
+// resolves with no values, which Wait also takes as an empty slice of any
+// type.
 func All(promises ...*Promise) *Promise {
 	if len(promises) == 0 {
-		return New(empty)
-	}
-	p := &Promise{
-		cond: sync.Cond{L: &sync.Mutex{}},
-		t:    allCall,
+		p := New(empty)
+		p.sliceType = anySliceType
+		return p
 	}
+	p := newPooledPromise(allCall, "All")
 
 	// Extract the type
 	p.resultType = []reflect.Type{}
@@ -137,10 +287,16 @@ func All(promises ...*Promise) *Promise {
 		p.resultType = append(p.resultType, prior.resultType...)
 	}
 
+	p.sliceType = homogeneousSliceType(promises)
 	p.counter = int64(len(promises))
 
-	for i := range promises {
-		go p.run(reflect.Value{}, nil, promises, i, nil)
+	for _, prior := range promises {
+		prior.observe()
+		prior.graph.addChild(prior, p)
+	}
+	p.watchContexts(promises)
+	for i, prior := range promises {
+		p.runAfter(reflect.Value{}, nil, promises, i, prior)
 	}
 	return p
 }
@@ -148,7 +304,9 @@ func All(promises ...*Promise) *Promise {
 const anyErrorFormat = "promise %d has an unexpected return type, expected all promises passed to Any to return the same type"
 
 // Race returns a promise that resolves if any of the passed promises
-// succeed or fails if any of the passed promises panics.
+// succeed or fails if any of the passed promises panics. Once it settles,
+// the passed promises still pending are canceled, as if by Cancel, unless
+// other promises are chained from them.
 // All of the supplied promises must be of the same type.
 func Race(promises ...*Promise) *Promise {
 	if len(promises) == 0 {
@@ -164,41 +322,48 @@ func Race(promises ...*Promise) *Promise {
 	for promiseIdx, promise := range promises[1:] {
 		newResultType := promise.resultType
 		if len(firstResultType) != len(newResultType) {
-			panic(errors.Errorf(anyErrorFormat, promiseIdx))
+			panic(fmt.Errorf(anyErrorFormat, promiseIdx))
 		}
 		for index := range firstResultType {
 			if firstResultType[index] != newResultType[index] {
-				panic(errors.Errorf(anyErrorFormat, promiseIdx))
+				panic(fmt.Errorf(anyErrorFormat, promiseIdx))
 			}
 		}
 	}
 
-	p := &Promise{
-		cond: sync.Cond{L: &sync.Mutex{}},
-		t:    raceCall,
-	}
+	p := newPooledPromise(raceCall, "Race")
 
 	// Extract the type
 	p.resultType = firstResultType[:]
 
 	p.counter = int64(1)
 
-	for i := range promises {
-		go p.run(reflect.Value{}, nil, promises, i, nil)
+	for _, prior := range promises {
+		prior.observe()
+		prior.graph.addChild(prior, p)
+	}
+	p.watchContexts(promises)
+	for i, prior := range promises {
+		p.runAfter(reflect.Value{}, nil, promises, i, prior)
 	}
+	p.cancelOnSettle(promises)
 	return p
 }
 
-// Any returns a promise that resolves if any of the passed promises
-// succeed or fails if all of the passed promises panics.
+// Any returns a promise that resolves with the result of the first of the
+// passed promises to succeed, or rejects with an *AggregateError once all
+// of them have failed. Like JavaScript's Promise.any, it rejects when no
+// promises are passed. Once it resolves, the passed promises still pending
+// are canceled, as if by Cancel, unless other promises are chained from
+// them.
 // All of the supplied promises must be of the same type.
 func Any(promises ...*Promise) *Promise {
 	if len(promises) == 0 {
-		return New(empty)
-	}
-
-	if len(promises) == 1 {
-		return promises[0]
+		p := newPooledPromise(anyCall, "Any")
+		p.resultType = []reflect.Type{}
+		newGraph(p)
+		p.settle(nil, &AggregateError{})
+		return p
 	}
 
 	// Check that all the promises have the same return type
@@ -206,30 +371,34 @@ func Any(promises ...*Promise) *Promise {
 	for promiseIdx, promise := range promises[1:] {
 		newResultType := promise.resultType
 		if len(firstResultType) != len(newResultType) {
-			panic(errors.Errorf(anyErrorFormat, promiseIdx))
+			panic(fmt.Errorf(anyErrorFormat, promiseIdx))
 		}
 		for index := range firstResultType {
 			if firstResultType[index] != newResultType[index] {
-				panic(errors.Errorf(anyErrorFormat, promiseIdx))
+				panic(fmt.Errorf(anyErrorFormat, promiseIdx))
 			}
 		}
 	}
 
-	p := &Promise{
-		cond:    sync.Cond{L: &sync.Mutex{}},
-		t:       anyCall,
-		anyErrs: make([]error, len(promises)),
-	}
+	p := newPooledPromise(anyCall, "Any")
+	x := p.extras()
+	x.anyErrs = make([]error, len(promises))
 
 	// Extract the type
 	p.resultType = firstResultType[:]
 
 	p.counter = int64(1)
-	p.errCounter = int64(len(promises))
+	x.errCounter = int64(len(promises))
 
-	for i := range promises {
-		go p.run(reflect.Value{}, nil, promises, i, nil)
+	for _, prior := range promises {
+		prior.observe()
+		prior.graph.addChild(prior, p)
 	}
+	p.watchContexts(promises)
+	for i, prior := range promises {
+		p.runAfter(reflect.Value{}, nil, promises, i, prior)
+	}
+	p.cancelOnSettle(promises)
 	return p
 }
 
@@ -253,103 +422,225 @@ func getResultType(outFunc reflect.Type) (resultType []reflect.Type, returnsErro
 
 // New returns a promise that resolves when f completes. Any panic()
 // encountered will be returned as an error from Wait()
+//
+// args are passed to f. If f is variadic, any number of them, including
+// none, fill its variadic parameter, as in New(fmt.Sprintf, "x=%d", 5),
+// and a single slice of the parameter's type is spread over it.
 func New(f interface{}, args ...interface{}) *Promise {
-	// Extract the type
-	p := &Promise{
-		cond: sync.Cond{L: new(sync.Mutex)},
-		t:    simpleCall,
-	}
+	return newWith(f, options{args: args})
+}
+
+// newCall returns a promise that calls f with the leading values followed
+// by args once start is called. Leading values are supplied by the
+// package, such as the context passed by NewCtx, and their types are
+// checked by the caller.
+func newCall(f interface{}, leading []reflect.Value, args []interface{}) (p *Promise, start func()) {
+	return newCaller(f).newCall(leading, args)
+}
 
-	functionRv := reflect.ValueOf(f)
+// A caller creates promises that call a function, whose signature it
+// looks up once.
+type caller struct {
+	functionRv reflect.Value
+	joinErrors bool
+	name       string
+	sig        *signature
+	call       thunk
+	// strict, if set, makes args that don't match the function panic
+	// even with strict mode off.
+	strict bool
+}
 
+func newCaller(f interface{}) *caller {
+	functionRv, joinErrors := funcValue(f)
 	if functionRv.Kind() != reflect.Func {
-		panic(errors.Errorf("expected Function, got %s", functionRv.Kind()))
+		panic(fmt.Errorf("expected Function, got %s", functionRv.Kind()))
 	}
-
-	reflectType := functionRv.Type()
-
-	inputs := []reflect.Type{}
-	for i := 0; i < reflectType.NumIn(); i++ {
-		inputs = append(inputs, reflectType.In(i))
+	return &caller{
+		functionRv: functionRv,
+		joinErrors: joinErrors,
+		name:       funcName(functionRv),
+		sig:        signatureOf(functionRv.Type()),
+		call:       thunkOf(functionRv),
 	}
+}
 
-	if len(args) != len(inputs) {
-		panic(errors.Errorf("expected %d args, got %d args", len(inputs), len(args)))
+// resultType returns the types of the results of c's promises.
+func (c *caller) resultType() []reflect.Type {
+	if c.joinErrors {
+		return c.sig.resultType[:len(c.sig.resultType)-1]
 	}
+	return c.sig.resultType
+}
 
-	p.resultType, p.returnsError = getResultType(reflectType)
+// newCall is like the package's newCall for the function of c.
+func (c *caller) newCall(leading []reflect.Value, args []interface{}) (p *Promise, start func()) {
+	p = newPooledPromise(simpleCall, c.name)
+	defer p.untrackOnPanic()
+	newGraph(p)
 
-	argValues := []reflect.Value{}
+	inputs := c.sig.in[len(leading):]
 
-	for i := 0; i < len(args); i++ {
-		providedArgRv := reflect.ValueOf(args[i])
-		providedArgType := providedArgRv.Type()
-		if providedArgType != inputs[i] {
-			panic(errors.Errorf("for argument %d: expected type %s got type %s", i, inputs[i], providedArgType))
-		}
-		argValues = append(argValues, providedArgRv)
+	p.resultType, p.returnsError = c.sig.resultType, c.sig.returnsError
+	p.call = c.call
+	if c.joinErrors {
+		p.resultType, p.returnsError, p.joinErrors = p.resultType[:len(p.resultType)-1], true, true
+	}
+
+	argValues := append([]reflect.Value{}, leading...)
+	argValues = append(argValues, callArgs(inputs, c.functionRv.Type().IsVariadic(), args, c.strict || strictChecks())...)
+	functionRv := c.functionRv
+	return p, func() {
+		p.acquire()
+		scheduleOn(p.peekExtras().executor, p.Priority(), func() {
+			defer p.releaseRef()
+			p.run(functionRv, nil, nil, 0, argValues)
+		})
 	}
-	go p.run(functionRv, nil, nil, 0, argValues)
-	return p
 }
 
 func (p *Promise) simpleCall(functionRv reflect.Value, argValues []reflect.Value) []reflect.Value {
-	return functionRv.Call(argValues)
+	defer p.startTimeout()()
+	p.markStarted()
+	injectFault(p)
+	return p.callFunc(functionRv, argValues)
 }
 
-func (p *Promise) thenCall(prior *Promise, functionRv reflect.Value) []reflect.Value {
-	prior.cond.L.Lock()
-	for !prior.complete {
-		prior.cond.Wait()
+// thenCall waits for prior and calls functionRv with its results. It
+// reports false if p settled before the function could be called.
+func (p *Promise) thenCall(prior *Promise, functionRv reflect.Value) ([]reflect.Value, bool) {
+	prior.await()
+	p.awaitSerialPrev()
+	if prior.err != nil {
+		if p.peekExtras().onRejected.IsValid() {
+			return p.onRejectedCall(prior.err)
+		}
+		panic(rejection{prior.err})
 	}
-	prior.cond.L.Unlock()
-	if p.err != nil {
-		panic(errors.Wrap(p.err, "error in previous promise"))
+	if p.isSettled() {
+		// Rejected while waiting, for example by its context.
+		return nil, false
 	}
-	if prior.err != nil {
-		panic(prior.err)
+	if prior.dynamic {
+		var err error
+		p.binding, p.argTypes, err = thenArgs(functionRv.Type(), signatureOf(functionRv.Type()).in, prior.resultType, prior.accepts)
+		if err != nil {
+			panic(err)
+		}
+	}
+	defer p.startTimeout()()
+	p.markStarted()
+	injectFault(p)
+	if atomic.LoadInt32(&prior.peekExtras().discarded) != 0 {
+		panic(rejection{ErrResultsDiscarded})
 	}
-	results := functionRv.Call(prior.results)
-	return results
+	if prior.slice.IsValid() && functionRv.Type().IsVariadic() && functionRv.Type().NumIn() == 1 && functionRv.Type().In(0) == prior.sliceType {
+		return functionRv.CallSlice([]reflect.Value{copySlice(prior.slice)}), true
+	}
+	args := prior.results
+	if p.binding != nil {
+		args = []reflect.Value{p.binding.bind(prior.results)}
+	}
+	if p.argTypes != nil {
+		args = make([]reflect.Value, len(prior.results))
+		for i, result := range prior.results {
+			args[i] = convertValue(result, p.argTypes[i])
+		}
+	}
+	results := p.callFunc(functionRv, args)
+	return results, true
 }
 
-// Then returns a promise that begins execution when this Promise completes
-func (p *Promise) Then(f interface{}) *Promise {
-	// Extract the type
-	next := &Promise{
-		cond: sync.Cond{L: &sync.Mutex{}},
-		t:    thenCall,
+// Then returns a promise that begins execution when this Promise completes.
+// f is called with p's results, so its parameters may be of any types the
+// results are assignable to, such as interfaces they implement.
+// Continuations attached to the same promise with Then run concurrently,
+// in no particular order; use ThenSerial when they depend on each other.
+// If f returns a *Promise or *PromiseT, the returned promise settles like
+// that promise instead of resolving with it. opts, such as StageTimeout,
+// configure the returned promise.
+func (p *Promise) Then(f interface{}, opts ...ThenOption) *Promise {
+	if len(opts) == 0 {
+		return p.then(f, nil)
 	}
+	return p.then(f, func(next *Promise) {
+		for _, opt := range opts {
+			opt(next)
+		}
+	})
+}
 
-	functionRv := reflect.ValueOf(f)
+// then implements Then. If setup is non-nil, it is called to configure the
+// chained promise before it starts.
+func (p *Promise) then(f interface{}, setup func(next *Promise)) *Promise {
+	return p.thenChecked(f, setup, strictChecks())
+}
+
+// thenChecked is then, except that if f can't accept p's results, it
+// panics if strict and otherwise returns a promise rejected with the
+// error.
+func (p *Promise) thenChecked(f interface{}, setup func(next *Promise), strict bool) *Promise {
+	// Extract the type
+	p.checkCopy()
+	p.observe()
+	functionRv, joinErrors := funcValue(f)
 
 	if functionRv.Kind() != reflect.Func {
-		panic(errors.Errorf("expected Function, got %v", functionRv.Kind()))
+		panic(fmt.Errorf("expected Function, got %v", functionRv.Kind()))
 	}
 
+	next := newPooledPromise(thenCall, funcName(functionRv))
+	defer next.untrackOnPanic()
+	next.call = thunkOf(functionRv)
 	reflectType := functionRv.Type()
 
-	inputs := []reflect.Type{}
-	for i := 0; i < reflectType.NumIn(); i++ {
-		inputs = append(inputs, reflectType.In(i))
-	}
-	outputs := []reflect.Type{}
-	for i := 0; i < reflectType.NumOut(); i++ {
-		outputs = append(outputs, reflectType.Out(i))
+	sig := signatureOf(reflectType)
+
+	next.resultType, next.returnsError = sig.resultType, sig.returnsError
+	if joinErrors {
+		next.resultType, next.returnsError, next.joinErrors = next.resultType[:len(next.resultType)-1], true, true
 	}
 
-	next.resultType, next.returnsError = getResultType(reflectType)
+	next.flatten, next.resultType, next.dynamic = flattenType(next.resultType)
+	if !p.dynamic {
+		// Otherwise checked by thenCall once p's types are known.
+		var err error
+		next.binding, next.argTypes, err = thenArgs(reflectType, sig.in, p.resultType, p.accepts)
+		if err != nil {
+			if strict {
+				panic(err)
+			}
+			// f is never called, but next is still p's child.
+			p.graph.addChild(p, next)
+			next.settle(nil, err)
+			return next
+		}
+	}
+	if setup != nil {
+		setup(next)
+	}
+	p.chain(next, functionRv)
+	return next
+}
 
+// thenArgs checks that a function of type fnType, taking inputs, can be
+// called with results, as by Then, and returns an error if not. It returns
+// the struct binding the call needs, or the types to convert results to,
+// if either is needed.
+func thenArgs(fnType reflect.Type, inputs, results []reflect.Type, accepts func(result, dest reflect.Type) bool) (*structBinding, []reflect.Type, error) {
 	// Check for variadic function
-	if reflectType.IsVariadic() {
+	if fnType.IsVariadic() {
 		// If it's variadic, adjust the inputs to match if possible
-		argDiff := len(p.resultType) - len(inputs)
+		inputs = append([]reflect.Type{}, inputs...)
+		argDiff := len(results) - len(inputs)
 		switch {
 		case argDiff == -1:
 			// Skipping the variadic arg
 			// TODO: better error message fo r variadic args
 			inputs = inputs[:len(inputs)-1]
-		case argDiff > 0:
+		case argDiff > 0, argDiff == 0 && results[len(results)-1] != inputs[len(inputs)-1]:
+			// A single result is also passed as one variadic element
+			// unless it is already the variadic slice type.
 			var variadic reflect.Type
 			variadic, inputs = inputs[len(inputs)-1], inputs[:len(inputs)-1]
 			for i := 0; i <= argDiff; i++ {
@@ -359,43 +650,140 @@ func (p *Promise) Then(f interface{}) *Promise {
 		}
 	}
 
-	if len(inputs) != len(p.resultType) {
-		panic(errors.Errorf("promise returns %d values, but provided function accepts %d args", len(p.resultType), len(inputs)))
+	if bindsStruct(fnType, results) {
+		return newStructBinding(fnType.In(0), results), nil, nil
+	}
+	if len(inputs) != len(results) {
+		return nil, nil, fmt.Errorf("promise returns %d values, but provided function accepts %d args", len(results), len(inputs))
 	}
 
-	for i := 0; i < len(p.resultType); i++ {
-		if inputs[i] != p.resultType[i] {
-			panic(errors.Errorf("for argument %d: expected type %s got type %s", i, p.resultType[i], inputs[i]))
+	var argTypes []reflect.Type
+	for i := 0; i < len(results); i++ {
+		if results[i].AssignableTo(inputs[i]) {
+			continue
+		}
+		if !accepts(results[i], inputs[i]) {
+			return nil, nil, fmt.Errorf("for argument %d: expected type %s got type %s", i, results[i], inputs[i])
 		}
+		argTypes = inputs
 	}
-	go next.run(functionRv, p, nil, 0, nil)
-	return next
+	return nil, argTypes, nil
+}
+
+// chain starts next, which calls functionRv once p settles.
+func (p *Promise) chain(next *Promise, functionRv reflect.Value) {
+	p.graph.addChild(p, next)
+	if next.t == finallyCall {
+		// Finally is rejected with the context's error through p once
+		// its function has run, rather than straight away.
+		next.ctx = p.ctx
+	} else {
+		next.watchContext(p.ctx)
+	}
+	if prev := next.peekExtras().serialPrev; prev != nil {
+		p.whenSettled(func() {
+			next.runAfter(functionRv, p, nil, 0, prev)
+		})
+		return
+	}
+	next.runAfter(functionRv, p, nil, 0, p)
+}
+
+// runAfter schedules p.run once after has settled, so that no goroutine
+// or worker is tied up waiting for it. Combinators and continuations
+// handed off directly run on the goroutine that settled after instead.
+func (p *Promise) runAfter(functionRv reflect.Value, prior *Promise, priors []*Promise, index int, after *Promise) {
+	p.acquire()
+	if !functionRv.IsValid() {
+		// Combinators have no function and only tally their inputs.
+		p.watchInput(after, func() {
+			defer p.releaseRef()
+			p.run(functionRv, prior, priors, index, nil)
+		})
+		return
+	}
+	after.whenSettled(func() {
+		if p.direct || isCheap(functionRv) {
+			defer p.releaseRef()
+			p.run(functionRv, prior, priors, index, nil)
+			return
+		}
+		scheduleAt(p.Priority(), func() {
+			defer p.releaseRef()
+			p.run(functionRv, prior, priors, index, nil)
+		})
+	})
+}
+
+// whenSettled calls f once p has settled, right away if it already has.
+// f must not block.
+func (p *Promise) whenSettled(f func()) {
+	p.addContinuation(f)
+}
+
+// addContinuation is like whenSettled, but returns the continuation if it
+// was queued rather than called right away, for removeContinuation.
+func (p *Promise) addContinuation(f func()) *core.Callback {
+	p.mu.Lock()
+	c := p.signal.Add(f)
+	p.mu.Unlock()
+	if c == nil {
+		f()
+	}
+	return c
+}
+
+// removeContinuation drops c, if p hasn't settled and called it yet.
+func (p *Promise) removeContinuation(c *core.Callback) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.signal.Remove(c)
 }
 
 func (p *Promise) run(functionRv reflect.Value, prior *Promise, priors []*Promise, index int, args []reflect.Value) {
 	// Catch panics
 	defer func() {
 		if r := recover(); r != nil {
-			err, ok := r.(error)
-			if !ok {
-				err = errors.Errorf("%+v", r)
+			if !p.recovers(r) {
+				panic(r)
 			}
-			p.cond.L.Lock()
-			defer p.cond.L.Unlock()
-			if p.complete {
-				return
-			}
-			p.err = err
-			p.complete = true
-			p.cond.Broadcast()
+			p.settle(nil, panicError(r))
 		}
 	}()
+	if functionRv.IsValid() {
+		defer p.shed()
+	}
+	if p.isSettled() {
+		return
+	}
+	if functionRv.IsValid() {
+		atomic.AddInt64(&runningCount, 1)
+		defer atomic.AddInt64(&runningCount, -1)
+		if p.tracked {
+			defer p.trackGoroutine()()
+		}
+	}
 	var results []reflect.Value
 	switch p.t {
 	case simpleCall:
 		results = p.simpleCall(functionRv, args)
 	case thenCall:
-		results = p.thenCall(prior, functionRv)
+		var ok bool
+		done := measureCost(functionRv)
+		results, ok = p.thenCall(prior, functionRv)
+		done()
+		if !ok {
+			return
+		}
+	case catchCall:
+		var ok bool
+		results, ok = p.catchCall(prior, functionRv)
+		if !ok {
+			return
+		}
+	case finallyCall:
+		p.finallyCall(prior, functionRv)
+		return
 	case allCall:
 		results = p.allCall(priors, index)
 		if results == nil {
@@ -408,38 +796,131 @@ func (p *Promise) run(functionRv reflect.Value, prior *Promise, priors []*Promis
 		}
 	case raceCall:
 		results = p.raceCall(priors, index)
+		if results == nil {
+			return
+		}
 	default:
 		panic("unexpected call type")
 	}
-	p.cond.L.Lock()
-	defer p.cond.L.Unlock()
+	var err error
 	if p.returnsError {
 		var lastResult reflect.Value
 		lastResult, results = results[len(results)-1], results[:len(results)-1]
-		if !lastResult.IsNil() {
-			err, ok := lastResult.Interface().(error)
+		if p.joinErrors {
+			err = errorListOf(lastResult.Interface().([]error))
+		} else if !lastResult.IsNil() {
+			var ok bool
+			err, ok = lastResult.Interface().(error)
 			if !ok {
 				panic("Expected to find error")
 			}
-			p.err = err
 		}
 	}
-	p.complete = true
+	if p.flatten && err == nil {
+		p.adopt(results[0])
+		return
+	}
+	p.settle(results, err)
+}
+
+// shed drops the state p only needs to call its function, once it has
+// been called or never will be, so that settled promises kept around, as
+// by a cache, don't keep the closures they were created with reachable.
+// Only the goroutine running p reads that state.
+func (p *Promise) shed() {
+	p.call = nil
+	p.binding = nil
+	p.argTypes = nil
+	if x := (*extra)(atomic.LoadPointer(&p.extra)); x != nil {
+		x.onRejected = reflect.Value{}
+		x.executor = nil
+	}
+}
+
+// await blocks until p has settled.
+func (p *Promise) await() {
+	if p.isSettled() {
+		return
+	}
+	<-p.doneChan()
+}
+
+// isSettled reports whether p has settled, without blocking.
+func (p *Promise) isSettled() bool {
+	return !p.signal.Pending()
+}
+
+// doneChan returns a channel that is closed once p settles. It is only
+// allocated for promises that are selected on before they settle.
+func (p *Promise) doneChan() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.signal.Done()
+}
+
+// settle records the outcome of the promise and wakes everything waiting
+// on it. Only the first call has any effect, and reports true.
+func (p *Promise) settle(results []reflect.Value, err error) bool {
+	if err == nil && p.graph != nil && !p.isSettled() {
+		results, err = p.graph.transformResults(p, results)
+	}
+	settled := p.now()
+	var origin *Promise
+	if err != nil && debugging() {
+		origin = p.originOf(err)
+	}
+	p.mu.Lock()
+	if !p.signal.Pending() {
+		p.mu.Unlock()
+		return false
+	}
+	p.err = err
+	if origin != nil {
+		p.extras().origin = origin
+	}
 	p.results = results
-	p.cond.Broadcast()
+	p.settled = settled
+	continuations, _ := p.signal.Settle()
+	x := p.peekExtras()
+	cleanups := x.cleanups
+	if cleanups != nil {
+		x.cleanups = nil
+	}
+	parents := p.parents
+	children := p.children
+	p.mu.Unlock()
+	untrackPending(p)
+	runCleanups(p.Name(), cleanups)
+	core.Run(continuations)
+	if x.cancelCtx != nil {
+		x.cancelCtx()
+	}
+	p.graph.nodeSettled()
+	if err != nil && atomic.LoadInt32(&p.observed) == 0 {
+		p.trackRejection()
+	}
+	observeSettled(p)
+	for _, parent := range parents {
+		parent.releaseConsumer()
+	}
+	for _, child := range children {
+		child.releaseRef()
+	}
+	p.releaseRef()
+	return true
 }
 
 func (p *Promise) getBareWaitRVs(out ...interface{}) []reflect.Value {
 	outRvs := []reflect.Value{}
 	if len(p.resultType) != len(out) {
-		panic(errors.Errorf("Promise returns %d values, Wait was asked to set %d values", len(p.resultType), len(out)))
+		panic(fmt.Errorf("Promise returns %d values, Wait was asked to set %d values", len(p.resultType), len(out)))
 	}
 	for i := 0; i < len(out); i++ {
 		outRv := reflect.ValueOf(out[i])
 		outRvs = append(outRvs, outRv)
 		outType := outRv.Type()
 		if outType != reflect.PtrTo(p.resultType[i]) {
-			panic(errors.Errorf("for return value %d: expected pointer to %s got type %s", i, p.resultType[i], outType))
+			panic(fmt.Errorf("for return value %d: expected pointer to %s got type %s", i, p.resultType[i], outType))
 		}
 	}
 	return outRvs
@@ -463,7 +944,7 @@ func validSliceReturn(resultType []reflect.Type, args []interface{}) (elem refle
 	}
 	arg := args[0]
 	argType := reflect.TypeOf(arg)
-	if argType.Kind() != reflect.Ptr {
+	if argType == nil || argType.Kind() != reflect.Ptr || reflect.ValueOf(arg).IsNil() {
 		return nil, false
 	}
 	slice := argType.Elem()
@@ -479,54 +960,159 @@ func validSliceReturn(resultType []reflect.Type, args []interface{}) (elem refle
 
 // Wait blocks until the promise finishes execution or panics.
 // If the promise panics, wait wraps the panic and returns an error.
+//
+// out takes one pointer per result, to any type the result is assignable
+// to, such as an interface it implements, or nil to skip that result, as
+// in all.Wait(&first, nil, &third). For results of a single type, it may
+// instead be a pointer to a slice of that type; AllOf gives typed promises
+// the same slice, checked at compile time. It may also be a single pointer
+// to a struct whose exported fields take the results in order, or whose
+// fields tagged `promise:"N"` take the N'th result. With no out at all,
+// Wait only waits, and discards the results.
 func (p *Promise) Wait(out ...interface{}) error {
-	// Check for slice special case
+	return p.wait(nil, nil, out)
+}
 
-	sliceReturnType, isSliceReturn := validSliceReturn(p.resultType, out)
+// WaitScan is like Wait, except that it returns an error instead of
+// panicking when out doesn't match the promise's results, for callers
+// such as request handlers that must not panic.
+func (p *Promise) WaitScan(out ...interface{}) error {
+	p.checkCopy()
+	if p.dynamic {
+		// Its types are only known once it settles.
+		p.await()
+	}
+	if _, _, err := p.checkDest(out); err != nil && !(p.dynamic && p.err != nil) {
+		return err
+	}
+	return p.wait(nil, nil, out)
+}
 
-	if !isSliceReturn {
-		if len(p.resultType) != len(out) {
-			panic(errors.Errorf("Promise returns %d values, Wait was asked to set %d values", len(p.resultType), len(out)))
+// checkDest checks that out can receive p's results, as described by
+// Wait.
+func (p *Promise) checkDest(out []interface{}) (sliceElem reflect.Type, isSlice bool, err error) {
+	if p.sliceType == anySliceType && len(out) == 1 {
+		// All of nothing fills any slice with nothing.
+		if t := reflect.TypeOf(out[0]); t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Slice && !reflect.ValueOf(out[0]).IsNil() {
+			return t.Elem().Elem(), true, nil
 		}
-		for i := 0; i < len(out); i++ {
-			outRv := reflect.ValueOf(out[i])
-			outType := outRv.Type()
-			if outType != reflect.PtrTo(p.resultType[i]) {
-				panic(errors.Errorf("for return value %d: expected pointer to %s got type %s", i, p.resultType[i], outType))
-			}
+	}
+	// Check for slice special case
+	sliceElem, isSlice = validSliceReturn(p.resultType, out)
+	if isSlice {
+		return sliceElem, true, nil
+	}
+	if t, ok := structDest(p.resultType, out); ok {
+		_, err := bindStruct(t, p.resultType)
+		return nil, false, err
+	}
+	if len(out) == 0 {
+		return nil, false, nil
+	}
+	if len(p.resultType) != len(out) {
+		return nil, false, fmt.Errorf("Promise returns %d values, Wait was asked to set %d values", len(p.resultType), len(out))
+	}
+	for i := 0; i < len(out); i++ {
+		outRv := reflect.ValueOf(out[i])
+		if !outRv.IsValid() {
+			// Skipped
+			continue
+		}
+		outType := outRv.Type()
+		if outType.Kind() != reflect.Ptr || !p.accepts(p.resultType[i], outType.Elem()) {
+			return nil, false, fmt.Errorf("for return value %d: expected pointer to %s got type %s", i, p.resultType[i], outType)
+		}
+		if outRv.IsNil() {
+			return nil, false, fmt.Errorf("for return value %d: got nil %s", i, outType)
 		}
 	}
-	p.cond.L.Lock()
-	for !p.complete {
-		p.cond.Wait()
+	return nil, false, nil
+}
+
+// wait implements Wait. If stop is closed before p settles, wait returns
+// stopErr without setting out.
+func (p *Promise) wait(stop <-chan struct{}, stopErr error, out []interface{}) error {
+	p.checkCopy()
+	p.observe()
+	var sliceReturnType reflect.Type
+	var isSliceReturn bool
+	check := func() {
+		var err error
+		sliceReturnType, isSliceReturn, err = p.checkDest(out)
+		if err != nil {
+			panic(err)
+		}
+	}
+	if !p.dynamic {
+		check()
+	}
+	if !p.isSettled() {
+		atomic.AddInt32(&p.waiting, 1)
+		select {
+		case <-p.doneChan():
+			atomic.AddInt32(&p.waiting, -1)
+		case <-stop:
+			atomic.AddInt32(&p.waiting, -1)
+			p.releaseConsumer()
+			return stopErr
+		}
 	}
-	p.cond.L.Unlock()
 
 	if p.err != nil {
-		return errors.Wrap(p.err, "error during promise execution")
+		return p.executionError(p.err)
 	}
+	if p.dynamic {
+		check()
+	}
+
+	results, slice, err := p.retained()
+	if err != nil {
+		return err
+	}
+	defer p.consumed()
 
 	var outRvs []reflect.Value
 
+	if len(out) == 0 {
+		return nil
+	}
+
+	if isSliceReturn && slice.IsValid() {
+		reflect.ValueOf(out[0]).Elem().Set(copySlice(slice))
+		return nil
+	}
+
+	if t, ok := structDest(p.resultType, out); ok && !isSliceReturn {
+		newStructBinding(t, p.resultType).fill(reflect.ValueOf(out[0]).Elem(), results)
+		return nil
+	}
+
 	if isSliceReturn {
 		slicePtr := reflect.ValueOf(out[0])
 		newSlice := reflect.MakeSlice(reflect.SliceOf(sliceReturnType), len(p.resultType), len(p.resultType))
 		slicePtr.Elem().Set(newSlice)
-		for i := 0; i < len(p.results); i++ {
+		for i := 0; i < len(results); i++ {
 			outRv := newSlice.Index(i)
 			outRvs = append(outRvs, outRv)
 		}
 	} else {
 		for i := 0; i < len(out); i++ {
+			if out[i] == nil {
+				outRvs = append(outRvs, reflect.Value{})
+				continue
+			}
 			outRv := reflect.ValueOf(out[i])
 			outRvs = append(outRvs, outRv.Elem())
 		}
 	}
 
-	for i := 0; i < len(p.results); i++ {
+	for i := 0; i < len(results); i++ {
 		outRv := outRvs[i]
-		result := p.results[i]
-		outRv.Set(result)
+		if !outRv.IsValid() {
+			continue
+		}
+		result := results[i]
+		outRv.Set(convertValue(result, outRv.Type()))
 	}
 	return nil
 }
@@ -3,12 +3,30 @@ package promise
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
+// cause returns the error err wraps, through any number of fmt.Errorf
+// %w wrappers such as the ones Wait adds, stopping at the package's own
+// error types.
+func cause(err error) error {
+	for {
+		if _, ok := err.(*PanicError); ok {
+			return err
+		}
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
 func TestPromiseResolution(t *testing.T) {
 	p := New(func() int {
 		return 1
@@ -54,6 +72,8 @@ func TestPromiseResolutionVoidReturnError(t *testing.T) {
 }
 
 func TestPromiseResolutionWrongArgumentType(t *testing.T) {
+	SetStrict(true)
+	defer SetStrict(defaultStrict)
 	require.Panics(t, func() {
 		_ = New(func(_ int) {
 		}, "sizzle")
@@ -230,6 +250,17 @@ func BenchmarkPromiseAllReturnIntoSlice(b *testing.B) {
 	}
 }
 
+func BenchmarkNewThenWait(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var n int
+		err := New(func() int { return 1 }).Then(func(n int) int { return n + 1 }).Wait(&n)
+		if err != nil || n != 2 {
+			b.Fatal(err, n)
+		}
+	}
+}
+
 func BenchmarkSyncSlicesWithChannels(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
@@ -397,3 +428,164 @@ func TestPromiseAnyFailsIfAllFail(t *testing.T) {
 	require.Contains(t, err.Error(), "err")
 	require.Equal(t, "", retval)
 }
+
+// copyPromise copies p by value without tripping vet's copylocks check.
+func copyPromise(p *Promise) *Promise {
+	copied := reflect.New(reflect.TypeOf(p).Elem())
+	copied.Elem().Set(reflect.ValueOf(p).Elem())
+	return copied.Interface().(*Promise)
+}
+
+func TestCopiedPromisePanics(t *testing.T) {
+	// Settle synchronously so the copy doesn't race with a running promise.
+	signal := Signal()
+	signal.Resolve()
+	p := signal.Promise()
+	require.NoError(t, p.Wait())
+	copied := copyPromise(p)
+	require.PanicsWithValue(t, "promise: Promise value was copied; use *Promise instead", func() {
+		_ = copied.Wait()
+	})
+	require.Panics(t, func() {
+		copied.Then(func() {})
+	})
+	require.PanicsWithValue(t, "promise: Promise value was copied; use *Promise instead", func() {
+		All(copied)
+	})
+	require.PanicsWithValue(t, "promise: zero Promise used; create promises with New or another constructor", func() {
+		_ = new(Promise).Wait()
+	})
+}
+
+func TestAnyResolvesWithFirstSuccess(t *testing.T) {
+	fail := func() (string, error) {
+		return "", fmt.Errorf("err")
+	}
+	sleepThenSuccess := func() string {
+		time.Sleep(50 * time.Millisecond)
+		return "success"
+	}
+
+	var retval string
+	err := Any(New(fail), New(sleepThenSuccess), New(fail)).Wait(&retval)
+	require.NoError(t, err)
+	require.Equal(t, "success", retval)
+}
+
+func TestAnyAggregatesErrors(t *testing.T) {
+	first := errors.New("first")
+	second := errors.New("second")
+	returnFirst := func() (string, error) {
+		return "", first
+	}
+	sleepThenSecond := func() (string, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "", second
+	}
+	sleepThenPanic := func() string {
+		time.Sleep(100 * time.Millisecond)
+		panic("failed")
+	}
+
+	err := Any(New(returnFirst), New(sleepThenSecond), New(sleepThenPanic)).Wait(new(string))
+	aggregate, ok := cause(err).(*AggregateError)
+	require.True(t, ok)
+	require.Len(t, aggregate.Errs, 3)
+	require.Equal(t, first, aggregate.Errs[0])
+	require.Equal(t, second, aggregate.Errs[1])
+	require.Contains(t, aggregate.Errs[2].Error(), "failed")
+	require.True(t, aggregate.Is(second))
+	require.Contains(t, err.Error(), "promise 1: second")
+}
+
+func TestAnyOfOneAggregatesError(t *testing.T) {
+	fail := errors.New("fail")
+	err := Any(New(func() error { return fail })).Wait()
+	aggregate, ok := cause(err).(*AggregateError)
+	require.True(t, ok)
+	require.Equal(t, []error{fail}, aggregate.Errs)
+}
+
+func TestAnyOfNothingRejects(t *testing.T) {
+	err := Any().Wait()
+	aggregate, ok := cause(err).(*AggregateError)
+	require.True(t, ok)
+	require.Empty(t, aggregate.Errs)
+}
+
+func TestWaitScan(t *testing.T) {
+	p := Resolved(1, "two")
+	var i int
+	var s string
+	require.NoError(t, p.WaitScan(&i, &s))
+	require.Equal(t, 1, i)
+	require.Equal(t, "two", s)
+
+	require.EqualError(t, p.WaitScan(&i), "Promise returns 2 values, Wait was asked to set 1 values")
+	require.EqualError(t, p.WaitScan(&s, &i), "for return value 0: expected pointer to int got type *string")
+	require.EqualError(t, p.WaitScan(i, s), "for return value 0: expected pointer to int got type int")
+	require.EqualError(t, p.WaitScan((*int)(nil), &s), "for return value 0: got nil *int")
+
+	s = ""
+	require.NoError(t, p.WaitScan(nil, &s))
+	require.Equal(t, "two", s)
+	require.NoError(t, p.WaitScan())
+
+	err := Rejected(errors.New("failed"), typeOf[int]()).WaitScan(&i)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed")
+}
+
+func TestWaitSkipsNilDestinations(t *testing.T) {
+	all := All(Resolved(1), Resolved("two"), Resolved(3.0))
+	var first int
+	var third float64
+	require.NoError(t, all.Wait(&first, nil, &third))
+	require.Equal(t, 1, first)
+	require.Equal(t, 3.0, third)
+	require.NoError(t, all.Wait())
+	require.NoError(t, all.Wait(nil, nil, nil))
+	require.Panics(t, func() { all.Wait(nil, nil) })
+}
+
+func TestWaitErrorsUnwrap(t *testing.T) {
+	failure := errors.New("failed")
+	err := New(func() error { return failure }).Then(func() {}).WithName("after").Wait()
+	require.True(t, errors.Is(err, failure))
+	require.Equal(t, failure, errors.Unwrap(err))
+
+	err = New(func() { time.Sleep(time.Second) }).WithTimeout(time.Millisecond).Wait()
+	require.True(t, errors.Is(err, ErrTimeout))
+
+	err = New(func() { panic(failure) }).Wait()
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	require.True(t, errors.Is(err, failure))
+}
+
+func TestSettledPromiseReleasesFunction(t *testing.T) {
+	type payload struct{ data [1 << 16]int }
+	collected := make(chan struct{})
+	chain := func() *PromiseT[int] {
+		big := &payload{}
+		big.data[0] = 1
+		runtime.SetFinalizer(big, func(*payload) { close(collected) })
+		return ThenOf(NewT(func() (int, error) { return big.data[0], nil }), func(n int) (int, error) {
+			return n + big.data[0], nil
+		})
+	}
+	p := chain()
+	n, err := p.Wait()
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		select {
+		case <-collected:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+	runtime.KeepAlive(p)
+}
@@ -0,0 +1,43 @@
+// Package promisetest helps test code that uses promises.
+package promisetest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	promise "github.com/garlicnation/promises/v2"
+)
+
+// grace is how long VerifyNoLeaks gives goroutines to finish, as the
+// goroutine that settled a promise may still be winding down when a test
+// returns from waiting on it.
+var grace = time.Second
+
+// VerifyNoLeaks fails t if, once t has finished, more goroutines started
+// by the promise package are running than when VerifyNoLeaks was called,
+// and lists the promises that haven't settled, if they were created in
+// debug mode (see promise.EnableDebug). Call it at the start of a test:
+//
+//	func TestFetch(t *testing.T) {
+//		promisetest.VerifyNoLeaks(t)
+//		...
+//	}
+//
+// The count is global, so tests that run in parallel with t can make it
+// fail.
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	before := promise.InFlight()
+	t.Cleanup(func() {
+		deadline := time.Now().Add(grace)
+		for promise.InFlight() > before && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if leaked := promise.InFlight() - before; leaked > 0 {
+			var pending bytes.Buffer
+			_ = promise.DumpPending(&pending)
+			t.Errorf("promisetest: %d goroutines started by the promise package are still running; pending promises:\n%s", leaked, pending.String())
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package promisetest
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	promise "github.com/garlicnation/promises/v2"
+)
+
+// recorder is a testing.TB that records failures and cleanups instead of
+// acting on them.
+type recorder struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// Fatalf records the failure and stops the calling goroutine, as
+// testing.T's does. Call functions that may fail fatally through run.
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// run calls f on a goroutine of its own, so that Fatalf can stop it.
+func (r *recorder) run(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	<-done
+}
+
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	r := &recorder{TB: t}
+	VerifyNoLeaks(r)
+	var n int
+	require.NoError(t, promise.New(func() int { return 1 }).Then(func(n int) int { return n + 1 }).Wait(&n))
+	r.finish()
+	require.Empty(t, r.errors)
+}
+
+func TestVerifyNoLeaksReportsLeak(t *testing.T) {
+	promise.EnableDebug()
+	defer promise.DisableDebug()
+	defer func(old time.Duration) { grace = old }(grace)
+	grace = 10 * time.Millisecond
+	r := &recorder{TB: t}
+	VerifyNoLeaks(r)
+	block := make(chan struct{})
+	defer close(block)
+	promise.NewNamed("stuck", func() { <-block })
+	r.finish()
+	require.Len(t, r.errors, 1)
+	require.Contains(t, r.errors[0], "1 goroutines started by the promise package are still running")
+	require.Contains(t, r.errors[0], "stuck")
+}
@@ -0,0 +1,94 @@
+package promisetest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	promise "github.com/garlicnation/promises/v2"
+)
+
+// RequireResolves waits up to timeout for p to settle, fills out with its
+// results as Wait does, and fails t right away if p didn't resolve in
+// time.
+func RequireResolves(t testing.TB, p *promise.Promise, timeout time.Duration, out ...interface{}) {
+	t.Helper()
+	select {
+	case <-p.Done():
+	case <-time.After(timeout):
+		t.Fatalf("promisetest: promise %s didn't settle within %v", p.Name(), timeout)
+	}
+	if err := p.Wait(out...); err != nil {
+		t.Fatalf("promisetest: promise %s was rejected: %v", p.Name(), err)
+	}
+}
+
+// RequireRejects waits up to timeout for p to settle, and fails t right
+// away unless p was rejected with an error every one of matchers
+// accepts. The error is as Wait returns it, wrapping the one p was
+// rejected with, and is returned for further checks.
+func RequireRejects(t testing.TB, p *promise.Promise, timeout time.Duration, matchers ...ErrorMatcher) error {
+	t.Helper()
+	select {
+	case <-p.Done():
+	case <-time.After(timeout):
+		t.Fatalf("promisetest: promise %s didn't settle within %v", p.Name(), timeout)
+	}
+	_, err := p.Result()
+	if err == nil {
+		t.Fatalf("promisetest: promise %s resolved, expected it to be rejected", p.Name())
+	}
+	for _, m := range matchers {
+		if !m.match(err) {
+			t.Fatalf("promisetest: promise %s was rejected with %q, expected an error that %s", p.Name(), err, m.description)
+		}
+	}
+	return err
+}
+
+// RequirePending fails t right away if p settles within d, for checking
+// that a promise waits on something that hasn't happened yet.
+func RequirePending(t testing.TB, p *promise.Promise, d time.Duration) {
+	t.Helper()
+	select {
+	case <-p.Done():
+		_, err := p.Result()
+		t.Fatalf("promisetest: promise %s settled within %v, expected it to be pending (error: %v)", p.Name(), d, err)
+	case <-time.After(d):
+	}
+}
+
+// An ErrorMatcher checks the error a promise was rejected with, for
+// RequireRejects.
+type ErrorMatcher struct {
+	description string
+	match       func(err error) bool
+}
+
+// ErrorIs matches errors that are target, or wrap it, as errors.Is
+// reports.
+func ErrorIs(target error) ErrorMatcher {
+	return ErrorMatcher{
+		description: fmt.Sprintf("is %q", target),
+		match:       func(err error) bool { return errors.Is(err, target) },
+	}
+}
+
+// ErrorAs matches errors that errors.As can assign to target, a non-nil
+// pointer, and assigns the first match to it.
+func ErrorAs(target interface{}) ErrorMatcher {
+	return ErrorMatcher{
+		description: fmt.Sprintf("can be assigned to %T", target),
+		match:       func(err error) bool { return errors.As(err, target) },
+	}
+}
+
+// ErrorContains matches errors whose message contains substr.
+func ErrorContains(substr string) ErrorMatcher {
+	return ErrorMatcher{
+		description: fmt.Sprintf("contains %q", substr),
+		match:       func(err error) bool { return strings.Contains(err.Error(), substr) },
+	}
+}
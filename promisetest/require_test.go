@@ -0,0 +1,62 @@
+package promisetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	promise "github.com/garlicnation/promises/v2"
+)
+
+func TestRequireResolves(t *testing.T) {
+	var n int
+	RequireResolves(t, promise.Resolved(1), time.Second, &n)
+	require.Equal(t, 1, n)
+
+	r := &recorder{TB: t}
+	r.run(func() { RequireResolves(r, promise.NewDeferred().Promise, time.Millisecond) })
+	require.Len(t, r.errors, 1)
+	require.Contains(t, r.errors[0], "didn't settle within 1ms")
+
+	r = &recorder{TB: t}
+	r.run(func() { RequireResolves(r, promise.Rejected(errors.New("failed")), time.Second) })
+	require.Len(t, r.errors, 1)
+	require.Contains(t, r.errors[0], "was rejected")
+	require.Contains(t, r.errors[0], "failed")
+
+	r = &recorder{TB: t}
+	r.run(func() { RequireResolves(r, promise.Rejected(promise.ErrWaitTimeout), time.Second) })
+	require.Len(t, r.errors, 1)
+	require.Contains(t, r.errors[0], "was rejected")
+}
+
+func TestRequireRejects(t *testing.T) {
+	failure := errors.New("failed")
+	var pe *promise.PanicError
+	err := RequireRejects(t, promise.New(func() { panic("boom") }), time.Second, ErrorAs(&pe), ErrorContains("boom"))
+	require.NotNil(t, pe)
+	require.Error(t, err)
+	RequireRejects(t, promise.Rejected(failure), time.Second, ErrorIs(failure))
+
+	r := &recorder{TB: t}
+	r.run(func() { RequireRejects(r, promise.Rejected(failure), time.Second, ErrorContains("other")) })
+	require.Equal(t, []string{`promisetest: promise Rejected was rejected with "error during promise execution: failed", expected an error that contains "other"`}, r.errors)
+
+	r = &recorder{TB: t}
+	r.run(func() { RequireRejects(r, promise.Resolved(1), time.Second) })
+	require.Len(t, r.errors, 1)
+	require.Contains(t, r.errors[0], "resolved, expected it to be rejected")
+}
+
+func TestRequirePending(t *testing.T) {
+	d := promise.NewDeferred()
+	RequirePending(t, d.Promise, time.Millisecond)
+	d.Resolve()
+
+	r := &recorder{TB: t}
+	r.run(func() { RequirePending(r, d.Promise, time.Second) })
+	require.Len(t, r.errors, 1)
+	require.Contains(t, r.errors[0], "expected it to be pending")
+}
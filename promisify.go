@@ -0,0 +1,36 @@
+package promise
+
+import "reflect"
+
+// Promisify returns a function that calls f in a new promise with the
+// arguments it is given, as New(f, args...) would, for functions called
+// asynchronously from many places:
+//
+//	getAsync := Promisify(http.Get)
+//	resp := getAsync("https://example.com")
+//
+// f is checked to be a function, and its signature looked up, only once,
+// rather than by every call. Arguments that don't match f panic, even with
+// strict mode off.
+func Promisify(f interface{}) func(args ...interface{}) *Promise {
+	c := newCaller(f)
+	c.strict = true
+	return func(args ...interface{}) *Promise {
+		p, start := c.newCall(nil, args)
+		start()
+		return p
+	}
+}
+
+// PromisifyT is a typed variant of Promisify for functions of one
+// argument, whose types are checked at compile time.
+func PromisifyT[A, R any](f func(A) (R, error)) func(A) *PromiseT[R] {
+	c := newCaller(f)
+	return func(arg A) *PromiseT[R] {
+		// Passed as a leading value, which keeps the static type of arg
+		// when A is an interface type.
+		p, start := c.newCall([]reflect.Value{reflect.ValueOf(&arg).Elem()}, nil)
+		start()
+		return &PromiseT[R]{p: p}
+	}
+}
@@ -0,0 +1,34 @@
+package promise
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromisify(t *testing.T) {
+	atoi := Promisify(strconv.Atoi)
+	var n int
+	require.NoError(t, atoi("42").Wait(&n))
+	require.Equal(t, 42, n)
+	require.Error(t, atoi("x").Wait(&n))
+	require.Equal(t, "strconv.Atoi", atoi("1").Name())
+
+	require.Panics(t, func() { Promisify(42) })
+	require.Panics(t, func() { atoi(1) })
+}
+
+func TestPromisifyT(t *testing.T) {
+	readAll := PromisifyT(io.ReadAll)
+	data, err := readAll(bytes.NewBufferString("body")).Wait()
+	require.NoError(t, err)
+	require.Equal(t, "body", string(data))
+
+	atoi := PromisifyT(strconv.Atoi)
+	n, err := atoi("7").Wait()
+	require.NoError(t, err)
+	require.Equal(t, 7, n)
+}
@@ -0,0 +1,74 @@
+package promise
+
+import "sync"
+
+// RaceResult is what the promises returned by RaceIndexed and AnyIndexed
+// resolve with.
+type RaceResult struct {
+	// Index is the position of the winner among the promises passed.
+	Index int
+	// Results holds how the winner settled. Err is only ever set for
+	// RaceIndexed, whose winner may have failed.
+	Results Results
+	// Settled reports, by position, which of the promises had settled
+	// when the winner was picked, including the winner itself.
+	Settled []bool
+}
+
+// RaceIndexed is like Race, but resolves with a RaceResult saying which
+// of promises settled first and how, even if it failed, so hedged
+// requests can tell which replica answered. Like Race, it cancels the
+// losers that nothing else is chained from. With no promises it never
+// settles.
+func RaceIndexed(promises ...*Promise) *Promise {
+	return raceIndexed("RaceIndexed", promises, false)
+}
+
+// AnyIndexed is like Any, but resolves with a RaceResult saying which of
+// promises resolved first. Like Any, it is rejected with an
+// *AggregateError if they all fail, and cancels the losers that nothing
+// else is chained from.
+func AnyIndexed(promises ...*Promise) *Promise {
+	return raceIndexed("AnyIndexed", promises, true)
+}
+
+// raceIndexed implements RaceIndexed and, if skipFailures, AnyIndexed.
+func raceIndexed(name string, promises []*Promise, skipFailures bool) *Promise {
+	d := NewDeferred(typeOf[RaceResult]())
+	d.name = name
+	if skipFailures && len(promises) == 0 {
+		d.Reject(&AggregateError{})
+		return d.Promise
+	}
+	var mu sync.Mutex
+	aggregate := &AggregateError{Errs: make([]error, len(promises))}
+	failed := 0
+	d.watchContexts(promises)
+	for i, prior := range promises {
+		i, prior := i, prior
+		prior.observe()
+		prior.graph.addChild(prior, d.Promise)
+		prior.whenSettled(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if d.isSettled() {
+				return
+			}
+			if skipFailures && prior.err != nil {
+				aggregate.Errs[i] = prior.err
+				aggregate.LastErr = prior.err
+				if failed++; failed == len(promises) {
+					d.Reject(aggregate)
+				}
+				return
+			}
+			settled := make([]bool, len(promises))
+			for j, p := range promises {
+				settled[j] = p.isSettled()
+			}
+			d.Resolve(RaceResult{Index: i, Results: prior.settledResults(), Settled: settled})
+		})
+	}
+	d.cancelOnSettle(promises)
+	return d.Promise
+}
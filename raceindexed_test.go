@@ -0,0 +1,48 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRaceIndexed(t *testing.T) {
+	slow := NewDeferred(typeOf[string]())
+	p := RaceIndexed(slow.Promise, Resolved("fast"))
+	var result RaceResult
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, 1, result.Index)
+	require.Equal(t, []interface{}{"fast"}, result.Results.Values)
+	require.Equal(t, []bool{false, true}, result.Settled)
+	// The loser is canceled.
+	pollUntil(t, func() bool {
+		return slow.Promise.State() == StateRejected
+	})
+}
+
+func TestRaceIndexedFailure(t *testing.T) {
+	failure := errors.New("failed")
+	p := RaceIndexed(Rejected(failure), NewDeferred().Promise)
+	var result RaceResult
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, 0, result.Index)
+	require.Equal(t, failure, result.Results.Err)
+}
+
+func TestAnyIndexed(t *testing.T) {
+	failure := errors.New("failed")
+	replica := NewDeferred(typeOf[int]())
+	p := AnyIndexed(Rejected(failure), replica.Promise)
+	replica.Resolve(2)
+	var result RaceResult
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, 1, result.Index)
+	require.Equal(t, []interface{}{2}, result.Results.Values)
+	require.Equal(t, []bool{true, true}, result.Settled)
+
+	err := AnyIndexed(Rejected(failure), Rejected(failure)).Wait(&result)
+	var aggregate *AggregateError
+	require.True(t, errors.As(err, &aggregate))
+	require.Equal(t, []error{failure, failure}, aggregate.Errs)
+}
@@ -0,0 +1,44 @@
+package promise
+
+import "context"
+
+// A RateLimiter hands out tokens for promise functions to run.
+// *rate.Limiter from golang.org/x/time/rate satisfies RateLimiter.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// NewLimited is like New, except that f doesn't start until limiter hands
+// out a token. Waiting for the token stops if the promise settles first,
+// such as by Cancel, and the promise is rejected with the error Wait
+// returns, such as when the token can never be handed out.
+func NewLimited(limiter RateLimiter, f interface{}, args ...interface{}) *Promise {
+	return newWith(f, options{args: args, limiter: limiter})
+}
+
+// waitForToken calls start, which starts p's function, once limiter hands
+// out a token.
+func (p *Promise) waitForToken(limiter RateLimiter, start func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Defer(cancel)
+	p.pin()
+	spawn(func() {
+		if err := limiter.Wait(ctx); err != nil {
+			p.settle(nil, err)
+			return
+		}
+		start()
+	})
+}
+
+// Throttled returns a function like New that creates its promises with
+// NewLimited, so that every promise created through it shares limiter,
+// as fanning out calls to a rate limited API wants:
+//
+//	fetch := Throttled(rate.NewLimiter(10, 1))
+//	all := All(fetch(get, "a"), fetch(get, "b"), fetch(get, "c"))
+func Throttled(limiter RateLimiter) func(f interface{}, args ...interface{}) *Promise {
+	return func(f interface{}, args ...interface{}) *Promise {
+		return NewLimited(limiter, f, args...)
+	}
+}
@@ -0,0 +1,77 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// tokenLimiter hands out a token each time one is sent on tokens, and
+// reports waits that gave up on gaveUp.
+type tokenLimiter struct {
+	tokens chan struct{}
+	gaveUp chan error
+	err    error
+}
+
+func (l *tokenLimiter) Wait(ctx context.Context) error {
+	if l.err != nil {
+		return l.err
+	}
+	select {
+	case <-l.tokens:
+		return nil
+	case <-ctx.Done():
+		if l.gaveUp != nil {
+			l.gaveUp <- ctx.Err()
+		}
+		return ctx.Err()
+	}
+}
+
+func TestNewLimitedWaitsForToken(t *testing.T) {
+	l := &tokenLimiter{tokens: make(chan struct{})}
+	var calls int32
+	fetch := Throttled(l)
+	all := All(fetch(func(i int) int {
+		atomic.AddInt32(&calls, 1)
+		return i
+	}, 1), fetch(func(i int) int {
+		atomic.AddInt32(&calls, 1)
+		return i
+	}, 2))
+
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	l.tokens <- struct{}{}
+	l.tokens <- struct{}{}
+
+	var a, b int
+	require.NoError(t, all.Wait(&a, &b))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	require.Equal(t, 1, a)
+	require.Equal(t, 2, b)
+}
+
+func TestNewLimitedRejectsOnLimiterError(t *testing.T) {
+	sentinel := errors.New("burst exceeded")
+	p := NewLimited(&tokenLimiter{err: sentinel}, func() {
+		t.Error("function ran without a token")
+	})
+	err := p.Wait()
+	require.True(t, errors.Is(err, sentinel))
+}
+
+func TestNewLimitedCancelStopsWaiting(t *testing.T) {
+	l := &tokenLimiter{tokens: make(chan struct{}), gaveUp: make(chan error, 1)}
+	p := NewLimited(l, func() {
+		t.Error("canceled function ran")
+	})
+	p.Cancel()
+	require.True(t, errors.Is(p.Wait(), ErrCanceled))
+	require.Equal(t, context.Canceled, <-l.gaveUp)
+}
@@ -0,0 +1,182 @@
+package promise
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/garlicnation/promises/v2/internal/core"
+)
+
+// pooling is set by SetPooling.
+var pooling int32
+
+var promisePool = sync.Pool{New: func() interface{} { return new(Promise) }}
+
+// SetPooling turns recycling of released promises on or off. With it on,
+// the promises created by New, Then, Catch, Finally and the combinators
+// are taken from a pool, and go back to it once they are released with
+// Release and nothing the package runs still refers to them. That takes
+// pressure off the garbage collector in services that create millions of
+// short-lived promises. It only affects promises created afterwards.
+func SetPooling(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&pooling, v)
+}
+
+// newPooledPromise is like newPromise, but takes the promise from the pool
+// when pooling is on. It's for promises whose settling the package fully
+// controls, so it knows when nothing refers to them anymore.
+func newPooledPromise(t promiseType, name string) *Promise {
+	if atomic.LoadInt32(&pooling) == 0 {
+		return newPromise(t, name)
+	}
+	p := promisePool.Get().(*Promise)
+	p.signal.Reset()
+	p.pooled = true
+	// One reference for the caller, dropped by Release, and one for
+	// settling.
+	p.refs = 2
+	return initPromise(p, t, name)
+}
+
+// Release tells the package that the caller is done with p: it won't wait
+// on p, chain from it or call any of its methods again. With pooling on,
+// p is recycled once it has settled and every promise chained from it has
+// been released and recycled too, so release the ends of chains as well
+// as their starts:
+//
+//	p := New(fetch)
+//	q := p.Then(parse)
+//	p.Release()
+//	err := q.Wait(&doc)
+//	q.Release()
+//
+// Using p after Release is a bug. While p waits in the pool, that panics,
+// as does releasing p twice. Once p has been reused, a call through p
+// reaches another promise, but promises the package holds on to, such as
+// those cached by Memoize or a Cache, are checked whenever they are
+// handed out again, and panic if they were released and reused
+// meanwhile. Promises with timers or context watchers, such as those
+// created by NewCtx or ThenWithTimeout, are never recycled, as their
+// timers may still fire. Without pooling, Release only checks it isn't
+// called twice.
+func (p *Promise) Release() {
+	p.checkCopy()
+	if !atomic.CompareAndSwapInt32(&p.released, 0, 1) {
+		panic("promise: Release called twice")
+	}
+	p.releaseRef()
+}
+
+// pin keeps p from being recycled, because something outside the
+// package's bookkeeping, such as a timer or a goroutine, may refer to it
+// after it settles.
+func (p *Promise) pin() {
+	atomic.StoreInt32(&p.pinned, 1)
+}
+
+// acquire adds a reference to p, which keeps it from being recycled until
+// releaseRef drops it.
+func (p *Promise) acquire() {
+	if p.pooled {
+		atomic.AddInt32(&p.refs, 1)
+	}
+}
+
+// tryAcquire is like acquire, but reports false instead if p is being
+// recycled.
+func (p *Promise) tryAcquire() bool {
+	if !p.pooled {
+		return true
+	}
+	for {
+		refs := atomic.LoadInt32(&p.refs)
+		if refs == 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&p.refs, refs, refs+1) {
+			return true
+		}
+	}
+}
+
+// releaseRef drops a reference to p, and recycles p if it was the last.
+func (p *Promise) releaseRef() {
+	if p.pooled && atomic.AddInt32(&p.refs, -1) == 0 {
+		p.recycle()
+	}
+}
+
+// recycle returns p to the pool, detaching it from the promises it was
+// chained from, and drops its references to them.
+func (p *Promise) recycle() {
+	if atomic.LoadInt32(&p.pinned) != 0 || p.graph.hasHooks() {
+		// Hooks may still be handed p.
+		return
+	}
+	p.mu.Lock()
+	parents := p.parents
+	p.mu.Unlock()
+	for _, parent := range parents {
+		parent.removeChild(p)
+	}
+	generation := p.generation + 1
+	*p = Promise{self: p.self}
+	p.signal.Retire()
+	atomic.StoreUint32(&p.generation, generation)
+	promisePool.Put(p)
+	for _, parent := range parents {
+		parent.releaseRef()
+	}
+}
+
+// errUsedAfterRelease is what using a promise after Release panics with,
+// where that is detected.
+const errUsedAfterRelease = "promise: Promise used after Release"
+
+// A promiseRef refers to a promise as it was when the ref was taken, for
+// holding on to a promise that may be released and reused meanwhile.
+type promiseRef struct {
+	p          *Promise
+	generation uint32
+}
+
+// ref returns a promiseRef to p.
+func (p *Promise) ref() promiseRef {
+	return promiseRef{p: p, generation: atomic.LoadUint32(&p.generation)}
+}
+
+// stale reports whether the promise r refers to has been released and
+// recycled since r was taken.
+func (r promiseRef) stale() bool {
+	return r.p.signal.State() == core.Retired || atomic.LoadUint32(&r.p.generation) != r.generation
+}
+
+// removeChild removes child from the promises chained from p.
+func (p *Promise) removeChild(child *Promise) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, c := range p.children {
+		if c == child {
+			// Copied rather than shifted in place, as Cancel and others
+			// iterate over earlier snapshots of the slice.
+			children := make([]*Promise, 0, len(p.children)-1)
+			children = append(children, p.children[:i]...)
+			p.children = append(children, p.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// hasHooks reports whether any hooks are registered with g.
+func (g *Graph) hasHooks() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.hooks) > 0
+}
@@ -0,0 +1,206 @@
+package promise
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/garlicnation/promises/v2/internal/core"
+)
+
+// withPooling turns pooling on for the test, and runs promise functions
+// with a ManualScheduler so that they finish settling, and release their
+// references, before Step returns.
+func withPooling(t *testing.T) *ManualScheduler {
+	SetPooling(true)
+	s := NewManualScheduler()
+	SetScheduler(s)
+	t.Cleanup(func() {
+		SetScheduler(nil)
+		SetPooling(false)
+	})
+	return s
+}
+
+func recycled(p *Promise) bool {
+	return p.signal.State() == core.Retired
+}
+
+func TestReleaseRecyclesSettledPromise(t *testing.T) {
+	s := withPooling(t)
+	p := New(func() int { return 1 })
+	s.RunUntilIdle()
+	var out int
+	require.NoError(t, p.Wait(&out))
+	require.Equal(t, 1, out)
+	p.Release()
+	require.True(t, recycled(p))
+	require.PanicsWithValue(t, "promise: Promise used after Release", func() { p.Wait(&out) })
+}
+
+func TestReleaseWaitsForChainedPromises(t *testing.T) {
+	s := withPooling(t)
+	p := New(func() int { return 1 })
+	q := p.Then(func(x int) int { return x + 1 })
+	p.Release()
+	s.RunUntilIdle()
+	var out int
+	require.NoError(t, q.Wait(&out))
+	require.Equal(t, 2, out)
+	require.False(t, recycled(p))
+
+	q.Release()
+	require.True(t, recycled(p))
+	require.True(t, recycled(q))
+}
+
+func TestReleasePendingPromise(t *testing.T) {
+	s := withPooling(t)
+	p := New(func() {})
+	p.Release()
+	require.False(t, recycled(p))
+	s.RunUntilIdle()
+	require.True(t, recycled(p))
+}
+
+func TestReleaseTwicePanics(t *testing.T) {
+	p := New(func() {})
+	p.Release()
+	require.PanicsWithValue(t, "promise: Release called twice", p.Release)
+}
+
+func TestReleaseWithoutPoolingDoesNotRecycle(t *testing.T) {
+	s := NewManualScheduler()
+	SetScheduler(s)
+	defer SetScheduler(nil)
+	p := New(func() {})
+	s.RunUntilIdle()
+	p.Release()
+	require.False(t, recycled(p))
+}
+
+func TestPinnedPromisesAreNotRecycled(t *testing.T) {
+	s := withPooling(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := NewCtx(ctx, func(ctx context.Context) {})
+	q := New(func() {}).ThenWithTimeout(time.Hour, func() {})
+	s.RunUntilIdle()
+	require.NoError(t, p.Wait())
+	require.NoError(t, q.Wait())
+	p.Release()
+	q.Release()
+	require.False(t, recycled(p))
+	require.False(t, recycled(q))
+}
+
+func TestCancelSkipsRecycledChildren(t *testing.T) {
+	s := withPooling(t)
+	p := New(func() int { return 1 })
+	q := p.Then(func(x int) int { return x })
+	s.RunUntilIdle()
+	require.NoError(t, q.Wait(new(int)))
+	q.Release()
+	require.True(t, recycled(q))
+	p.Cancel()
+	require.Empty(t, p.children)
+	p.Release()
+}
+
+func TestPoolingUnderLoad(t *testing.T) {
+	SetPooling(true)
+	defer SetPooling(false)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				a := New(func() int { return i })
+				b := New(func() int { return j })
+				all := All(a, b)
+				c := all.Then(func(x, y int) int { return x + y })
+				a.Release()
+				b.Release()
+				all.Release()
+				var out int
+				require.NoError(t, c.Wait(&out))
+				require.Equal(t, i+j, out)
+				c.Release()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkPooling(b *testing.B) {
+	for _, pooled := range []bool{false, true} {
+		name := "unpooled"
+		if pooled {
+			name = "pooled"
+		}
+		b.Run(name, func(b *testing.B) {
+			SetPooling(pooled)
+			defer SetPooling(false)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				p := New(func() int { return i })
+				q := p.Then(func(x int) int { return x })
+				p.Release()
+				q.Wait(new(int))
+				q.Release()
+			}
+		})
+	}
+}
+
+func TestRefDetectsReuse(t *testing.T) {
+	s := withPooling(t)
+	p := New(func() int { return 1 })
+	s.RunUntilIdle()
+	r := p.ref()
+	require.False(t, r.stale())
+	p.Release()
+	require.True(t, r.stale())
+
+	// Reuse p, and any other promise the pool hands out.
+	for i := 0; i < 10; i++ {
+		New(func() int { return i })
+	}
+	s.RunUntilIdle()
+	require.True(t, r.stale(), "recycled promises stay stale once reused")
+}
+
+func TestMemoizeDetectsReleasedPromise(t *testing.T) {
+	s := withPooling(t)
+	get := Memoize(func(key string) string { return key }, nil)
+	p := get("a")
+	s.RunUntilIdle()
+	require.True(t, get("a") == p)
+	p.Release()
+	require.PanicsWithValue(t, errUsedAfterRelease, func() { get("a") })
+	q := get("a")
+	s.RunUntilIdle()
+	var out string
+	require.NoError(t, q.Wait(&out), "the released promise is dropped from the cache")
+	require.Equal(t, "a", out)
+}
+
+func TestCacheDetectsReleasedPromise(t *testing.T) {
+	s := withPooling(t)
+	c := NewCache()
+	factory := func() *Promise { return New(func() string { return "a" }) }
+	p := c.GetOrCreate("a", time.Hour, factory)
+	s.RunUntilIdle()
+	require.True(t, c.GetOrCreate("a", time.Hour, factory) == p)
+	p.Release()
+	require.PanicsWithValue(t, errUsedAfterRelease, func() { c.GetOrCreate("a", time.Hour, factory) })
+	q := c.GetOrCreate("a", time.Hour, factory)
+	s.RunUntilIdle()
+	var out string
+	require.NoError(t, q.Wait(&out), "the released promise is dropped from the cache")
+	require.Equal(t, "a", out)
+}
@@ -0,0 +1,55 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var promisePtrType = reflect.TypeOf((*Promise)(nil))
+
+// Reduce folds inputs through reducer, starting from initial, and resolves
+// with the accumulated value. inputs is either a []*Promise, each
+// resolving with a single value, or a slice of plain values. Each input
+// is reduced in order, as soon as it and every input before it have
+// resolved, and the first failure rejects the returned promise.
+//
+// reducer must accept the accumulator and one input and return the new
+// accumulator, optionally followed by an error to reject the returned
+// promise. initial must be assignable to the accumulator's type.
+func Reduce(inputs interface{}, reducer interface{}, initial interface{}) *Promise {
+	inputsRv := reflect.ValueOf(inputs)
+	if inputsRv.Kind() != reflect.Slice {
+		panic(fmt.Errorf("expected a slice of inputs, got %v", inputsRv.Kind()))
+	}
+	reducerRv := reflect.ValueOf(reducer)
+	if reducerRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", reducerRv.Kind()))
+	}
+	reducerType := reducerRv.Type()
+	if reducerType.NumIn() != 2 {
+		panic(fmt.Errorf("reducer must accept the accumulator and an input, got %s", reducerType))
+	}
+	accType := reducerType.In(0)
+	if resultType, _ := getResultType(reducerType); len(resultType) != 1 || resultType[0] != accType {
+		panic(fmt.Errorf("reducer must return the accumulator of type %s, got %s", accType, reducerType))
+	}
+
+	acc := resolvedAs(accType, initial)
+	for i := 0; i < inputsRv.Len(); i++ {
+		var input *Promise
+		if inputsRv.Type().Elem() == promisePtrType {
+			input = inputsRv.Index(i).Interface().(*Promise)
+		} else {
+			input = resolvedAs(inputsRv.Type().Elem(), inputsRv.Index(i).Interface())
+		}
+		acc = All(acc, input).Then(reducer)
+	}
+	return acc
+}
+
+// resolvedAs returns a promise resolved with value as a t.
+func resolvedAs(t reflect.Type, value interface{}) *Promise {
+	d := NewDeferred(t)
+	d.Resolve(value)
+	return d.Promise
+}
@@ -0,0 +1,55 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReducePromises(t *testing.T) {
+	bodies := []*Promise{
+		New(func() string { time.Sleep(20 * time.Millisecond); return "a" }),
+		New(func() string { return "b" }),
+		New(func() string { time.Sleep(10 * time.Millisecond); return "c" }),
+	}
+	var joined string
+	err := Reduce(bodies, func(acc, body string) string {
+		return acc + body
+	}, ">").Wait(&joined)
+	require.NoError(t, err)
+	require.Equal(t, ">abc", joined)
+}
+
+func TestReduceValues(t *testing.T) {
+	var sum int
+	err := Reduce([]int{1, 2, 3}, func(acc int, v int) (int, error) {
+		return acc + v, nil
+	}, 10).Wait(&sum)
+	require.NoError(t, err)
+	require.Equal(t, 16, sum)
+
+	require.NoError(t, Reduce([]int{}, func(acc, v int) int { return acc + v }, 5).Wait(&sum))
+	require.Equal(t, 5, sum)
+}
+
+func TestReduceRejects(t *testing.T) {
+	inputs := []*Promise{Resolved(1), Rejected(errors.New("failed"), typeOf[int]())}
+	err := Reduce(inputs, func(acc, v int) int { return acc + v }, 0).Wait(new(int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed")
+
+	err = Reduce([]int{1, 2}, func(acc, v int) (int, error) {
+		return 0, errors.New("reducer failed")
+	}, 0).Wait(new(int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "reducer failed")
+}
+
+func TestReduceChecksReducer(t *testing.T) {
+	require.Panics(t, func() { Reduce(1, func(a, b int) int { return a }, 0) })
+	require.Panics(t, func() { Reduce([]int{}, func(a int) int { return a }, 0) })
+	require.Panics(t, func() { Reduce([]int{}, func(a, b int) string { return "" }, 0) })
+	require.Panics(t, func() { Reduce([]int{1}, func(a, b int) int { return a }, "zero") })
+}
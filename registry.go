@@ -0,0 +1,81 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var registry = struct {
+	sync.RWMutex
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}{
+	byName: map[string]reflect.Type{},
+	byType: map[reflect.Type]string{},
+}
+
+// RegisterType records T under its default name so that result types can
+// be rendered and looked up by name, for example when describing promises
+// or decoding results. Like gob.Register, the default name is the package
+// path and name for named types, and the type's string otherwise.
+// RegisterType returns the name used.
+func RegisterType[T any]() string {
+	t := typeOf[T]()
+	name := defaultTypeName(t)
+	RegisterTypeName[T](name)
+	return name
+}
+
+// RegisterTypeName records T under name. It panics if name is already
+// registered to another type, or T to another name.
+func RegisterTypeName[T any](name string) {
+	t := typeOf[T]()
+	registry.Lock()
+	defer registry.Unlock()
+	if existing, ok := registry.byName[name]; ok && existing != t {
+		panic(fmt.Errorf("promise: registering duplicate types for %q: %s != %s", name, existing, t))
+	}
+	if existing, ok := registry.byType[t]; ok && existing != name {
+		panic(fmt.Errorf("promise: registering duplicate names for %s: %q != %q", t, existing, name))
+	}
+	registry.byName[name] = t
+	registry.byType[t] = name
+}
+
+// TypeByName returns the type registered under name.
+func TypeByName(name string) (reflect.Type, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	t, ok := registry.byName[name]
+	return t, ok
+}
+
+// TypeName returns the name t is registered under, or its default name if
+// it isn't registered.
+func TypeName(t reflect.Type) string {
+	registry.RLock()
+	name, ok := registry.byType[t]
+	registry.RUnlock()
+	if ok {
+		return name
+	}
+	return defaultTypeName(t)
+}
+
+func defaultTypeName(t reflect.Type) string {
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
+
+// ResultTypeNames returns the names of the types p resolves with, as
+// reported by TypeName.
+func (p *Promise) ResultTypeNames() []string {
+	names := make([]string, len(p.resultType))
+	for i, t := range p.resultType {
+		names[i] = TypeName(t)
+	}
+	return names
+}
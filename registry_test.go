@@ -0,0 +1,36 @@
+package promise
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type registeredResult struct {
+	Value int
+}
+
+type renamedResult struct{}
+
+func TestRegisterType(t *testing.T) {
+	name := RegisterType[registeredResult]()
+	require.Equal(t, "github.com/garlicnation/promises/v2.registeredResult", name)
+	registered, ok := TypeByName(name)
+	require.True(t, ok)
+	require.Equal(t, reflect.TypeOf(registeredResult{}), registered)
+
+	RegisterTypeName[renamedResult]("renamed")
+	require.Equal(t, "renamed", TypeName(reflect.TypeOf(renamedResult{})))
+	require.Panics(t, func() {
+		RegisterTypeName[registeredResult]("renamed")
+	})
+
+	p := New(func() (registeredResult, renamedResult, []int) {
+		return registeredResult{}, renamedResult{}, nil
+	})
+	require.Equal(t, []string{name, "renamed", "[]int"}, p.ResultTypeNames())
+
+	_, ok = TypeByName("unregistered")
+	require.False(t, ok)
+}
@@ -0,0 +1,58 @@
+package promise
+
+import "sync/atomic"
+
+// Result waits for p to settle and returns its results boxed as
+// interface{} values, for callers that don't know p's signature at
+// compile time. The error is the same one Wait would return.
+func (p *Promise) Result() ([]interface{}, error) {
+	p.checkCopy()
+	p.observe()
+	if !p.isSettled() {
+		atomic.AddInt32(&p.waiting, 1)
+		<-p.doneChan()
+		atomic.AddInt32(&p.waiting, -1)
+	}
+	return p.boxedResults()
+}
+
+// OnComplete registers f to be called with p's results, as returned by
+// Result, once p settles, or straight away if it already has. f is called
+// on the goroutine that settles p, so it should hand off anything slow.
+// OnComplete returns p for chaining.
+func (p *Promise) OnComplete(f func(results []interface{}, err error)) *Promise {
+	p.checkCopy()
+	p.observe()
+	p.whenSettled(func() {
+		f(p.boxedResults())
+	})
+	return p
+}
+
+// OnSuccess is like OnComplete, but only calls f if p resolves.
+func (p *Promise) OnSuccess(f func(results []interface{})) *Promise {
+	return p.OnComplete(func(results []interface{}, err error) {
+		if err == nil {
+			f(results)
+		}
+	})
+}
+
+// OnError is like OnComplete, but only calls f if p is rejected.
+func (p *Promise) OnError(f func(err error)) *Promise {
+	return p.OnComplete(func(results []interface{}, err error) {
+		if err != nil {
+			f(err)
+		}
+	})
+}
+
+// boxedResults returns the results of p, which must have settled, as
+// returned by Result.
+func (p *Promise) boxedResults() ([]interface{}, error) {
+	if p.err != nil {
+		return nil, p.executionError(p.err)
+	}
+	results := p.settledResults()
+	return results.Values, results.Err
+}
@@ -0,0 +1,62 @@
+package promise
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResult(t *testing.T) {
+	values, err := New(func() (int, string) { return 1, "one" }).Result()
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{1, "one"}, values)
+
+	values, err = New(func() {}).Result()
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	values, err = All(Resolved(1), Resolved(2)).Result()
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{1, 2}, values)
+}
+
+func TestResultRejected(t *testing.T) {
+	failure := fmt.Errorf("failure")
+	values, err := New(func() (int, error) { return 0, failure }).Result()
+	require.Nil(t, values)
+	require.Equal(t, failure, cause(err))
+}
+
+func TestOnComplete(t *testing.T) {
+	release := make(chan struct{})
+	p := New(func() (int, string) {
+		<-release
+		return 1, "one"
+	})
+	completed := make(chan []interface{}, 1)
+	succeeded := make(chan []interface{}, 1)
+	failed := make(chan error, 1)
+	p.OnComplete(func(results []interface{}, err error) {
+		require.NoError(t, err)
+		completed <- results
+	}).OnSuccess(func(results []interface{}) {
+		succeeded <- results
+	}).OnError(func(err error) {
+		failed <- err
+	})
+	close(release)
+	require.Equal(t, []interface{}{1, "one"}, <-completed)
+	require.Equal(t, []interface{}{1, "one"}, <-succeeded)
+	require.NoError(t, p.Wait(new(int), new(string)))
+	require.Empty(t, failed)
+}
+
+func TestOnCompleteSettled(t *testing.T) {
+	failure := fmt.Errorf("failure")
+	p := Rejected(failure)
+	var got error
+	p.OnSuccess(func([]interface{}) { t.Fatal("OnSuccess called for a rejected promise") })
+	p.OnError(func(err error) { got = err })
+	require.Equal(t, failure, cause(got), "callbacks on settled promises run straight away")
+}
@@ -0,0 +1,62 @@
+package promise
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+)
+
+// ErrResultsDiscarded is returned when reading the results of a promise
+// that released them, as set up by WithDiscardResultsAfterWait.
+var ErrResultsDiscarded = errors.New("promise results were discarded after being read")
+
+// WithDiscardResultsAfterWait makes p release its results once they have
+// been read by Wait, Result or ToChannel and every promise chained from p
+// so far has settled, so a long-lived promise doesn't pin large results.
+// Reading the results again afterwards fails with ErrResultsDiscarded, and
+// promises chained from p later are rejected with it. It returns p for
+// chaining.
+func (p *Promise) WithDiscardResultsAfterWait() *Promise {
+	p.checkCopy()
+	atomic.StoreInt32(&p.extras().discardAfterWait, 1)
+	return p
+}
+
+// retained returns the results of p, which must have resolved, or
+// ErrResultsDiscarded if they have been released.
+func (p *Promise) retained() ([]reflect.Value, reflect.Value, error) {
+	if atomic.LoadInt32(&p.peekExtras().discardAfterWait) == 0 {
+		return p.results, p.slice, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peekExtras().discarded != 0 {
+		return nil, reflect.Value{}, ErrResultsDiscarded
+	}
+	return p.results, p.slice, nil
+}
+
+// consumed records that p's results have been read, and releases them if
+// p discards its results and nothing chained from it still needs them.
+func (p *Promise) consumed() {
+	if atomic.LoadInt32(&p.peekExtras().discardAfterWait) == 0 {
+		return
+	}
+	p.mu.Lock()
+	var waiting *Promise
+	for _, child := range p.children {
+		if !child.isSettled() {
+			waiting = child
+			break
+		}
+	}
+	if waiting == nil {
+		p.results = nil
+		p.slice = reflect.Value{}
+		atomic.StoreInt32(&p.peekExtras().discarded, 1)
+	}
+	p.mu.Unlock()
+	if waiting != nil {
+		waiting.whenSettled(p.consumed)
+	}
+}
@@ -0,0 +1,38 @@
+package promise
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscardResultsAfterWait(t *testing.T) {
+	p := New(func() []byte { return make([]byte, 1<<20) }).WithDiscardResultsAfterWait()
+	var result []byte
+	require.NoError(t, p.Wait(&result))
+	require.Len(t, result, 1<<20)
+	require.Nil(t, p.results)
+
+	require.Equal(t, ErrResultsDiscarded, p.Wait(&result))
+	_, err := p.Result()
+	require.Equal(t, ErrResultsDiscarded, err)
+	err = p.Then(func(b []byte) {}).Wait()
+	require.Equal(t, ErrResultsDiscarded, cause(err))
+}
+
+func TestDiscardResultsWaitsForChained(t *testing.T) {
+	release := make(chan struct{})
+	p := New(func() int { return 1 }).WithDiscardResultsAfterWait()
+	slow := p.Then(func(i int) int {
+		<-release
+		return i + 1
+	})
+	require.NoError(t, p.Wait(new(int)))
+	require.NoError(t, p.Wait(new(int)), "results are kept while chained promises are pending")
+
+	close(release)
+	var result int
+	require.NoError(t, slow.Wait(&result))
+	require.Equal(t, 2, result)
+	require.Equal(t, ErrResultsDiscarded, p.Wait(new(int)))
+}
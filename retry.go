@@ -0,0 +1,82 @@
+package promise
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RetryConfig configures the retries of Retry.
+type RetryConfig struct {
+	// Attempts is the most times f is called, counting the first call.
+	Attempts int
+	// Backoff is how long Retry waits, on the package's Clock, before
+	// each retry.
+	Backoff time.Duration
+	// Budget, if not nil, must hold a token for every retry. Once it runs
+	// out, Retry gives up with the last error.
+	Budget *Budget
+	// Retryable, if not nil, reports whether an error is worth retrying.
+	// Errors it reports false for reject the promise straight away.
+	Retryable func(err error) bool
+}
+
+// Retry returns a promise for New(f, args...) that calls f again, up to
+// config.Attempts times in all, while it fails. It resolves with the first
+// call to succeed, or is rejected with the error of the last call. Canceling
+// the promise cancels the call in flight and stops further retries. Retry
+// panics if config.Attempts is less than 1.
+func Retry(config RetryConfig, f interface{}, args ...interface{}) *Promise {
+	if config.Attempts < 1 {
+		panic(fmt.Errorf("Retry needs at least 1 attempt, got %d", config.Attempts))
+	}
+	first := New(f, args...)
+	d := NewDeferred(first.resultType...)
+	d.name = "Retry"
+	var mu sync.Mutex
+	current := first
+	var try func(p *Promise, attempt int)
+	try = func(p *Promise, attempt int) {
+		p.observe()
+		p.graph.addChild(p, d.Promise)
+		p.whenSettled(func() {
+			if p.err == nil {
+				d.settle(p.results, nil)
+				return
+			}
+			if d.isSettled() || attempt == config.Attempts ||
+				(config.Retryable != nil && !config.Retryable(p.err)) ||
+				(config.Budget != nil && !config.Budget.TryTake()) {
+				d.settle(nil, p.err)
+				return
+			}
+			retry := func() {
+				mu.Lock()
+				if d.isSettled() {
+					mu.Unlock()
+					return
+				}
+				next := New(f, args...)
+				current = next
+				mu.Unlock()
+				try(next, attempt+1)
+			}
+			if config.Backoff <= 0 {
+				retry()
+				return
+			}
+			timer := afterFunc(config.Backoff, retry)
+			d.whenSettled(func() { timer.Stop() })
+		})
+	}
+	d.whenSettled(func() {
+		mu.Lock()
+		p := current
+		mu.Unlock()
+		if !p.isSettled() {
+			p.Cancel()
+		}
+	})
+	try(first, 1)
+	return d.Promise
+}
@@ -0,0 +1,81 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	failure := errors.New("failure")
+	var attempts int32
+	fetch := func() (int, error) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return 0, failure
+		}
+		return 42, nil
+	}
+	var result int
+	require.NoError(t, Retry(RetryConfig{Attempts: 3}, fetch).Wait(&result))
+	require.Equal(t, 42, result)
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	atomic.StoreInt32(&attempts, 0)
+	require.Equal(t, failure, cause(Retry(RetryConfig{Attempts: 2}, fetch).Wait(&result)))
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+
+	require.Panics(t, func() { Retry(RetryConfig{}, fetch) })
+}
+
+func TestRetryOnlyRetryableErrors(t *testing.T) {
+	fatal := errors.New("fatal")
+	var attempts int32
+	fetch := func() (int, error) {
+		atomic.AddInt32(&attempts, 1)
+		return 0, fatal
+	}
+	config := RetryConfig{
+		Attempts:  3,
+		Retryable: func(err error) bool { return err != fatal },
+	}
+	require.Equal(t, fatal, cause(Retry(config, fetch).Wait(new(int))))
+	require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryBacksOff(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	var attempts int32
+	fetch := func() (int, error) {
+		atomic.AddInt32(&attempts, 1)
+		return 0, errors.New("failure")
+	}
+	p := Retry(RetryConfig{Attempts: 2, Backoff: time.Second}, fetch)
+	pollUntil(t, func() bool { return clock.pending() == 1 })
+	require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+	clock.Advance(time.Second)
+	require.Error(t, p.Wait(new(int)))
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryCancel(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var attempts int32
+	p := Retry(RetryConfig{Attempts: 3}, func() (int, error) {
+		atomic.AddInt32(&attempts, 1)
+		close(started)
+		<-release
+		return 0, errors.New("failure")
+	})
+	<-started
+	p.Cancel()
+	close(release)
+	require.Equal(t, ErrCanceled, cause(p.Wait(new(int))))
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, int32(1), atomic.LoadInt32(&attempts), "no retries after Cancel")
+}
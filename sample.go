@@ -0,0 +1,68 @@
+package promise
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A Sample describes a settled promise selected by the installed Sampler.
+type Sample struct {
+	Name    string
+	Results []interface{}
+	Err     error
+	Latency time.Duration
+	// QueueDelay and Execution split Latency into the time spent waiting
+	// to start and running, as reported by Timings.
+	QueueDelay time.Duration
+	Execution  time.Duration
+}
+
+// A Sampler passes a fraction of settled promises to Sink, so real
+// pipeline outputs can be inspected in production without logging
+// everything.
+type Sampler struct {
+	// Rate is the fraction of settled promises, between 0 and 1, that are
+	// sampled.
+	Rate float64
+	// Redact, if set, is applied to every sample before it reaches Sink.
+	Redact func(Sample) Sample
+	// Sink receives sampled promises. It is called on the goroutine that
+	// settled the promise and should not block.
+	Sink func(Sample)
+}
+
+var sampler atomic.Value
+
+// SetSampler installs s as the package-wide result sampler. Passing nil
+// disables sampling.
+func SetSampler(s *Sampler) {
+	sampler.Store(s)
+}
+
+func sampleSettled(p *Promise) {
+	s, _ := sampler.Load().(*Sampler)
+	if s == nil || s.Sink == nil {
+		return
+	}
+	if randFloat64() >= s.Rate {
+		return
+	}
+	timings := p.Timings()
+	sample := Sample{
+		Name:       p.Name(),
+		Err:        p.err,
+		Latency:    timings.Total(),
+		QueueDelay: timings.QueueDelay(),
+		Execution:  timings.Execution(),
+	}
+	if p.err == nil {
+		sample.Results = make([]interface{}, len(p.results))
+		for i, result := range p.results {
+			sample.Results[i] = result.Interface()
+		}
+	}
+	if s.Redact != nil {
+		sample = s.Redact(sample)
+	}
+	s.Sink(sample)
+}
@@ -0,0 +1,52 @@
+package promise
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sampledSecret() (int, string) {
+	return 7, "hunter2"
+}
+
+func TestSamplerRedactsAndSinksResults(t *testing.T) {
+	name := funcName(reflect.ValueOf(sampledSecret))
+	samples := make(chan Sample, 1)
+	SetSampler(&Sampler{
+		Rate: 1,
+		Redact: func(s Sample) Sample {
+			if s.Name == name {
+				s.Results[1] = "<redacted>"
+			}
+			return s
+		},
+		Sink: func(s Sample) {
+			if s.Name == name {
+				samples <- s
+			}
+		},
+	})
+	defer SetSampler(nil)
+
+	var id int
+	var secret string
+	err := New(sampledSecret).Wait(&id, &secret)
+	require.NoError(t, err)
+	sample := <-samples
+	require.Equal(t, []interface{}{7, "<redacted>"}, sample.Results)
+	require.NoError(t, sample.Err)
+}
+
+func TestSamplerRateZeroSamplesNothing(t *testing.T) {
+	sampled := false
+	SetSampler(&Sampler{
+		Rate: 0,
+		Sink: func(Sample) { sampled = true },
+	})
+	defer SetSampler(nil)
+
+	require.NoError(t, New(func() {}).Wait())
+	require.False(t, sampled)
+}
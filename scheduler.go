@@ -0,0 +1,70 @@
+package promise
+
+import "sync/atomic"
+
+// A Scheduler runs the functions of promises. Submit must not block; it
+// queues f or starts running it. *Pool satisfies Scheduler.
+//
+// Promises are only submitted once the promises they depend on have
+// settled, so a bounded scheduler can't deadlock on chains, but a
+// function that itself blocks on another promise holds on to its worker
+// while it waits.
+type Scheduler interface {
+	Submit(f func())
+}
+
+// A PriorityScheduler is a Scheduler that can run some functions ahead of
+// others. The package submits the functions of promises given a priority
+// with WithPriority through SubmitPriority. *Pool satisfies
+// PriorityScheduler.
+type PriorityScheduler interface {
+	Scheduler
+	// SubmitPriority is like Submit, but functions with a higher
+	// priority should run first. Submit has priority zero.
+	SubmitPriority(f func(), priority int)
+}
+
+type schedulerHolder struct {
+	Scheduler
+}
+
+var scheduler atomic.Value
+
+// SetScheduler runs promises on s, such as SetScheduler(NewPool(64)),
+// instead of starting a goroutine for each one. Passing nil restores the
+// default of one goroutine per promise.
+func SetScheduler(s Scheduler) {
+	scheduler.Store(schedulerHolder{s})
+}
+
+func schedule(f func()) {
+	scheduleAt(0, f)
+}
+
+// scheduleAt is like schedule, for a function with the given priority,
+// which only schedulers implementing PriorityScheduler take into account.
+func scheduleAt(priority int, f func()) {
+	scheduleOn(nil, priority, f)
+}
+
+// scheduleOn is like scheduleAt, but runs f on s unless s is nil.
+func scheduleOn(s Scheduler, priority int, f func()) {
+	queueAdd(1)
+	run := func() {
+		queueAdd(-1)
+		f()
+	}
+	if s == nil {
+		holder, _ := scheduler.Load().(schedulerHolder)
+		s = holder.Scheduler
+	}
+	if ps, ok := s.(PriorityScheduler); ok && priority != 0 {
+		ps.SubmitPriority(run, priority)
+		return
+	}
+	if s != nil {
+		s.Submit(run)
+		return
+	}
+	spawn(run)
+}
@@ -0,0 +1,53 @@
+package promise
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerBoundsGoroutines(t *testing.T) {
+	pool := NewPool(4)
+	SetScheduler(pool)
+	defer SetScheduler(nil)
+
+	before := runtime.NumGoroutine()
+	release := make(chan struct{})
+	promises := make([]*Promise, 1000)
+	for i := range promises {
+		promises[i] = New(func() int {
+			<-release
+			return 1
+		}).Then(func(i int) int {
+			return i + 1
+		})
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine()-before, 10)
+	close(release)
+
+	var sum int
+	err := All(promises...).Then(func(values ...int) int {
+		total := 0
+		for _, v := range values {
+			total += v
+		}
+		return total
+	}).Wait(&sum)
+	require.NoError(t, err)
+	require.Equal(t, 2000, sum)
+	require.LessOrEqual(t, pool.Workers(), 4)
+}
+
+func TestSchedulerRunsDeepChainsOnOneWorker(t *testing.T) {
+	SetScheduler(NewPool(1))
+	defer SetScheduler(nil)
+
+	p := New(func() int { return 0 })
+	for i := 0; i < 100; i++ {
+		p = p.Then(func(i int) int { return i + 1 })
+	}
+	var result int
+	require.NoError(t, All(p, p.Catch(func(error) int { return -1 })).Wait(&result, new(int)))
+	require.Equal(t, 100, result)
+}
@@ -0,0 +1,111 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// A Scope tracks the promises of a call of WithScope. Its methods must not
+// be called once WithScope has returned.
+type Scope struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	group  Group
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// WithScope calls body with a Scope, and doesn't return until every
+// promise added to the scope has settled, so that a request handler can't
+// leak promises that outlive it:
+//
+//	err := promise.WithScope(r.Context(), func(s *promise.Scope) error {
+//		user := s.NewCtx(loadUser, id)
+//		orders := s.NewCtx(loadOrders, id)
+//		return promise.All(user, orders).Wait(&u, &o)
+//	})
+//
+// The first promise of the scope to be rejected, or body returning an
+// error, cancels the scope's context, which rejects the others that are
+// still pending. WithScope returns body's error, or else the error of
+// the first promise to be rejected. If body panics, the scope is canceled
+// and waited for before the panic continues.
+func WithScope(ctx context.Context, body func(s *Scope) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Scope{ctx: ctx, cancel: cancel}
+	defer func() {
+		if r := recover(); r != nil {
+			cancel()
+			s.close()
+			panic(r)
+		}
+	}()
+	err := body(s)
+	if err != nil {
+		cancel()
+	}
+	if groupErr := s.close(); err == nil {
+		err = groupErr
+	}
+	return err
+}
+
+// Context returns the scope's context, which is canceled once a promise
+// of the scope fails, body returns an error, or WithScope returns.
+func (s *Scope) Context() context.Context {
+	return s.ctx
+}
+
+// New is like the package's New, for a promise added to s.
+func (s *Scope) New(f interface{}, args ...interface{}) *Promise {
+	return s.Add(New(f, args...))
+}
+
+// NewCtx is like the package's NewCtx with the scope's context, for a
+// promise added to s.
+func (s *Scope) NewCtx(f interface{}, args ...interface{}) *Promise {
+	return s.Add(NewCtx(s.ctx, f, args...))
+}
+
+// Add adds p, such as a promise chained from one of the scope's, to s,
+// tying it to the scope's context, and returns p.
+func (s *Scope) Add(p *Promise) *Promise {
+	p.checkCopy()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		panic(errors.New("promise: Scope used after WithScope returned"))
+	}
+	p.watchContext(s.ctx)
+	s.group.add(p, nil)
+	// Registered after the group's continuation, so that the group
+	// records p's error before the siblings it cancels.
+	p.whenSettled(func() {
+		if p.err != nil {
+			s.cancel()
+		}
+	})
+	return p
+}
+
+// close waits for the scope's promises, including any they add while it
+// waits, then stops further ones from being added and returns the error
+// of the first of them to be rejected.
+func (s *Scope) close() error {
+	for {
+		err := s.group.Wait()
+		s.mu.Lock()
+		s.group.mu.Lock()
+		idle := s.group.settled == len(s.group.promises)
+		s.group.mu.Unlock()
+		if idle {
+			s.closed = true
+			s.mu.Unlock()
+			s.cancel()
+			return err
+		}
+		s.mu.Unlock()
+	}
+}
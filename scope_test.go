@@ -0,0 +1,71 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithScope(t *testing.T) {
+	var inner *Promise
+	var out int
+	err := WithScope(context.Background(), func(s *Scope) error {
+		p := s.New(func() int { return 1 })
+		// Work added by the scope's own promises is waited for too.
+		s.New(func() {
+			inner = s.New(func() int { return 2 })
+		})
+		return p.Wait(&out)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, out)
+	require.Equal(t, StateFulfilled, inner.State())
+}
+
+func TestWithScopeCancelsSiblings(t *testing.T) {
+	var sibling *Promise
+	err := WithScope(context.Background(), func(s *Scope) error {
+		sibling = s.NewCtx(func(ctx context.Context) {
+			<-ctx.Done()
+		})
+		s.New(func() error { return errors.New("failed") })
+		return nil
+	})
+	require.EqualError(t, cause(err), "failed")
+	// Whether the sibling is rejected for the cancellation or its function
+	// returns first is a race, but either way it has settled.
+	require.True(t, sibling.isSettled())
+}
+
+func TestWithScopeBodyError(t *testing.T) {
+	var p *Promise
+	err := WithScope(context.Background(), func(s *Scope) error {
+		p = s.Add(NewDeferred().Promise)
+		return errors.New("bad request")
+	})
+	require.EqualError(t, err, "bad request")
+	require.Equal(t, context.Canceled, cause(p.Wait()))
+}
+
+func TestScopeUsedAfterReturn(t *testing.T) {
+	var scope *Scope
+	require.NoError(t, WithScope(context.Background(), func(s *Scope) error {
+		scope = s
+		return nil
+	}))
+	require.Error(t, scope.Context().Err())
+	require.Panics(t, func() { scope.New(func() {}) })
+}
+
+func TestWithScopePanics(t *testing.T) {
+	var p *Promise
+	require.Panics(t, func() {
+		WithScope(context.Background(), func(s *Scope) error {
+			p = s.Add(NewDeferred().Promise)
+			panic("boom")
+		})
+	})
+	require.Equal(t, StateRejected, p.State())
+}
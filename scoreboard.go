@@ -0,0 +1,117 @@
+package promise
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// scoreboardWeight is how much each new outcome moves an alternative's
+// averages, so that recent behavior counts for more than old.
+const scoreboardWeight = 0.2
+
+// healthyRate is the success rate below which an alternative is ranked
+// after every healthy one.
+const healthyRate = 0.5
+
+// A Scoreboard records how named alternatives, such as the hosts serving
+// the same request, have behaved recently, and ranks them fastest healthy
+// first. Alternatives it hasn't seen yet rank first, so they get tried.
+// A Scoreboard is safe for concurrent use.
+type Scoreboard struct {
+	mu     sync.Mutex
+	scores map[string]*score
+}
+
+type score struct {
+	successRate float64
+	latency     time.Duration
+}
+
+// NewScoreboard returns an empty Scoreboard.
+func NewScoreboard() *Scoreboard {
+	return &Scoreboard{scores: map[string]*score{}}
+}
+
+// Record adds the outcome of a call to the alternative name.
+func (s *Scoreboard) Record(name string, latency time.Duration, err error) {
+	success := 0.0
+	if err == nil {
+		success = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.scores[name]
+	if !ok {
+		s.scores[name] = &score{successRate: success, latency: latency}
+		return
+	}
+	sc.successRate += scoreboardWeight * (success - sc.successRate)
+	sc.latency += time.Duration(scoreboardWeight * float64(latency-sc.latency))
+}
+
+// Track records the outcome of p against the alternative name once it
+// settles, and returns p. Promises canceled before they settled on their
+// own, such as the losers of a race, aren't recorded.
+func (s *Scoreboard) Track(name string, p *Promise) *Promise {
+	p.whenSettled(func() {
+		if errors.Is(p.err, ErrCanceled) {
+			return
+		}
+		s.Record(name, p.Timings().Total(), p.err)
+	})
+	return p
+}
+
+// Order returns a copy of names sorted by rank: healthy alternatives by
+// how quickly they have been settling, then unhealthy ones by success
+// rate. Names that rank equally keep their order.
+func (s *Scoreboard) Order(names []string) []string {
+	s.mu.Lock()
+	scores := make([]score, len(names))
+	seen := make([]bool, len(names))
+	for i, name := range names {
+		if sc, ok := s.scores[name]; ok {
+			scores[i], seen[i] = *sc, true
+		}
+	}
+	s.mu.Unlock()
+
+	idx := make([]int, len(names))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		i, j := idx[a], idx[b]
+		if seen[i] != seen[j] {
+			return !seen[i]
+		}
+		healthyI, healthyJ := scores[i].successRate >= healthyRate, scores[j].successRate >= healthyRate
+		if healthyI != healthyJ {
+			return healthyI
+		}
+		if !healthyI && scores[i].successRate != scores[j].successRate {
+			return scores[i].successRate > scores[j].successRate
+		}
+		return scores[i].latency < scores[j].latency
+	})
+	ordered := make([]string, len(names))
+	for i, k := range idx {
+		ordered[i] = names[k]
+	}
+	return ordered
+}
+
+// Any is like the package's Any over the promises returned by calling
+// factory with each of names, except that the factories are called in
+// the order ranked by s, so the best alternatives get a head start, and
+// the outcome of each is recorded in s.
+func (s *Scoreboard) Any(names []string, factory func(name string) *Promise) *Promise {
+	ordered := s.Order(names)
+	promises := make([]*Promise, len(ordered))
+	for i, name := range ordered {
+		promises[i] = s.Track(name, factory(name))
+	}
+	return Any(promises...)
+}
@@ -0,0 +1,59 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreboardOrder(t *testing.T) {
+	s := NewScoreboard()
+	s.Record("slow", 100*time.Millisecond, nil)
+	s.Record("fast", 10*time.Millisecond, nil)
+	s.Record("broken", time.Millisecond, errors.New("down"))
+	s.Record("flaky", 50*time.Millisecond, errors.New("down"))
+	s.Record("flaky", 50*time.Millisecond, nil)
+
+	names := []string{"broken", "flaky", "slow", "new", "fast"}
+	require.Equal(t, []string{"new", "fast", "slow", "flaky", "broken"}, s.Order(names))
+	require.Equal(t, []string{"broken", "flaky", "slow", "new", "fast"}, names, "Order doesn't modify its argument")
+}
+
+func TestScoreboardRecoversWithRecentSuccesses(t *testing.T) {
+	s := NewScoreboard()
+	s.Record("a", time.Millisecond, errors.New("down"))
+	s.Record("b", time.Second, nil)
+	require.Equal(t, []string{"b", "a"}, s.Order([]string{"a", "b"}))
+	for i := 0; i < 5; i++ {
+		s.Record("a", time.Millisecond, nil)
+	}
+	require.Equal(t, []string{"a", "b"}, s.Order([]string{"a", "b"}))
+}
+
+func TestScoreboardAny(t *testing.T) {
+	s := NewScoreboard()
+	s.Record("primary", time.Millisecond, errors.New("down"))
+	var called []string
+	p := s.Any([]string{"primary", "secondary"}, func(name string) *Promise {
+		called = append(called, name)
+		if name == "primary" {
+			return Rejected(errors.New("still down"), typeOf[string]())
+		}
+		return Resolved(name)
+	})
+	var got string
+	require.NoError(t, p.Wait(&got))
+	require.Equal(t, "secondary", got)
+	require.Equal(t, []string{"secondary", "primary"}, called)
+}
+
+func TestScoreboardTrackIgnoresCanceled(t *testing.T) {
+	s := NewScoreboard()
+	d := NewDeferred()
+	s.Track("a", d.Promise)
+	d.Promise.Cancel()
+	s.Record("b", time.Second, nil)
+	require.Equal(t, []string{"a", "b"}, s.Order([]string{"b", "a"}))
+}
@@ -0,0 +1,34 @@
+package promise
+
+// ThenSerial is like Then, except that continuations attached to p with
+// ThenSerial run one at a time, in the order they were attached. Each one
+// starts once p has resolved and the previous serial continuation has
+// settled, whether or not it succeeded. Continuations attached with Then
+// are not ordered relative to them.
+func (p *Promise) ThenSerial(f interface{}) *Promise {
+	return p.then(f, func(next *Promise) {
+		p.mu.Lock()
+		tail := p.extras()
+		next.extras().serialPrev = tail.serialTail
+		tail.serialTail = next
+		// p and the next sibling keep pointers to next.
+		next.pin()
+		p.mu.Unlock()
+	})
+}
+
+// awaitSerialPrev blocks until the sibling attached before p with
+// ThenSerial has settled, or p itself has.
+func (p *Promise) awaitSerialPrev() {
+	x := p.peekExtras()
+	prev := x.serialPrev
+	if prev == nil {
+		return
+	}
+	select {
+	case <-prev.doneChan():
+	case <-p.doneChan():
+	}
+	// Don't keep every earlier sibling reachable.
+	x.serialPrev = nil
+}
@@ -0,0 +1,46 @@
+package promise
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThenSerialRunsSiblingsInOrder(t *testing.T) {
+	signal := Signal()
+	var mu sync.Mutex
+	order := []int{}
+	siblings := []*Promise{}
+	for i := 0; i < 10; i++ {
+		i := i
+		siblings = append(siblings, signal.Promise().ThenSerial(func() {
+			// Give later siblings the chance to overtake if they could.
+			time.Sleep(time.Duration(10-i) * time.Millisecond)
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}))
+	}
+	signal.Resolve()
+	require.NoError(t, All(siblings...).Wait())
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, order)
+}
+
+func TestThenSerialContinuesAfterFailedSibling(t *testing.T) {
+	signal := Signal()
+	failed := signal.Promise().ThenSerial(func() {
+		time.Sleep(10 * time.Millisecond)
+		panic("failed")
+	})
+	next := signal.Promise().ThenSerial(func() bool {
+		return failed.isSettled()
+	})
+	signal.Resolve()
+
+	var ranAfter bool
+	require.NoError(t, next.Wait(&ranAfter))
+	require.True(t, ranAfter)
+	require.Error(t, failed.Wait())
+}
@@ -0,0 +1,65 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Series calls each factory only once the promise of the one before it
+// has resolved, so the tasks run strictly one after another, and resolves
+// with the results of every promise in order. The first failure rejects
+// the returned promise without calling the remaining factories. Like
+// AllWithLimit, every factory must return a promise with the same result
+// types.
+func Series(factories ...func() *Promise) *Promise {
+	return AllWithLimit(1, factories...)
+}
+
+// Each calls f on the elements of slice one at a time, in order, and
+// resolves with a slice of the results. f accepts an element and either
+// returns a single value, optionally followed by an error, or returns a
+// *Promise resolving with a single value, in which case the next element
+// is only processed once that promise has resolved. For an empty slice,
+// the returned promise resolves with an empty slice, or with no values if
+// f returns a *Promise, whose result types are unknown until it is called.
+func Each(slice interface{}, f interface{}) *Promise {
+	sliceRv := reflect.ValueOf(slice)
+	if sliceRv.Kind() != reflect.Slice {
+		panic(fmt.Errorf("expected a slice, got %v", sliceRv.Kind()))
+	}
+	functionRv := reflect.ValueOf(f)
+	if functionRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", functionRv.Kind()))
+	}
+	fnType := functionRv.Type()
+	if fnType.NumIn() != 1 || fnType.IsVariadic() || !sliceRv.Type().Elem().AssignableTo(fnType.In(0)) {
+		panic(fmt.Errorf("function must accept a single %s, got %s", sliceRv.Type().Elem(), fnType))
+	}
+	resultType, _ := getResultType(fnType)
+	if len(resultType) != 1 {
+		panic(fmt.Errorf("function must return a single value, got %s", fnType))
+	}
+	if sliceRv.Len() == 0 && resultType[0] != promisePtrType {
+		empty := reflect.MakeSlice(reflect.SliceOf(resultType[0]), 0, 0)
+		return resolvedAs(empty.Type(), empty.Interface())
+	}
+	elemType := sliceRv.Type().Elem()
+	factories := make([]func() *Promise, sliceRv.Len())
+	for i := range factories {
+		elem := sliceRv.Index(i).Interface()
+		if resultType[0] == promisePtrType {
+			factories[i] = func() *Promise {
+				arg := reflect.New(elemType).Elem()
+				if elem != nil {
+					arg.Set(reflect.ValueOf(elem))
+				}
+				return functionRv.Call([]reflect.Value{arg})[0].Interface().(*Promise)
+			}
+			continue
+		}
+		factories[i] = func() *Promise {
+			return resolvedAs(elemType, elem).Then(f)
+		}
+	}
+	return Series(factories...)
+}
@@ -0,0 +1,67 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesRunsOneAtATime(t *testing.T) {
+	var running int32
+	overlapped := false
+	factories := make([]func() *Promise, 5)
+	for i := range factories {
+		i := i
+		factories[i] = func() *Promise {
+			return New(func() int {
+				if atomic.AddInt32(&running, 1) > 1 {
+					overlapped = true
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return i
+			})
+		}
+	}
+	var results []int
+	require.NoError(t, Series(factories...).Wait(&results))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, results)
+	require.False(t, overlapped)
+}
+
+func TestEach(t *testing.T) {
+	var order []string
+	var results []int
+	err := Each([]string{"a", "bb", "ccc"}, func(s string) int {
+		order = append(order, s)
+		return len(s)
+	}).Wait(&results)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, results)
+	require.Equal(t, []string{"a", "bb", "ccc"}, order)
+}
+
+func TestEachWithPromises(t *testing.T) {
+	var calls int32
+	err := Each([]int{1, 2, 3}, func(i int) *Promise {
+		atomic.AddInt32(&calls, 1)
+		return New(func() (int, error) {
+			if i == 2 {
+				return 0, errors.New("failed")
+			}
+			return i, nil
+		})
+	}).Wait(new([]int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed")
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestEachEmpty(t *testing.T) {
+	var results []int
+	require.NoError(t, Each([]string{}, func(s string) int { return len(s) }).Wait(&results))
+	require.Empty(t, results)
+}
@@ -0,0 +1,37 @@
+package promise
+
+import "reflect"
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// Resolved returns a promise already resolved with values, without
+// starting a goroutine. Its result types are the dynamic types of values;
+// a nil value has type interface{}.
+func Resolved(values ...interface{}) *Promise {
+	p := newPromise(signalCall, "Resolved")
+	p.resultType = make([]reflect.Type, len(values))
+	results := make([]reflect.Value, len(values))
+	for i, value := range values {
+		if value == nil {
+			p.resultType[i] = interfaceType
+			results[i] = reflect.Zero(interfaceType)
+			continue
+		}
+		results[i] = reflect.ValueOf(value)
+		p.resultType[i] = results[i].Type()
+	}
+	newGraph(p)
+	p.settle(results, nil)
+	return p
+}
+
+// Rejected returns a promise already rejected with err, without starting
+// a goroutine. types are the result types the promise would have resolved
+// with, so that it can stand in for another promise in a chain.
+func Rejected(err error, types ...reflect.Type) *Promise {
+	p := newPromise(signalCall, "Rejected")
+	p.resultType = append([]reflect.Type{}, types...)
+	newGraph(p)
+	p.settle(nil, err)
+	return p
+}
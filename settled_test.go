@@ -0,0 +1,33 @@
+package promise
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolved(t *testing.T) {
+	var s string
+	var i int
+	var v interface{}
+	require.NoError(t, Resolved("a", 1, nil).Wait(&s, &i, &v))
+	require.Equal(t, "a", s)
+	require.Equal(t, 1, i)
+	require.Nil(t, v)
+
+	var doubled int
+	require.NoError(t, Resolved(21).Then(func(i int) int { return i * 2 }).Wait(&doubled))
+	require.Equal(t, 42, doubled)
+}
+
+func TestRejected(t *testing.T) {
+	p := Rejected(errors.New("not found"), reflect.TypeOf(0))
+	called := false
+	next := p.Then(func(int) { called = true })
+	err := next.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+	require.False(t, called)
+}
@@ -0,0 +1,65 @@
+package promise
+
+import (
+	"fmt"
+	"sync"
+)
+
+type sharedCall struct {
+	ready chan struct{}
+	p     *Promise
+}
+
+var shared = struct {
+	sync.Mutex
+	calls map[string]*sharedCall
+}{calls: map[string]*sharedCall{}}
+
+// Shared deduplicates work in flight, like golang.org/x/sync/singleflight:
+// it returns the promise factory returns, but while a promise made for key
+// is pending, later calls with the same key return that promise instead of
+// calling factory again. All of them see its results, as any number of
+// callers can wait on a promise. Once it settles, the next call with key
+// calls factory again. Keys are global to the program, so packages should
+// qualify theirs, as in "users:" + id.
+func Shared(key string, factory func() *Promise) *Promise {
+	shared.Lock()
+	if call, ok := shared.calls[key]; ok {
+		shared.Unlock()
+		<-call.ready
+		return call.p
+	}
+	call := &sharedCall{ready: make(chan struct{})}
+	shared.calls[key] = call
+	shared.Unlock()
+
+	// factory runs without the lock held, so that it may call Shared
+	// itself; callers with the same key wait for it to return.
+	defer close(call.ready)
+	defer func() {
+		if r := recover(); r != nil {
+			forgetShared(key, call)
+			call.p = Rejected(panicError(r))
+			panic(r)
+		}
+	}()
+	p := factory()
+	if p == nil {
+		panic(fmt.Errorf("factory for %q returned a nil promise", key))
+	}
+	call.p = p
+	p.whenSettled(func() {
+		forgetShared(key, call)
+	})
+	return p
+}
+
+// forgetShared removes call from the calls in flight, unless a later call
+// for key has already replaced it.
+func forgetShared(key string, call *sharedCall) {
+	shared.Lock()
+	if shared.calls[key] == call {
+		delete(shared.calls, key)
+	}
+	shared.Unlock()
+}
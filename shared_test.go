@@ -0,0 +1,66 @@
+package promise
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShared(t *testing.T) {
+	release := NewDeferred()
+	var calls int32
+	fetch := func() *Promise {
+		return Shared("shared-test", func() *Promise {
+			atomic.AddInt32(&calls, 1)
+			return release.Then(func() string { return "result" })
+		})
+	}
+
+	var wg sync.WaitGroup
+	promises := make([]*Promise, 10)
+	for i := range promises {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			promises[i] = fetch()
+		}(i)
+	}
+	wg.Wait()
+	release.Resolve()
+	for _, p := range promises {
+		require.Same(t, promises[0], p)
+		var s string
+		require.NoError(t, p.Wait(&s))
+		require.Equal(t, "result", s)
+	}
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	// Once settled, the key is free again.
+	require.True(t, fetch() != promises[0])
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestSharedKeysAreIndependent(t *testing.T) {
+	pending := NewDeferred()
+	a := Shared("shared-a", func() *Promise { return pending.Promise })
+	b := Shared("shared-b", func() *Promise {
+		// factory may use Shared itself.
+		return Shared("shared-c", func() *Promise { return Resolved(1) })
+	})
+	require.True(t, a != b)
+	require.NoError(t, b.Wait(new(int)))
+	pending.Resolve()
+}
+
+func TestSharedFactoryPanics(t *testing.T) {
+	require.Panics(t, func() {
+		Shared("shared-panic", func() *Promise { panic("boom") })
+	})
+	require.Panics(t, func() {
+		Shared("shared-nil", func() *Promise { return nil })
+	})
+	p := Shared("shared-panic", func() *Promise { return Resolved() })
+	require.NoError(t, p.Wait())
+}
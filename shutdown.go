@@ -0,0 +1,72 @@
+package promise
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// OnSignal returns a promise that resolves, with no values, once the
+// process receives one of sigs, or os.Interrupt or SIGTERM if none are
+// given. Racing it against work that resolves with no values stops the
+// work on shutdown:
+//
+//	err := Race(New(serve), OnSignal()).Wait()
+//
+// SIGURG, which the Go runtime sends itself to preempt goroutines, never
+// resolves it.
+//
+// Use RaceIndexed to tell which one settled. The signals are delivered to
+// the promise, and not to the process's default handling, until it
+// settles; canceling it, as Race does once the work finishes first, stops
+// listening.
+func OnSignal(sigs ...os.Signal) *Promise {
+	s := Signal()
+	s.p.name = "OnSignal"
+	if len(sigs) == 0 {
+		sigs = shutdownSignals
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	settled := make(chan struct{})
+	s.p.whenSettled(func() { close(settled) })
+	spawn(func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case sig := <-ch:
+				if isPreemptSignal(sig) {
+					continue
+				}
+				s.Resolve()
+				return
+			case <-settled:
+				return
+			}
+		}
+	})
+	return s.p
+}
+
+// OnDone returns a promise that resolves, with no values, once ctx is
+// done, for racing work against a shutdown context the way OnSignal races
+// it against signals. Unlike NewCtx, it resolves rather than rejecting
+// with ctx.Err(). Canceling the promise stops watching ctx.
+func OnDone(ctx context.Context) *Promise {
+	s := Signal()
+	s.p.name = "OnDone"
+	if ctx.Err() != nil {
+		s.Resolve()
+		return s.p
+	}
+	settled := make(chan struct{})
+	s.p.whenSettled(func() { close(settled) })
+	spawn(func() {
+		select {
+		case <-ctx.Done():
+			s.Resolve()
+		case <-settled:
+		}
+	})
+	return s.p
+}
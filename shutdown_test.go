@@ -0,0 +1,50 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnSignal(t *testing.T) {
+	shutdown := OnSignal(syscall.SIGUSR1)
+	release := make(chan struct{})
+	defer close(release)
+	work := New(func() { <-release })
+	race := RaceIndexed(work, shutdown)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	var result RaceResult
+	require.NoError(t, race.Wait(&result))
+	require.Equal(t, 1, result.Index)
+}
+
+func TestOnSignalDefaults(t *testing.T) {
+	shutdown := OnSignal()
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGURG))
+	require.Equal(t, ErrWaitTimeout, shutdown.WaitTimeout(50*time.Millisecond), "SIGURG is not a shutdown")
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	require.NoError(t, shutdown.Wait())
+}
+
+func TestOnSignalStopsWhenCanceled(t *testing.T) {
+	before := InFlight()
+	shutdown := OnSignal(syscall.SIGUSR2)
+	require.NoError(t, Race(New(func() {}), shutdown).Wait())
+	require.True(t, errors.Is(shutdown.Wait(), ErrCanceled))
+	pollUntil(t, func() bool { return InFlight() <= before })
+}
+
+func TestOnDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	race := Race(New(func() { <-release }), OnDone(ctx))
+	cancel()
+	require.NoError(t, race.Wait())
+
+	require.NoError(t, OnDone(ctx).Wait())
+}
@@ -0,0 +1,41 @@
+package promise
+
+import (
+	"errors"
+	"reflect"
+)
+
+// A SignalHandle settles a promise that carries no values, only completion
+// or failure. Signals run no function and start no goroutine, which makes
+// them a cheap synchronization point, such as "config loaded", to chain
+// from or join with All.
+type SignalHandle struct {
+	p *Promise
+}
+
+// Signal returns a handle to a new, pending signal promise.
+func Signal() *SignalHandle {
+	p := newPromise(signalCall, "Signal")
+	newGraph(p)
+	return &SignalHandle{p: p}
+}
+
+// Promise returns the promise settled by s.
+func (s *SignalHandle) Promise() *Promise {
+	return s.p
+}
+
+// Resolve fulfills the signal. Only the first call to Resolve or Reject has
+// any effect.
+func (s *SignalHandle) Resolve() {
+	s.p.settle([]reflect.Value{}, nil)
+}
+
+// Reject fails the signal with err, which must not be nil. Only the first
+// call to Resolve or Reject has any effect.
+func (s *SignalHandle) Reject(err error) {
+	if err == nil {
+		panic(errors.New("promise: SignalHandle.Reject called with a nil error"))
+	}
+	s.p.settle(nil, err)
+}
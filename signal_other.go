@@ -0,0 +1,15 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package promise
+
+import "os"
+
+// shutdownSignals are the signals OnSignal waits for by default.
+var shutdownSignals = []os.Signal{os.Interrupt}
+
+// isPreemptSignal reports whether sig is the signal the runtime uses to
+// preempt goroutines, which is never delivered to programs here.
+func isPreemptSignal(sig os.Signal) bool {
+	return false
+}
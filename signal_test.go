@@ -0,0 +1,38 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalResolve(t *testing.T) {
+	configLoaded := Signal()
+	ready := configLoaded.Promise().Then(func() string {
+		return "ready"
+	})
+	configLoaded.Resolve()
+	configLoaded.Reject(errors.New("ignored"))
+
+	var result string
+	require.NoError(t, ready.Wait(&result))
+	require.Equal(t, "ready", result)
+	require.NoError(t, configLoaded.Promise().Wait())
+}
+
+func TestSignalRejectFailsJoin(t *testing.T) {
+	a, b := Signal(), Signal()
+	all := All(a.Promise(), b.Promise())
+	a.Resolve()
+	b.Reject(errors.New("config missing"))
+	err := all.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "config missing")
+}
+
+func TestSignalRejectNil(t *testing.T) {
+	s := Signal()
+	require.Panics(t, func() { s.Reject(nil) })
+	require.Equal(t, StatePending, s.Promise().State())
+}
@@ -0,0 +1,18 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package promise
+
+import (
+	"os"
+	"syscall"
+)
+
+// shutdownSignals are the signals OnSignal waits for by default.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// isPreemptSignal reports whether sig is the signal the runtime uses to
+// preempt goroutines, which says nothing about shutting down.
+func isPreemptSignal(sig os.Signal) bool {
+	return sig == syscall.SIGURG
+}
@@ -0,0 +1,38 @@
+package promise
+
+import (
+	"reflect"
+	"sync"
+)
+
+// A signature is the analysis of a function type that New and Then need
+// for every promise they create from a function of that type.
+type signature struct {
+	// in are the parameter types. The slice is shared and must be copied
+	// before it is modified.
+	in []reflect.Type
+	// resultType and returnsError are as returned by getResultType, and
+	// shared like in.
+	resultType   []reflect.Type
+	returnsError bool
+}
+
+// signatures caches the signature of each function type seen, keyed by
+// reflect.Type.
+var signatures sync.Map
+
+// signatureOf returns the signature of t, a function type.
+func signatureOf(t reflect.Type) *signature {
+	if sig, ok := signatures.Load(t); ok {
+		return sig.(*signature)
+	}
+	sig := &signature{in: make([]reflect.Type, t.NumIn())}
+	for i := range sig.in {
+		sig.in[i] = t.In(i)
+	}
+	resultType, returnsError := getResultType(t)
+	// Cap the results so appending to them never writes into the cache.
+	sig.resultType, sig.returnsError = resultType[:len(resultType):len(resultType)], returnsError
+	actual, _ := signatures.LoadOrStore(t, sig)
+	return actual.(*signature)
+}
@@ -0,0 +1,33 @@
+package promise
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureOfIsCached(t *testing.T) {
+	ft := reflect.TypeOf(func(int, string) (bool, error) { return false, nil })
+	sig := signatureOf(ft)
+	require.Same(t, sig, signatureOf(ft))
+	require.Equal(t, []reflect.Type{typeOf[int](), typeOf[string]()}, sig.in)
+	require.Equal(t, []reflect.Type{typeOf[bool]()}, sig.resultType)
+	require.True(t, sig.returnsError)
+}
+
+func TestVariadicThenLeavesSignatureIntact(t *testing.T) {
+	sum := func(xs ...int) int {
+		total := 0
+		for _, x := range xs {
+			total += x
+		}
+		return total
+	}
+	var one, three int
+	require.NoError(t, Resolved(1).Then(sum).Wait(&one))
+	require.NoError(t, All(Resolved(1), Resolved(2), Resolved(3)).Then(sum).Wait(&three))
+	require.Equal(t, 1, one)
+	require.Equal(t, 6, three)
+	require.Equal(t, []reflect.Type{typeOf[[]int]()}, signatureOf(reflect.TypeOf(sum)).in)
+}
@@ -0,0 +1,236 @@
+package promise
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// An SLO describes the service level expected of promises with a given
+// name, measured from creation to settlement over a sliding window.
+// Tracking costs a fixed amount of memory and time per settled promise,
+// however many promises the window holds: the window moves in steps of a
+// twentieth of its length, and latencies are kept in a histogram whose
+// buckets are about 40% wide.
+type SLO struct {
+	// Percentile is the fraction of promises, between 0 and 1, that must
+	// settle within LatencyTarget. Zero disables the latency objective.
+	Percentile    float64
+	LatencyTarget time.Duration
+	// MaxErrorRate is the largest tolerated fraction of rejected promises.
+	// Zero disables the error rate objective.
+	MaxErrorRate float64
+	// Window is how far back settled promises are considered. Zero
+	// considers every promise settled since SetSLO.
+	Window time.Duration
+	// MinSamples is the number of settled promises required in the window
+	// before the SLO is evaluated.
+	MinSamples int
+	// OnBreach is called when the SLO goes from met to breached.
+	OnBreach func(SLOBreach)
+}
+
+// SLOBreach describes a breached SLO.
+type SLOBreach struct {
+	Name    string
+	Samples int
+	// Latency is the observed latency at the SLO's Percentile, rounded up
+	// to the bound of its histogram bucket. Whether it breached the
+	// target is decided on exact latencies.
+	Latency   time.Duration
+	ErrorRate float64
+	// LatencyBreached and ErrorRateBreached report which objective failed.
+	LatencyBreached   bool
+	ErrorRateBreached bool
+}
+
+type sloSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+const (
+	// sloSlots is the number of steps the window of an SLO moves in.
+	sloSlots = 20
+	// sloBuckets is the number of buckets of the latency histograms. The
+	// bound of bucket i is 2^(i/2) microseconds, and the last bucket, up
+	// to about 50 minutes, also takes anything slower.
+	sloBuckets = 64
+)
+
+// sloCounts tallies the samples in a slot of the window, or in all of it.
+type sloCounts struct {
+	samples int
+	failed  int
+	// over counts the samples slower than the SLO's LatencyTarget
+	over    int
+	buckets [sloBuckets]int32
+}
+
+func (c *sloCounts) add(other *sloCounts, sign int) {
+	c.samples += sign * other.samples
+	c.failed += sign * other.failed
+	c.over += sign * other.over
+	for i, n := range other.buckets {
+		c.buckets[i] += int32(sign) * n
+	}
+}
+
+type sloSlot struct {
+	// epoch is the number of slot widths from the Unix epoch to the start
+	// of the slot
+	epoch int64
+	sloCounts
+}
+
+type sloTracker struct {
+	slo SLO
+	// width is the width of a slot, or zero if the SLO has no window and
+	// every sample goes in the first slot
+	width int64
+
+	mu       sync.Mutex
+	slots    [sloSlots]sloSlot
+	latest   int64
+	total    sloCounts
+	breached bool
+}
+
+var slos = struct {
+	sync.RWMutex
+	trackers map[string]*sloTracker
+}{trackers: map[string]*sloTracker{}}
+
+// SetSLO tracks promises called name against slo, replacing any SLO
+// previously set for that name.
+func SetSLO(name string, slo SLO) {
+	t := &sloTracker{slo: slo}
+	if slo.Window > 0 {
+		t.width = int64(slo.Window) / sloSlots
+		if t.width == 0 {
+			t.width = 1
+		}
+	}
+	slos.Lock()
+	defer slos.Unlock()
+	slos.trackers[name] = t
+}
+
+// ClearSLO stops tracking the SLO set for name.
+func ClearSLO(name string) {
+	slos.Lock()
+	defer slos.Unlock()
+	delete(slos.trackers, name)
+}
+
+func recordSLO(p *Promise) {
+	slos.RLock()
+	tracker, ok := slos.trackers[p.Name()]
+	slos.RUnlock()
+	if !ok {
+		return
+	}
+	breach, fire := tracker.record(sloSample{
+		at:      p.settled,
+		latency: p.Timings().Total(),
+		failed:  p.err != nil,
+	})
+	if fire && tracker.slo.OnBreach != nil {
+		breach.Name = p.Name()
+		tracker.slo.OnBreach(breach)
+	}
+}
+
+// sloBucket returns the histogram bucket of latency.
+func sloBucket(latency time.Duration) int {
+	if latency <= time.Microsecond {
+		return 0
+	}
+	i := int(math.Ceil(2 * math.Log2(float64(latency)/float64(time.Microsecond))))
+	if i >= sloBuckets {
+		return sloBuckets - 1
+	}
+	return i
+}
+
+// sloBucketBound returns the upper bound of histogram bucket i.
+func sloBucketBound(i int) time.Duration {
+	return time.Duration(math.Pow(2, float64(i)/2) * float64(time.Microsecond))
+}
+
+// record adds sample to the window and reports whether the SLO just
+// became breached.
+func (t *sloTracker) record(sample sloSample) (breach SLOBreach, fire bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	slot := &t.slots[0]
+	if t.width > 0 {
+		epoch := sample.at.UnixNano() / t.width
+		if epoch > t.latest {
+			t.latest = epoch
+		}
+		if epoch <= t.latest-sloSlots {
+			// It settled before the window, as promises settling out of
+			// order may.
+			return breach, false
+		}
+		t.expire()
+		slot = &t.slots[(epoch%sloSlots+sloSlots)%sloSlots]
+		slot.epoch = epoch
+	}
+	var counts sloCounts
+	counts.samples = 1
+	if sample.failed {
+		counts.failed = 1
+	}
+	if sample.latency > t.slo.LatencyTarget {
+		counts.over = 1
+	}
+	counts.buckets[sloBucket(sample.latency)] = 1
+	slot.add(&counts, 1)
+	t.total.add(&counts, 1)
+
+	n := t.total.samples
+	if n < t.slo.MinSamples || n == 0 {
+		return breach, false
+	}
+	breach.Samples = n
+	breach.ErrorRate = float64(t.total.failed) / float64(n)
+	breach.ErrorRateBreached = t.slo.MaxErrorRate > 0 && breach.ErrorRate > t.slo.MaxErrorRate
+
+	if t.slo.Percentile > 0 {
+		// rank is the position, from 1, of the latency at Percentile.
+		rank := int(t.slo.Percentile*float64(n) + 0.5)
+		if rank < 1 {
+			rank = 1
+		}
+		if rank > n {
+			rank = n
+		}
+		seen := 0
+		for i, count := range t.total.buckets {
+			if seen += int(count); seen >= rank {
+				breach.Latency = sloBucketBound(i)
+				break
+			}
+		}
+		breach.LatencyBreached = n-t.total.over < rank
+	}
+
+	breached := breach.LatencyBreached || breach.ErrorRateBreached
+	fire = breached && !t.breached
+	t.breached = breached
+	return breach, fire
+}
+
+// expire drops the slots that have left the window. t.mu must be held.
+func (t *sloTracker) expire() {
+	for i := range t.slots {
+		slot := &t.slots[i]
+		if slot.samples > 0 && slot.epoch <= t.latest-sloSlots {
+			t.total.add(&slot.sloCounts, -1)
+			slot.sloCounts = sloCounts{}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package promise
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sloFailure() error {
+	return errors.New("failed")
+}
+
+func sloSlow() {
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestSLOErrorRateBreach(t *testing.T) {
+	name := funcName(reflect.ValueOf(sloFailure))
+	breaches := make(chan SLOBreach, 10)
+	SetSLO(name, SLO{
+		MaxErrorRate: 0.5,
+		Window:       time.Minute,
+		MinSamples:   2,
+		OnBreach:     func(b SLOBreach) { breaches <- b },
+	})
+	defer ClearSLO(name)
+
+	for i := 0; i < 3; i++ {
+		require.Error(t, New(sloFailure).Wait())
+	}
+	breach := <-breaches
+	require.Equal(t, name, breach.Name)
+	require.True(t, breach.ErrorRateBreached)
+	require.False(t, breach.LatencyBreached)
+	require.Len(t, breaches, 0, "breaches are only reported on transition")
+}
+
+func TestSLOLatencyBreach(t *testing.T) {
+	name := funcName(reflect.ValueOf(sloSlow))
+	breaches := make(chan SLOBreach, 10)
+	SetSLO(name, SLO{
+		Percentile:    0.9,
+		LatencyTarget: time.Millisecond,
+		Window:        time.Minute,
+		OnBreach:      func(b SLOBreach) { breaches <- b },
+	})
+	defer ClearSLO(name)
+
+	require.NoError(t, New(sloSlow).Wait())
+	breach := <-breaches
+	require.True(t, breach.LatencyBreached)
+	require.True(t, breach.Latency >= 20*time.Millisecond)
+}
+
+func TestSLOWindow(t *testing.T) {
+	SetSLO("window", SLO{MaxErrorRate: 0.5, Window: time.Minute, MinSamples: 2})
+	defer ClearSLO("window")
+	tracker := slos.trackers["window"]
+	start := time.Unix(0, 0)
+	_, fire := tracker.record(sloSample{at: start, failed: true})
+	require.False(t, fire)
+	breach, fire := tracker.record(sloSample{at: start.Add(time.Second), failed: true})
+	require.True(t, fire)
+	require.Equal(t, 2, breach.Samples)
+
+	_, fire = tracker.record(sloSample{at: start.Add(2 * time.Minute)})
+	require.False(t, fire)
+	require.Equal(t, 1, tracker.total.samples, "the failures have left the window")
+	breach, _ = tracker.record(sloSample{at: start.Add(2*time.Minute + time.Second)})
+	require.Equal(t, 2, breach.Samples)
+	require.False(t, breach.ErrorRateBreached)
+	_, fire = tracker.record(sloSample{at: start, failed: true})
+	require.False(t, fire, "samples from before the window are dropped")
+}
+
+func TestSLOZeroWindow(t *testing.T) {
+	SetSLO("forever", SLO{Percentile: 0.5, LatencyTarget: 10 * time.Millisecond})
+	defer ClearSLO("forever")
+	tracker := slos.trackers["forever"]
+	start := time.Unix(0, 0)
+	for i := 0; i < 3; i++ {
+		tracker.record(sloSample{at: start.Add(time.Duration(i) * time.Hour), latency: time.Millisecond})
+	}
+	breach, _ := tracker.record(sloSample{at: start.Add(1000 * time.Hour), latency: time.Second})
+	require.Equal(t, 4, breach.Samples, "nothing expires without a window")
+	require.False(t, breach.LatencyBreached)
+	require.True(t, breach.Latency >= time.Millisecond && breach.Latency < 2*time.Millisecond)
+}
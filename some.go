@@ -0,0 +1,66 @@
+package promise
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SomeResult is what the promise returned by Some resolves with.
+type SomeResult struct {
+	// Indices are the positions, among the promises passed to Some, of
+	// the first k to resolve, in the order they resolved.
+	Indices []int
+	// Results holds the results of those promises, in the same order.
+	Results []Results
+}
+
+// Some returns a promise that resolves with a SomeResult once k of
+// promises have resolved, for quorum reads and hedged requests. It is
+// rejected with an *AggregateError, holding the errors so far by index,
+// as soon as so many have failed that k can no longer resolve. Some
+// panics if k is negative or more than len(promises), and resolves
+// straight away if k is zero.
+func Some(k int, promises ...*Promise) *Promise {
+	if k < 0 || k > len(promises) {
+		panic(fmt.Errorf("Some needs 0 to %d successes, got %d", len(promises), k))
+	}
+	d := NewDeferred(typeOf[SomeResult]())
+	d.name = "Some"
+	if k == 0 {
+		d.Resolve(SomeResult{Indices: []int{}, Results: []Results{}})
+		return d.Promise
+	}
+	var mu sync.Mutex
+	result := SomeResult{Indices: make([]int, 0, k), Results: make([]Results, 0, k)}
+	aggregate := &AggregateError{Errs: make([]error, len(promises))}
+	failed := 0
+	d.watchContexts(promises)
+	for i, prior := range promises {
+		i, prior := i, prior
+		prior.checkCopy()
+		prior.observe()
+		prior.graph.addChild(prior, d.Promise)
+		prior.whenSettled(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if d.isSettled() {
+				return
+			}
+			if prior.err != nil {
+				aggregate.Errs[i] = prior.err
+				aggregate.LastErr = prior.err
+				if failed++; failed > len(promises)-k {
+					errs := append([]error{}, aggregate.Errs...)
+					d.Reject(&AggregateError{Errs: errs, LastErr: prior.err})
+				}
+				return
+			}
+			result.Indices = append(result.Indices, i)
+			result.Results = append(result.Results, prior.settledResults())
+			if len(result.Indices) == k {
+				d.Resolve(result)
+			}
+		})
+	}
+	return d.Promise
+}
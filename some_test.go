@@ -0,0 +1,46 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSome(t *testing.T) {
+	slow := NewDeferred(typeOf[string]())
+	a := Resolved("a")
+	c := Resolved("c")
+	p := Some(2, slow.Promise, a, c)
+	var result SomeResult
+	require.NoError(t, p.Wait(&result))
+	require.ElementsMatch(t, []int{1, 2}, result.Indices)
+	values := map[int]interface{}{1: "a", 2: "c"}
+	for i, index := range result.Indices {
+		require.Equal(t, []interface{}{values[index]}, result.Results[i].Values)
+	}
+	slow.Resolve("b")
+}
+
+func TestSomeRejectsOnceImpossible(t *testing.T) {
+	pending := NewDeferred(typeOf[int]())
+	failed := Rejected(errors.New("replica down"), typeOf[int]())
+	failed2 := Rejected(errors.New("timeout"), typeOf[int]())
+	p := Some(3, Resolved(1), failed, failed2, pending.Promise)
+	err := p.Wait(new(SomeResult))
+	var aggregate *AggregateError
+	require.True(t, errors.As(err, &aggregate))
+	require.Len(t, aggregate.Errs, 4)
+	require.Nil(t, aggregate.Errs[0])
+	require.EqualError(t, aggregate.Errs[1], "replica down")
+	require.EqualError(t, aggregate.Errs[2], "timeout")
+	pending.Resolve(2)
+}
+
+func TestSomeEdgeCases(t *testing.T) {
+	var result SomeResult
+	require.NoError(t, Some(0, Resolved(1)).Wait(&result))
+	require.Empty(t, result.Indices)
+	require.Panics(t, func() { Some(2, Resolved(1)) })
+	require.Panics(t, func() { Some(-1) })
+}
@@ -0,0 +1,119 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Spread is like Then for a promise that resolves with a single slice or
+// array, but passes its elements to f as separate arguments:
+//
+//	New(strings.Fields, "GET /index.html HTTP/1.1").Spread(func(method, path, proto string) { ... })
+//
+// If f is variadic, the elements left after its other parameters fill the
+// variadic one. The returned promise is rejected if the slice has the
+// wrong number of elements, or, for elements of interface type, if one
+// holds a value f can't accept. Whatever can be checked from the types,
+// such as an array's length, panics when Spread is called instead.
+func (p *Promise) Spread(f interface{}) *Promise {
+	p.checkCopy()
+	functionRv, joinErrors := funcValue(f)
+	if functionRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", functionRv.Kind()))
+	}
+	if len(p.resultType) != 1 || (p.resultType[0].Kind() != reflect.Slice && p.resultType[0].Kind() != reflect.Array) {
+		panic(fmt.Errorf("Spread expects a promise resolving with a single slice or array, got %v", p.resultType))
+	}
+	fnType := functionRv.Type()
+	seqType := p.resultType[0]
+	elem := seqType.Elem()
+	params := spreadParams(fnType)
+	for i, param := range params {
+		if !elem.AssignableTo(param) && elem.Kind() != reflect.Interface {
+			panic(fmt.Errorf("for argument %d: expected type %s got type %s", i, elem, param))
+		}
+	}
+	if seqType.Kind() == reflect.Array {
+		if err := checkSpreadLen(fnType, seqType.Len()); err != nil {
+			panic(err)
+		}
+	}
+
+	outs := make([]reflect.Type, fnType.NumOut())
+	for i := range outs {
+		outs[i] = fnType.Out(i)
+	}
+	adapter := reflect.MakeFunc(reflect.FuncOf([]reflect.Type{seqType}, outs, false), func(in []reflect.Value) []reflect.Value {
+		args, err := spreadArgs(fnType, in[0])
+		if err != nil {
+			panic(rejection{err})
+		}
+		return functionRv.Call(args)
+	}).Interface()
+	if joinErrors {
+		adapter = joinedErrors{adapter}
+	}
+	return p.then(adapter, func(next *Promise) {
+		next.name = funcName(functionRv)
+	})
+}
+
+// spreadParams returns the types of the parameters of fnType, with the
+// element type of a variadic parameter in place of its slice type.
+func spreadParams(fnType reflect.Type) []reflect.Type {
+	params := make([]reflect.Type, fnType.NumIn())
+	for i := range params {
+		params[i] = fnType.In(i)
+	}
+	if fnType.IsVariadic() {
+		params[len(params)-1] = params[len(params)-1].Elem()
+	}
+	return params
+}
+
+// checkSpreadLen reports an error if n elements can't be spread over the
+// parameters of fnType.
+func checkSpreadLen(fnType reflect.Type, n int) error {
+	if fnType.IsVariadic() && n < fnType.NumIn()-1 {
+		return fmt.Errorf("Spread expected at least %d elements, got %d", fnType.NumIn()-1, n)
+	}
+	if !fnType.IsVariadic() && n != fnType.NumIn() {
+		return fmt.Errorf("Spread expected %d elements, got %d", fnType.NumIn(), n)
+	}
+	return nil
+}
+
+// spreadArgs returns the elements of seq as arguments for a function of
+// type fnType.
+func spreadArgs(fnType reflect.Type, seq reflect.Value) ([]reflect.Value, error) {
+	if err := checkSpreadLen(fnType, seq.Len()); err != nil {
+		return nil, err
+	}
+	params := spreadParams(fnType)
+	args := make([]reflect.Value, seq.Len())
+	for i := range args {
+		param := params[len(params)-1]
+		if i < len(params) {
+			param = params[i]
+		}
+		arg := seq.Index(i)
+		if arg.Type().AssignableTo(param) {
+			args[i] = arg
+			continue
+		}
+		// An interface element, whose value must be checked.
+		if arg.IsNil() {
+			switch param.Kind() {
+			case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+				args[i] = reflect.Zero(param)
+				continue
+			}
+			return nil, fmt.Errorf("Spread element %d is nil, expected %s", i, param)
+		}
+		if !arg.Elem().Type().AssignableTo(param) {
+			return nil, fmt.Errorf("Spread element %d is a %s, expected %s", i, arg.Elem().Type(), param)
+		}
+		args[i] = arg.Elem()
+	}
+	return args, nil
+}
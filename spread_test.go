@@ -0,0 +1,57 @@
+package promise
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpread(t *testing.T) {
+	var got string
+	p := New(strings.Fields, "GET /index.html HTTP/1.1").Spread(func(method, path, proto string) string {
+		return method + " " + path
+	})
+	require.NoError(t, p.Wait(&got))
+	require.Equal(t, "GET /index.html", got)
+}
+
+func TestSpreadVariadicAndArrays(t *testing.T) {
+	var n int
+	p := New(func() []int { return []int{1, 2, 3, 4} }).Spread(func(first int, rest ...int) int {
+		return first * len(rest)
+	})
+	require.NoError(t, p.Wait(&n))
+	require.Equal(t, 3, n)
+
+	p = New(func() [2]int { return [2]int{3, 4} }).Spread(func(a, b int) int { return a * b })
+	require.NoError(t, p.Wait(&n))
+	require.Equal(t, 12, n)
+}
+
+func TestSpreadInterfaceElements(t *testing.T) {
+	values := New(func() []interface{} { return []interface{}{"a", 1, nil} })
+	var s string
+	require.NoError(t, values.Spread(func(name string, n int, err error) string {
+		return strings.Repeat(name, n)
+	}).Wait(&s))
+	require.Equal(t, "a", s)
+
+	err := values.Spread(func(a, b string, c error) {}).Wait()
+	require.EqualError(t, cause(err), "Spread element 1 is a int, expected string")
+}
+
+func TestSpreadLengthMismatch(t *testing.T) {
+	p := New(func() []int { return []int{1} }).Spread(func(a, b int) {})
+	require.EqualError(t, cause(p.Wait()), "Spread expected 2 elements, got 1")
+
+	require.Panics(t, func() {
+		New(func() [3]int { return [3]int{} }).Spread(func(a, b int) {})
+	})
+	require.Panics(t, func() {
+		New(func() []int { return nil }).Spread(func(a string) {})
+	})
+	require.Panics(t, func() {
+		New(func() int { return 1 }).Spread(func(a int) {})
+	})
+}
@@ -0,0 +1,120 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Stages is a sequence of functions run one after another, each called
+// with the results of the one before, as if chained with Then.
+type Stages struct {
+	fns     []interface{}
+	budget  time.Duration
+	weights []float64
+}
+
+// NewStages returns the stages fs, in order. It panics if fs is empty.
+func NewStages(fs ...interface{}) *Stages {
+	if len(fs) == 0 {
+		panic(errors.New("NewStages requires at least one function"))
+	}
+	return &Stages{fns: fs}
+}
+
+// WithBudget splits a total latency budget across the stages in proportion
+// to weights, one per stage. With no weights the budget is split evenly.
+// A stage that runs for longer than its share rejects the chain with a
+// *StageTimeoutError. WithBudget returns s for chaining.
+func (s *Stages) WithBudget(total time.Duration, weights ...float64) *Stages {
+	if len(weights) == 0 {
+		weights = make([]float64, len(s.fns))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	if len(weights) != len(s.fns) {
+		panic(fmt.Errorf("expected %d weights, got %d", len(s.fns), len(weights)))
+	}
+	for i, w := range weights {
+		if w <= 0 {
+			panic(fmt.Errorf("weight %d must be positive, got %v", i, w))
+		}
+	}
+	s.budget = total
+	s.weights = weights
+	return s
+}
+
+// Run starts the first stage with args and returns a promise for the
+// results of the last stage.
+func (s *Stages) Run(args ...interface{}) *Promise {
+	budgets := s.budgets()
+	p, start := newCall(s.fns[0], nil, args)
+	s.limit(p, 0, budgets)
+	start()
+	for i := 1; i < len(s.fns); i++ {
+		i := i
+		p = p.then(s.fns[i], func(next *Promise) {
+			s.limit(next, i, budgets)
+		})
+	}
+	return p
+}
+
+func (s *Stages) budgets() []time.Duration {
+	if s.budget <= 0 {
+		return nil
+	}
+	total := 0.0
+	for _, w := range s.weights {
+		total += w
+	}
+	budgets := make([]time.Duration, len(s.weights))
+	for i, w := range s.weights {
+		budgets[i] = time.Duration(float64(s.budget) * w / total)
+	}
+	return budgets
+}
+
+func (s *Stages) limit(p *Promise, stage int, budgets []time.Duration) {
+	if budgets == nil {
+		return
+	}
+	x := p.extras()
+	x.timeout = budgets[stage]
+	x.timeoutErr = &StageTimeoutError{
+		Stage:  stage,
+		Name:   funcName(reflect.ValueOf(s.fns[stage])),
+		Budget: budgets[stage],
+	}
+}
+
+// A StageTimeoutError reports a stage that exceeded its share of a budget
+// set with WithBudget.
+type StageTimeoutError struct {
+	// Stage is the index of the stage, and Name the name of its function.
+	Stage  int
+	Name   string
+	Budget time.Duration
+}
+
+func (err *StageTimeoutError) Error() string {
+	return fmt.Sprintf("stage %d (%s) exceeded its budget of %s", err.Stage, err.Name, err.Budget)
+}
+
+// startTimeout starts the promise's own timeout, if it has one, and
+// returns a function that stops it.
+func (p *Promise) startTimeout() (stop func()) {
+	x := p.peekExtras()
+	if x.timeout <= 0 {
+		return func() {}
+	}
+	timer := p.afterFunc(p.checkDeadline(x.timeout), func() {
+		p.settle(nil, x.timeoutErr)
+	})
+	return func() {
+		timer.Stop()
+	}
+}
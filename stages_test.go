@@ -0,0 +1,49 @@
+package promise
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagesRun(t *testing.T) {
+	p := NewStages(
+		func(x int) int { return x + 1 },
+		func(x int) int { return x * 2 },
+		strconv.Itoa,
+	).Run(20)
+	var result string
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, "42", result)
+}
+
+func slowStage(x int) int {
+	time.Sleep(200 * time.Millisecond)
+	return x
+}
+
+func TestStagesWithBudgetRejectsSlowStage(t *testing.T) {
+	p := NewStages(
+		func(x int) int { return x },
+		slowStage,
+		func(x int) int { return x },
+	).WithBudget(100*time.Millisecond, 1, 2, 1).Run(1)
+
+	start := time.Now()
+	err := p.Wait(new(int))
+	require.Error(t, err)
+	require.True(t, time.Since(start) < 150*time.Millisecond)
+	stageErr, ok := cause(err).(*StageTimeoutError)
+	require.True(t, ok, "expected a StageTimeoutError, got %v", err)
+	require.Equal(t, 1, stageErr.Stage)
+	require.Contains(t, stageErr.Name, "slowStage")
+	require.Equal(t, 50*time.Millisecond, stageErr.Budget)
+}
+
+func TestStagesWithBudgetValidatesWeights(t *testing.T) {
+	stages := NewStages(func() {}, func() {})
+	require.Panics(t, func() { stages.WithBudget(time.Second, 1) })
+	require.Panics(t, func() { stages.WithBudget(time.Second, 1, 0) })
+}
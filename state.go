@@ -0,0 +1,61 @@
+package promise
+
+// State is the state of a promise: pending, fulfilled or rejected.
+type State int
+
+const (
+	// StatePending promises haven't settled yet.
+	StatePending State = iota
+	// StateFulfilled promises resolved with values.
+	StateFulfilled
+	// StateRejected promises failed with an error.
+	StateRejected
+)
+
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateFulfilled:
+		return "fulfilled"
+	case StateRejected:
+		return "rejected"
+	}
+	return "unknown"
+}
+
+// State returns the current state of p without blocking.
+func (p *Promise) State() State {
+	p.checkCopy()
+	if !p.isSettled() {
+		return StatePending
+	}
+	if p.err != nil {
+		return StateRejected
+	}
+	return StateFulfilled
+}
+
+// Done returns a channel that is closed once p settles, so p can be used
+// in a select statement alongside other channels:
+//
+//	select {
+//	case <-p.Done():
+//		err := p.Wait(&result)
+//	case <-ctx.Done():
+//	}
+func (p *Promise) Done() <-chan struct{} {
+	p.checkCopy()
+	return p.doneChan()
+}
+
+// TryWait is a non-blocking Wait: if p has settled, it sets out like Wait
+// and reports true along with p's error. Otherwise it leaves out alone and
+// reports false, so p can be polled, for example once per tick of a loop.
+func (p *Promise) TryWait(out ...interface{}) (done bool, err error) {
+	p.checkCopy()
+	if !p.isSettled() {
+		return false, nil
+	}
+	return true, p.Wait(out...)
+}
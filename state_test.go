@@ -0,0 +1,56 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestState(t *testing.T) {
+	signal := Signal()
+	require.Equal(t, StatePending, signal.Promise().State())
+	signal.Resolve()
+	require.Equal(t, StateFulfilled, signal.Promise().State())
+	require.Equal(t, StateRejected, Rejected(errors.New("failed")).State())
+	require.Equal(t, "rejected", StateRejected.String())
+}
+
+func TestTryWait(t *testing.T) {
+	d := NewDeferred(typeOf[int]())
+	result := -1
+	done, err := d.TryWait(&result)
+	require.False(t, done)
+	require.NoError(t, err)
+	require.Equal(t, -1, result)
+
+	d.Resolve(7)
+	done, err = d.TryWait(&result)
+	require.True(t, done)
+	require.NoError(t, err)
+	require.Equal(t, 7, result)
+
+	done, err = Rejected(errors.New("failed")).TryWait()
+	require.True(t, done)
+	require.Error(t, err)
+}
+
+func TestDone(t *testing.T) {
+	d := NewDeferred(typeOf[int]())
+	done := d.Promise.Done()
+	select {
+	case <-done:
+		t.Fatal("done before settling")
+	default:
+	}
+	d.Resolve(1)
+	<-done
+	<-d.Promise.Done()
+
+	settled := Resolved(2)
+	select {
+	case <-settled.Done():
+	default:
+		t.Fatal("a settled promise isn't done")
+	}
+}
@@ -0,0 +1,80 @@
+package promise
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rejectedCount counts every promise rejected since the program started.
+var rejectedCount int64
+
+// Stats is a snapshot of the promises in the program.
+type Stats struct {
+	// Pending promises haven't settled, and Running ones are executing
+	// their function right now.
+	Pending int
+	Running int
+	// Failed counts the promises rejected since the program started.
+	Failed int64
+	// OldestPending is the age of the oldest pending promise created in
+	// debug mode, or zero if there are none. See EnableDebug.
+	OldestPending time.Duration
+}
+
+// ReadStats returns a snapshot of the promises in the program.
+func ReadStats() Stats {
+	now := currentTime()
+	stats := Stats{Failed: atomic.LoadInt64(&rejectedCount)}
+	stats.Pending = int(atomic.LoadInt64(&pendingCount))
+	stats.Running = int(atomic.LoadInt64(&runningCount))
+	tracked.Lock()
+	for p := range tracked.pending {
+		if age := now.Sub(p.createdAt()); age > stats.OldestPending {
+			stats.OldestPending = age
+		}
+	}
+	tracked.Unlock()
+	return stats
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("pending=%d running=%d failed=%d oldest_pending=%s",
+		s.Pending, s.Running, s.Failed, s.OldestPending.Round(time.Millisecond))
+}
+
+// StartStatsLogger writes a line with ReadStats to w every interval, such
+// as "promise stats: pending=3 running=1 failed=0 oldest_pending=1.2s",
+// until the returned function is called.
+func StartStatsLogger(interval time.Duration, w io.Writer) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	spawn(func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprintf(w, "promise stats: %s\n", ReadStats())
+			case <-done:
+				return
+			}
+		}
+	})
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+		<-stopped
+	}
+}
+
+func countSettled(p *Promise) {
+	if p.err != nil {
+		atomic.AddInt64(&rejectedCount, 1)
+	}
+}
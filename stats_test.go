@@ -0,0 +1,68 @@
+package promise
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStats(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+	before := ReadStats()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p := New(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	time.Sleep(5 * time.Millisecond)
+	stats := ReadStats()
+	require.GreaterOrEqual(t, stats.Pending, 1)
+	require.GreaterOrEqual(t, stats.Running, 1)
+	require.GreaterOrEqual(t, int64(stats.OldestPending), int64(5*time.Millisecond))
+	close(release)
+	require.NoError(t, p.Wait())
+
+	require.Error(t, Rejected(fmt.Errorf("failure")).Wait())
+	require.Greater(t, ReadStats().Failed, before.Failed)
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStartStatsLogger(t *testing.T) {
+	var out syncBuffer
+	stop := StartStatsLogger(5*time.Millisecond, &out)
+	time.Sleep(30 * time.Millisecond)
+	stop()
+	stop()
+	logged := out.String()
+	lines := strings.Split(strings.TrimSpace(logged), "\n")
+	require.NotEmpty(t, lines)
+	require.Regexp(t, `^promise stats: pending=\d+ running=\d+ failed=\d+ oldest_pending=\S+$`, lines[0])
+
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, logged, out.String(), "nothing is logged after stop")
+}
@@ -0,0 +1,293 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// A Stream is a sequence of values of one type produced over time, such
+// as the pages of a paginated API or the chunks of a download, where a
+// Promise settles with a single set of values. A producer calls Emit for
+// each value and Close, or CloseWithError, once there are no more:
+//
+//	s := NewStream(reflect.TypeOf(Page{}))
+//	go func() {
+//		for token := ""; ; {
+//			page, err := fetchPage(token)
+//			if err != nil {
+//				s.CloseWithError(err)
+//				return
+//			}
+//			s.Emit(page)
+//			if token = page.Next; token == "" {
+//				s.Close()
+//				return
+//			}
+//		}
+//	}()
+//	items := s.Then(pageItems).All()
+//
+// A Stream has a single consumer, attached with Then, All or Reduce.
+// Values emitted before it is attached are buffered until then, and
+// afterwards are handed to it on the goroutine calling Emit, one at a
+// time and in order, so a slow consumer holds the producer back.
+type Stream struct {
+	// mu is held while values are handed to the consumer, which keeps
+	// them in order.
+	mu sync.Mutex
+	// elem is nil until setElem is called for a stream whose type isn't
+	// known when it is created, such as that of a Poller whose first poll
+	// failed, and accepts is then the type its consumer takes, if any.
+	elem     reflect.Type
+	accepts  reflect.Type
+	buf      []reflect.Value
+	consume  func(value reflect.Value)
+	finish   func(err error)
+	attached bool
+	closed   bool
+	err      error
+}
+
+// NewStream returns an open Stream of values of type elem.
+func NewStream(elem reflect.Type) *Stream {
+	return &Stream{elem: elem}
+}
+
+// Emit adds value, which must be assignable to the stream's type, to the
+// stream. A nil value stands for the zero value of the type. Emit panics
+// if the stream is closed, and must not be called by the stream's own
+// consumer.
+func (s *Stream) Emit(value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rv := valueAs(s.elem, value, 0)
+	if s.closed {
+		panic(errors.New("promise: Emit on closed Stream"))
+	}
+	s.emit(rv)
+}
+
+// emit hands value to the consumer, or buffers it until there is one.
+// s.mu must be held.
+func (s *Stream) emit(value reflect.Value) {
+	if !s.attached {
+		s.buf = append(s.buf, value)
+		return
+	}
+	s.consume(value)
+}
+
+// Close ends the stream. Only the first call to Close or CloseWithError
+// has any effect.
+func (s *Stream) Close() {
+	s.CloseWithError(nil)
+}
+
+// CloseWithError ends the stream with err, which rejects the promises of
+// All and Reduce and closes streams returned by Then with the same error.
+func (s *Stream) CloseWithError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed, s.err = true, err
+	if s.attached {
+		s.finish(err)
+	}
+}
+
+// elemType returns the type of the values of s, or nil if it isn't known
+// yet.
+func (s *Stream) elemType() reflect.Type {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.elem
+}
+
+// setElem sets the type of the values of an untyped stream, and checks it
+// against the type its consumer takes.
+func (s *Stream) setElem(elem reflect.Type) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elem = elem
+	if s.accepts != nil && !elem.AssignableTo(s.accepts) {
+		return fmt.Errorf("stream consumer accepts %s, got values of type %s", s.accepts, elem)
+	}
+	return nil
+}
+
+// attach makes consume and finish the consumer of s, and hands them
+// whatever was emitted so far.
+func (s *Stream) attach(accepts reflect.Type, consume func(value reflect.Value), finish func(err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attached {
+		panic(errors.New("promise: Stream already has a consumer"))
+	}
+	s.attached, s.accepts, s.consume, s.finish = true, accepts, consume, finish
+	buf := s.buf
+	s.buf = nil
+	for _, value := range buf {
+		consume(value)
+	}
+	if s.closed {
+		finish(s.err)
+	}
+}
+
+// Then returns a stream of the results of calling f on each value of s.
+// f accepts a value of the stream's type and returns a single value,
+// optionally followed by an error. An error, or a panic, closes the
+// returned stream with it, and later values of s are dropped.
+func (s *Stream) Then(f interface{}) *Stream {
+	functionRv := reflect.ValueOf(f)
+	if functionRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", functionRv.Kind()))
+	}
+	fnType := functionRv.Type()
+	elem := s.elemType()
+	if fnType.NumIn() != 1 || (elem != nil && !elem.AssignableTo(fnType.In(0))) {
+		panic(fmt.Errorf("expected function accepting a single %s, got %s", elem, fnType))
+	}
+	resultType, returnsError := getResultType(fnType)
+	if len(resultType) != 1 {
+		panic(fmt.Errorf("function must return a single value, got %s", fnType))
+	}
+	next := NewStream(resultType[0])
+	failed := false
+	s.attach(fnType.In(0), func(value reflect.Value) {
+		if failed {
+			return
+		}
+		result, err := callItem(functionRv, returnsError, value)
+		if err != nil {
+			failed = true
+			next.CloseWithError(err)
+			return
+		}
+		next.mu.Lock()
+		defer next.mu.Unlock()
+		if !next.closed {
+			next.emit(result[0])
+		}
+	}, next.CloseWithError)
+	return next
+}
+
+// All returns a promise that resolves with a slice of every value of s
+// once it is closed, or is rejected with the error it was closed with.
+func (s *Stream) All() *Promise {
+	elem := s.elemType()
+	if elem == nil {
+		return s.allUntyped()
+	}
+	d := NewDeferred(reflect.SliceOf(elem))
+	d.name = "Stream.All"
+	values := reflect.MakeSlice(reflect.SliceOf(elem), 0, 0)
+	s.attach(nil, func(value reflect.Value) {
+		values = reflect.Append(values, value)
+	}, func(err error) {
+		if err != nil {
+			d.Reject(err)
+			return
+		}
+		d.settle([]reflect.Value{values}, nil)
+	})
+	return d.Promise
+}
+
+// allUntyped is All for a stream whose type isn't known yet. Its promise
+// takes the slice type of the first value, and resolves like an All of no
+// promises if there is none.
+func (s *Stream) allUntyped() *Promise {
+	d := NewDeferred()
+	d.name = "Stream.All"
+	d.resultType, d.dynamic = nil, true
+	var values reflect.Value
+	s.attach(nil, func(value reflect.Value) {
+		if !values.IsValid() {
+			values = reflect.MakeSlice(reflect.SliceOf(value.Type()), 0, 0)
+		}
+		values = reflect.Append(values, value)
+	}, func(err error) {
+		if err != nil {
+			d.Reject(err)
+			return
+		}
+		results := []reflect.Value{}
+		d.mu.Lock()
+		if values.IsValid() {
+			results = append(results, values)
+			d.resultType = []reflect.Type{values.Type()}
+		} else {
+			d.resultType, d.sliceType = []reflect.Type{}, anySliceType
+		}
+		d.mu.Unlock()
+		d.settle(results, nil)
+	})
+	return d.Promise
+}
+
+// Reduce returns a promise that folds the values of s through reducer,
+// starting from initial, and resolves with the accumulated value once s is
+// closed. reducer is as for the package's Reduce. An error or panic from
+// reducer, or s closing with an error, rejects the promise.
+func (s *Stream) Reduce(reducer interface{}, initial interface{}) *Promise {
+	reducerRv := reflect.ValueOf(reducer)
+	if reducerRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", reducerRv.Kind()))
+	}
+	reducerType := reducerRv.Type()
+	elem := s.elemType()
+	if reducerType.NumIn() != 2 || (elem != nil && !elem.AssignableTo(reducerType.In(1))) {
+		panic(fmt.Errorf("reducer must accept the accumulator and a %s, got %s", elem, reducerType))
+	}
+	accType := reducerType.In(0)
+	resultType, returnsError := getResultType(reducerType)
+	if len(resultType) != 1 || !resultType[0].AssignableTo(accType) {
+		panic(fmt.Errorf("reducer must return the accumulator of type %s, got %s", accType, reducerType))
+	}
+	acc := valueAs(accType, initial, 0)
+	d := NewDeferred(accType)
+	d.name = "Stream.Reduce"
+	s.attach(reducerType.In(1), func(value reflect.Value) {
+		if d.isSettled() {
+			return
+		}
+		result, err := callItem(reducerRv, returnsError, acc, value)
+		if err != nil {
+			d.Reject(err)
+			return
+		}
+		acc = result[0]
+	}, func(err error) {
+		if err != nil {
+			d.Reject(err)
+			return
+		}
+		d.settle([]reflect.Value{acc}, nil)
+	})
+	return d.Promise
+}
+
+// callItem calls f with args, and returns its results without a trailing
+// error, or the error it returned or panicked with.
+func callItem(f reflect.Value, returnsError bool, args ...reflect.Value) (results []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicError(r)
+		}
+	}()
+	results = f.Call(args)
+	if returnsError {
+		last := results[len(results)-1]
+		results = results[:len(results)-1]
+		if !last.IsNil() {
+			return nil, last.Interface().(error)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,69 @@
+package promise
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var intType = reflect.TypeOf(0)
+
+func TestStreamAll(t *testing.T) {
+	s := NewStream(intType)
+	s.Emit(1)
+	all := s.All()
+	go func() {
+		for i := 2; i <= 4; i++ {
+			s.Emit(i)
+		}
+		s.Close()
+	}()
+	var values []int
+	require.NoError(t, all.Wait(&values))
+	require.Equal(t, []int{1, 2, 3, 4}, values)
+}
+
+func TestStreamThenAndReduce(t *testing.T) {
+	s := NewStream(intType)
+	sum := s.Then(strconv.Itoa).Then(func(s string) int { return len(s) }).Reduce(func(acc, n int) int {
+		return acc + n
+	}, 0)
+	for _, n := range []int{5, 50, 500} {
+		s.Emit(n)
+	}
+	s.Close()
+	var total int
+	require.NoError(t, sum.Wait(&total))
+	require.Equal(t, 6, total)
+}
+
+func TestStreamErrors(t *testing.T) {
+	s := NewStream(intType)
+	all := s.All()
+	s.Emit(1)
+	s.CloseWithError(errors.New("connection reset"))
+	require.EqualError(t, cause(all.Wait(new([]int))), "connection reset")
+	require.Panics(t, func() { s.Emit(2) })
+
+	s = NewStream(reflect.TypeOf(""))
+	parsed := s.Then(strconv.Atoi).All()
+	s.Emit("1")
+	s.Emit("x")
+	s.Emit("3")
+	s.Close()
+	err := parsed.Wait(new([]int))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `parsing "x"`)
+}
+
+func TestStreamValidates(t *testing.T) {
+	s := NewStream(intType)
+	require.Panics(t, func() { s.Emit("one") })
+	require.Panics(t, func() { s.Then(func(string) int { return 0 }) })
+	require.Panics(t, func() { s.Reduce(func(acc string, n int) int { return 0 }, "") })
+	s.All()
+	require.Panics(t, func() { s.All() })
+}
@@ -0,0 +1,34 @@
+package promise
+
+import "sync/atomic"
+
+var strict int32
+
+func init() {
+	SetStrict(defaultStrict)
+}
+
+// SetStrict turns strict mode on or off. Strict mode is the default,
+// unless the package is built with the promise_nostrict build tag.
+//
+// In strict mode, New checks each argument against the function's
+// parameters up front, and New and Then panic on a mismatch. With strict
+// mode off, New leaves the check to reflect when the function is called,
+// and a mismatch rejects the promise New or Then returns instead of
+// panicking. Types are checked either way: a result is only converted for
+// Then when the promise allows it with WithConversions, and Wait panics
+// on a mismatch in both modes, as do TryNew, TryThen, Chain, Join,
+// Promisify and Debounce, which promise to check up front. Only turn
+// strict mode off for hot paths that tests already exercised with it on;
+// see BenchmarkStrict for the savings.
+func SetStrict(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&strict, v)
+}
+
+func strictChecks() bool {
+	return atomic.LoadInt32(&strict) == 1
+}
@@ -0,0 +1,6 @@
+//go:build !promise_nostrict
+// +build !promise_nostrict
+
+package promise
+
+const defaultStrict = true
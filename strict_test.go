@@ -0,0 +1,69 @@
+package promise
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonStrictRejectsInsteadOfPanicking(t *testing.T) {
+	SetStrict(false)
+	defer SetStrict(defaultStrict)
+
+	err := New(func(i int) {}, "x").Wait()
+	require.Error(t, err)
+
+	err = Resolved(1).Then(func(s []string) {}).Wait()
+	require.Error(t, err)
+}
+
+func TestNonStrictStillChecksTypes(t *testing.T) {
+	SetStrict(false)
+	defer SetStrict(defaultStrict)
+
+	called := false
+	err := Resolved(65).Then(func(s string) { called = true }).Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "for argument 0: expected type int got type string")
+	require.False(t, called)
+
+	err = Resolved(int64(1)).Then(func(int) {}).Wait()
+	require.Error(t, err)
+	require.NoError(t, Resolved(int64(1)).WithConversions().Then(func(int) {}).Wait())
+
+	_, err = TryNew(func(int) {}, "x")
+	require.Error(t, err)
+	_, err = Resolved(1).TryThen(func(string) {})
+	require.Error(t, err)
+	require.Panics(t, func() { Chain(func() int { return 1 }, func(string) {}) })
+	require.Panics(t, func() { Resolved(1).Wait(new(string)) })
+}
+
+func TestStrictPanics(t *testing.T) {
+	SetStrict(true)
+	defer SetStrict(defaultStrict)
+
+	require.Panics(t, func() { New(func(i int) {}, "x") })
+	require.Panics(t, func() { Resolved(1).Then(func(s string) {}) })
+	require.Panics(t, func() { Resolved(1).Wait(new(string)) })
+}
+
+func BenchmarkStrict(b *testing.B) {
+	for _, strict := range []bool{true, false} {
+		name := "strict"
+		if !strict {
+			name = "nostrict"
+		}
+		b.Run(name, func(b *testing.B) {
+			SetStrict(strict)
+			defer SetStrict(defaultStrict)
+			var result int64
+			for i := 0; i < b.N; i++ {
+				p := New(func(x int) int { return x }, i).Then(func(x int) int64 { return int64(x) })
+				if err := p.Wait(&result); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ThenCatch is like Then, except that if p is rejected, onRejected is
+// called with its error instead of the rejection skipping the
+// continuation, like JavaScript's then(onFulfilled, onRejected). The
+// returned promise settles with the results of whichever function ran.
+//
+// onRejected must accept a single error and return the same types as
+// onFulfilled.
+func (p *Promise) ThenCatch(onFulfilled, onRejected interface{}) *Promise {
+	onRejectedRv := reflect.ValueOf(onRejected)
+	if onRejectedRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", onRejectedRv.Kind()))
+	}
+	rejectedType := onRejectedRv.Type()
+	if rejectedType.NumIn() != 1 || rejectedType.In(0) != errorType {
+		panic(fmt.Errorf("expected function accepting a single error, got %s", rejectedType))
+	}
+	return p.then(onFulfilled, func(next *Promise) {
+		fulfilledType := reflect.TypeOf(onFulfilled)
+		if rejectedType.NumOut() != fulfilledType.NumOut() {
+			panic(fmt.Errorf("onFulfilled returns %d values, but onRejected returns %d values", fulfilledType.NumOut(), rejectedType.NumOut()))
+		}
+		for i := 0; i < fulfilledType.NumOut(); i++ {
+			if rejectedType.Out(i) != fulfilledType.Out(i) {
+				panic(fmt.Errorf("for return value %d: expected type %s got type %s", i, fulfilledType.Out(i), rejectedType.Out(i)))
+			}
+		}
+		next.extras().onRejected = onRejectedRv
+	})
+}
+
+// onRejectedCall calls p's onRejected handler with err. It reports false
+// if p settled before the handler could be called.
+func (p *Promise) onRejectedCall(err error) ([]reflect.Value, bool) {
+	if p.isSettled() {
+		return nil, false
+	}
+	defer p.startTimeout()()
+	p.markStarted()
+	injectFault(p)
+	errRv := reflect.New(errorType).Elem()
+	errRv.Set(reflect.ValueOf(err))
+	return p.peekExtras().onRejected.Call([]reflect.Value{errRv}), true
+}
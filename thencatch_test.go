@@ -0,0 +1,54 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThenCatchFulfilled(t *testing.T) {
+	p := Resolved(2).ThenCatch(
+		func(i int) string { return "fulfilled" },
+		func(err error) string { return "rejected" },
+	)
+	var result string
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, "fulfilled", result)
+}
+
+func TestThenCatchRejected(t *testing.T) {
+	failed := New(func() (int, error) {
+		return 0, errors.New("upstream failed")
+	})
+	p := failed.ThenCatch(
+		func(i int) (string, error) { return "fulfilled", nil },
+		func(err error) (string, error) { return "recovered from " + err.Error(), nil },
+	)
+	var result string
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, "recovered from upstream failed", result)
+}
+
+func TestThenCatchRejectedHandlerCanFail(t *testing.T) {
+	p := Rejected(errors.New("first")).ThenCatch(
+		func() error { return nil },
+		func(err error) error { return errors.New("second") },
+	)
+	err := p.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "second")
+}
+
+func TestThenCatchChecksSignatures(t *testing.T) {
+	p := Resolved(1)
+	require.Panics(t, func() {
+		p.ThenCatch(func(int) string { return "" }, func(string) string { return "" })
+	})
+	require.Panics(t, func() {
+		p.ThenCatch(func(int) string { return "" }, func(error) int { return 0 })
+	})
+	require.Panics(t, func() {
+		p.ThenCatch(func(int) string { return "" }, nil)
+	})
+}
@@ -0,0 +1,62 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ThenCtx is like Then, but f takes a context.Context before p's results,
+// so that it can pass the chain's context on to calls such as
+// http.NewRequestWithContext:
+//
+//	NewCtx(ctx, lookup, id).ThenCtx(func(ctx context.Context, url string) (*http.Response, error) {
+//		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//		...
+//	})
+//
+// The context is the one the chain inherited from NewCtx or from the
+// promises p depends on, or context.Background() if there is none, and is
+// canceled once the returned promise settles, including by Cancel.
+func (p *Promise) ThenCtx(f interface{}) *Promise {
+	p.checkCopy()
+	functionRv, joinErrors := funcValue(f)
+	if functionRv.Kind() != reflect.Func {
+		panic(fmt.Errorf("expected Function, got %v", functionRv.Kind()))
+	}
+	fnType := functionRv.Type()
+	if fnType.NumIn() == 0 || fnType.In(0) != contextType {
+		panic(fmt.Errorf("expected first argument of type %s, got %s", contextType, fnType))
+	}
+	ins := make([]reflect.Type, fnType.NumIn()-1)
+	for i := range ins {
+		ins[i] = fnType.In(i + 1)
+	}
+	outs := make([]reflect.Type, fnType.NumOut())
+	for i := range outs {
+		outs[i] = fnType.Out(i)
+	}
+	var ctxRv reflect.Value
+	adapter := reflect.MakeFunc(reflect.FuncOf(ins, outs, fnType.IsVariadic()), func(in []reflect.Value) []reflect.Value {
+		args := append([]reflect.Value{ctxRv}, in...)
+		if fnType.IsVariadic() {
+			return functionRv.CallSlice(args)
+		}
+		return functionRv.Call(args)
+	}).Interface()
+	if joinErrors {
+		adapter = joinedErrors{adapter}
+	}
+	return p.then(adapter, func(next *Promise) {
+		next.name = funcName(functionRv)
+		parent := p.ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithCancel(parent)
+		ctxRv = reflect.ValueOf(context.WithValue(ctx, promiseKey{}, next))
+		next.extras().cancelCtx = cancel
+		// f may hold on to the context, which refers to next.
+		next.pin()
+	})
+}
@@ -0,0 +1,52 @@
+package promise
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type requestIDKey struct{}
+
+func TestThenCtx(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	p := NewCtx(ctx, func(ctx context.Context) int { return 2 }).
+		ThenCtx(func(ctx context.Context, x int) (string, error) {
+			require.NoError(t, Checkpoint(ctx))
+			return ctx.Value(requestIDKey{}).(string), nil
+		})
+	var id string
+	require.NoError(t, p.Wait(&id))
+	require.Equal(t, "req-1", id)
+}
+
+func TestThenCtxWithoutContext(t *testing.T) {
+	p := Resolved(1, 2).ThenCtx(func(ctx context.Context, xs ...int) int {
+		require.NoError(t, ctx.Err())
+		return len(xs)
+	})
+	var n int
+	require.NoError(t, p.Wait(&n))
+	require.Equal(t, 2, n)
+}
+
+func TestThenCtxCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	p := NewCtx(ctx, func(ctx context.Context) {}).ThenCtx(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+	})
+	<-started
+	p.Cancel()
+	require.Equal(t, context.Canceled, <-stopped)
+	require.Equal(t, ErrCanceled, cause(p.Wait()))
+}
+
+func TestThenCtxRequiresContext(t *testing.T) {
+	require.Panics(t, func() { Resolved(1).ThenCtx(func(x int) {}) })
+}
@@ -0,0 +1,85 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle returns a function that calls New(f, args...) at most once per
+// minInterval. A call within minInterval of the one that last called f
+// returns that call's promise, even if its args differ, so a burst of
+// callers, such as a config refresh triggered from many places, shares a
+// single result.
+func Throttle(f interface{}, minInterval time.Duration) func(args ...interface{}) *Promise {
+	c := newCaller(f)
+	var mu sync.Mutex
+	var last *Promise
+	var started time.Time
+	return func(args ...interface{}) *Promise {
+		mu.Lock()
+		defer mu.Unlock()
+		now := currentTime()
+		if last != nil && now.Sub(started) < minInterval {
+			return last
+		}
+		p, start := c.newCall(nil, args)
+		start()
+		last, started = p, now
+		return p
+	}
+}
+
+// Debounce returns a function that calls New(f, args...) once calls to it
+// have stopped for quiet, with the args of the last call. Every call of a
+// burst returns the same promise, which settles like the one f ran in, so
+// only the final keystroke of an autocomplete box, say, sends a query.
+// Args are checked against f when passed, like New does.
+func Debounce(f interface{}, quiet time.Duration) func(args ...interface{}) *Promise {
+	c := newCaller(f)
+	resultType := c.resultType()
+	var mu sync.Mutex
+	var pending *Deferred
+	var lastArgs []interface{}
+	var timer Timer
+	// generation tells a timer that fired while a later call was
+	// restarting it that it is stale.
+	var generation int
+	fire := func(g int) {
+		mu.Lock()
+		if g != generation {
+			mu.Unlock()
+			return
+		}
+		d, args := pending, lastArgs
+		pending, lastArgs, timer = nil, nil, nil
+		mu.Unlock()
+		defer func() {
+			if r := recover(); r != nil {
+				d.settle(nil, panicError(r))
+			}
+		}()
+		p, start := c.newCall(nil, args)
+		start()
+		p.observe()
+		p.whenSettled(func() {
+			d.settle(p.results, p.err)
+		})
+	}
+	return func(args ...interface{}) *Promise {
+		callArgs(c.sig.in, c.functionRv.Type().IsVariadic(), args, true)
+		mu.Lock()
+		defer mu.Unlock()
+		if pending == nil {
+			pending = NewDeferred(resultType...)
+			pending.name = c.name
+		}
+		lastArgs = args
+		if timer != nil {
+			timer.Stop()
+		}
+		generation++
+		g := generation
+		timer = afterFunc(quiet, func() { fire(g) })
+		return pending.Promise
+	}
+}
@@ -0,0 +1,70 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+	var calls int32
+	load := Throttle(func(version int) int {
+		atomic.AddInt32(&calls, 1)
+		return version
+	}, time.Second)
+
+	first := load(1)
+	require.Same(t, first, load(2))
+	clock.Advance(999 * time.Millisecond)
+	require.Same(t, first, load(3))
+	clock.Advance(time.Millisecond)
+	second := load(4)
+	require.True(t, first != second)
+
+	var v int
+	require.NoError(t, first.Wait(&v))
+	require.Equal(t, 1, v)
+	require.NoError(t, second.Wait(&v))
+	require.Equal(t, 4, v)
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestDebounce(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+	var calls int32
+	search := Debounce(func(query string) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		if query == "" {
+			return nil, errors.New("empty query")
+		}
+		return []string{query + "ic"}, nil
+	}, 100*time.Millisecond)
+
+	p := search("g")
+	clock.Advance(50 * time.Millisecond)
+	require.Same(t, p, search("gar"))
+	clock.Advance(50 * time.Millisecond)
+	require.Same(t, p, search("garl"))
+	require.Equal(t, StatePending, p.State())
+	clock.Advance(100 * time.Millisecond)
+
+	var results []string
+	require.NoError(t, p.Wait(&results))
+	require.Equal(t, []string{"garlic"}, results)
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	next := search("")
+	require.True(t, p != next)
+	clock.Advance(100 * time.Millisecond)
+	require.EqualError(t, cause(next.Wait(&results)), "empty query")
+
+	require.Panics(t, func() { search(1) })
+}
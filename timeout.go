@@ -0,0 +1,103 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrWaitTimeout is returned by WaitTimeout and WaitDeadline when the
+// promise hasn't settled in time.
+var ErrWaitTimeout = errors.New("timed out waiting for promise")
+
+// WaitContext is like Wait, but gives up and returns ctx.Err() if ctx is
+// done before the promise settles, such as when a request handler's
+// deadline passes. The promise keeps running, and may be waited on again.
+func (p *Promise) WaitContext(ctx context.Context, out ...interface{}) error {
+	err := p.wait(ctx.Done(), errContextDone, out)
+	if err == errContextDone {
+		return ctx.Err()
+	}
+	return err
+}
+
+// errContextDone stands in for ctx.Err() in WaitContext, which isn't known
+// until ctx is done.
+var errContextDone = errors.New("context done")
+
+// ErrTimeout rejects a promise returned by WithTimeout when the promise it
+// wraps didn't settle in time.
+var ErrTimeout = errors.New("promise timed out")
+
+// ErrThenTimeout rejects a promise returned by ThenWithTimeout whose
+// function ran for too long.
+var ErrThenTimeout = errors.New("continuation timed out")
+
+// WaitTimeout is like Wait, but gives up and returns ErrWaitTimeout if the
+// promise hasn't settled within d, as measured by the promise's Clock. The
+// promise keeps running, and may be waited on again.
+func (p *Promise) WaitTimeout(d time.Duration, out ...interface{}) error {
+	expired := make(chan struct{})
+	if d <= 0 {
+		close(expired)
+	} else {
+		timer := p.clockFor().AfterFunc(d, func() { close(expired) })
+		defer timer.Stop()
+	}
+	return p.wait(expired, ErrWaitTimeout, out)
+}
+
+// WaitDeadline is like Wait, but gives up and returns ErrWaitTimeout if
+// the promise hasn't settled by deadline. The promise keeps running, and
+// may be waited on again.
+func (p *Promise) WaitDeadline(deadline time.Time, out ...interface{}) error {
+	return p.WaitTimeout(deadline.Sub(p.now()), out...)
+}
+
+// ThenWithTimeout is like Then, but rejects the returned promise with
+// ErrThenTimeout if f runs for longer than d. Only f's own execution is
+// timed, so time spent waiting for p doesn't count against d. f keeps
+// running after the timeout, and its results are discarded.
+func (p *Promise) ThenWithTimeout(d time.Duration, f interface{}) *Promise {
+	return p.Then(f, StageTimeout(d))
+}
+
+// A ThenOption configures the promise returned by Then.
+type ThenOption func(next *Promise)
+
+// StageTimeout rejects the promise returned by Then with ErrThenTimeout if
+// its function runs for longer than d, as with ThenWithTimeout. The
+// timeout is independent of any deadline on the chain's context, so fast
+// and slow stages of one chain can each have their own budget.
+func StageTimeout(d time.Duration) ThenOption {
+	return func(next *Promise) {
+		x := next.extras()
+		x.timeout = d
+		x.timeoutErr = ErrThenTimeout
+	}
+}
+
+// WithTimeout returns a promise that settles like p, or is rejected with
+// ErrTimeout if p hasn't settled within d. Unlike WaitTimeout, the timeout
+// is part of the chain, so every consumer of the returned promise sees
+// it. When the timeout fires, p is canceled as if by Cancel, which stops
+// chained work and cancels the context of a promise created by NewCtx,
+// unless other promises are chained from p and still want its results.
+func (p *Promise) WithTimeout(d time.Duration) *Promise {
+	p.checkCopy()
+	p.observe()
+	next := newPromise(signalCall, "WithTimeout")
+	next.resultType = p.resultType
+	p.graph.addChild(p, next)
+	next.watchContext(p.ctx)
+	timer := next.afterFunc(next.checkDeadline(d), func() {
+		if next.settle(nil, ErrTimeout) && p.onlyChild(next) {
+			p.Cancel()
+		}
+	})
+	next.Defer(func() { timer.Stop() })
+	p.whenSettled(func() {
+		next.settle(p.results, p.err)
+	})
+	return next
+}
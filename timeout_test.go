@@ -0,0 +1,151 @@
+package promise
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitTimeout(t *testing.T) {
+	release := make(chan struct{})
+	p := New(func() int {
+		<-release
+		return 1
+	})
+	var result int
+	require.Equal(t, ErrWaitTimeout, p.WaitTimeout(10*time.Millisecond, &result))
+	require.Equal(t, ErrWaitTimeout, p.WaitDeadline(time.Now().Add(10*time.Millisecond), &result))
+	require.Equal(t, 0, result)
+
+	close(release)
+	require.NoError(t, p.WaitTimeout(time.Second, &result))
+	require.Equal(t, 1, result)
+}
+
+func TestWaitTimeoutFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	d := NewDeferred(typeOf[int]())
+	d.Promise.Graph().SetClock(clock)
+
+	errs := make(chan error, 2)
+	go func() { errs <- d.Promise.WaitTimeout(time.Hour, new(int)) }()
+	go func() { errs <- d.Promise.WaitDeadline(clock.Now().Add(time.Hour), new(int)) }()
+	pollUntil(t, func() bool { return clock.pending() == 2 })
+	clock.Advance(time.Hour)
+	require.Equal(t, ErrWaitTimeout, <-errs)
+	require.Equal(t, ErrWaitTimeout, <-errs)
+	d.Resolve(1)
+}
+
+func TestWaitDeadlineInThePast(t *testing.T) {
+	p := New(func() int { return 1 })
+	var result int
+	require.NoError(t, p.Wait(&result))
+	require.NoError(t, p.WaitDeadline(time.Now().Add(-time.Second), &result),
+		"a settled promise is delivered even past the deadline")
+}
+
+func TestWaitTimeoutChecksTypes(t *testing.T) {
+	p := New(func() int { return 1 })
+	require.Panics(t, func() {
+		_ = p.WaitTimeout(time.Second, new(string))
+	})
+}
+
+func TestThenWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	p := Resolved(1).ThenWithTimeout(10*time.Millisecond, func(i int) int {
+		<-release
+		return i
+	})
+	require.Equal(t, ErrThenTimeout, cause(p.Wait(new(int))))
+}
+
+func TestThenWithTimeoutExcludesParent(t *testing.T) {
+	parent := New(func() int {
+		time.Sleep(50 * time.Millisecond)
+		return 1
+	})
+	var result int
+	p := parent.ThenWithTimeout(20*time.Millisecond, func(i int) int { return i + 1 })
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, 2, result)
+}
+
+func TestStageTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	fast := Resolved(1).Then(func(i int) int { return i + 1 }, StageTimeout(time.Second))
+	slow := fast.Then(func(i int) int {
+		<-release
+		return i
+	}, StageTimeout(10*time.Millisecond))
+	var result int
+	require.NoError(t, fast.Wait(&result))
+	require.Equal(t, 2, result)
+	require.Equal(t, ErrThenTimeout, cause(slow.Wait(&result)))
+}
+
+func TestWaitContext(t *testing.T) {
+	release := make(chan struct{})
+	p := New(func() int {
+		<-release
+		return 1
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var result int
+	require.Equal(t, context.DeadlineExceeded, p.WaitContext(ctx, &result))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Equal(t, context.Canceled, p.WaitContext(canceled, &result))
+	require.Equal(t, 0, result)
+
+	close(release)
+	require.NoError(t, p.WaitContext(context.Background(), &result))
+	require.Equal(t, 1, result)
+	require.NoError(t, p.WaitContext(canceled, &result), "a settled promise is delivered even after the context is done")
+}
+
+func TestWithTimeout(t *testing.T) {
+	ctx := make(chan context.Context, 1)
+	p := NewCtx(context.Background(), func(c context.Context) int {
+		ctx <- c
+		<-c.Done()
+		return 1
+	})
+	timed := p.WithTimeout(10 * time.Millisecond)
+	require.Equal(t, ErrTimeout, cause(timed.Wait(new(int))))
+	require.Equal(t, ErrCanceled, cause(p.Wait(new(int))))
+	require.Error(t, (<-ctx).Err(), "the work behind p is canceled")
+}
+
+func TestWithTimeoutSpareSiblings(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	SetClock(clock)
+	defer SetClock(nil)
+	release := make(chan struct{})
+	p := New(func() int {
+		<-release
+		return 1
+	})
+	sibling := p.Then(func(i int) int { return i + 1 })
+	timed := p.WithTimeout(10 * time.Millisecond)
+	clock.Advance(10 * time.Millisecond)
+	require.Equal(t, ErrTimeout, cause(timed.Wait(new(int))))
+
+	close(release)
+	var result int
+	require.NoError(t, sibling.Wait(&result), "p is still needed by sibling")
+	require.Equal(t, 2, result)
+}
+
+func TestWithTimeoutSettled(t *testing.T) {
+	var result int
+	require.NoError(t, Resolved(1).WithTimeout(time.Millisecond).Wait(&result))
+	require.Equal(t, 1, result)
+}
@@ -0,0 +1,65 @@
+package promise
+
+import "time"
+
+// Timings records when a promise went through the stages of its life.
+type Timings struct {
+	// Created is when the promise was created.
+	Created time.Time
+	// Started is when its function was called, and is zero if it hasn't
+	// been, such as for combinators or promises rejected while waiting.
+	Started time.Time
+	// Settled is when it settled, and is zero while it is pending.
+	Settled time.Time
+}
+
+// QueueDelay returns how long the promise waited between being created
+// and its function starting, including waiting on the promises before it
+// and for a scheduler worker. It is zero if the function hasn't started.
+func (t Timings) QueueDelay() time.Duration {
+	if t.Started.IsZero() {
+		return 0
+	}
+	return t.Started.Sub(t.Created)
+}
+
+// Execution returns how long the promise's function ran. It is zero
+// unless the function started and the promise has settled.
+func (t Timings) Execution() time.Duration {
+	if t.Started.IsZero() || t.Settled.IsZero() {
+		return 0
+	}
+	return t.Settled.Sub(t.Started)
+}
+
+// Total returns how long the promise took to settle after being created.
+// It is zero while the promise is pending.
+func (t Timings) Total() time.Duration {
+	if t.Settled.IsZero() {
+		return 0
+	}
+	return t.Settled.Sub(t.Created)
+}
+
+// Timings returns when p was created, started and settled.
+func (p *Promise) Timings() Timings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Timings{Created: p.created, Started: p.started, Settled: p.settled}
+}
+
+// markStarted records that p's function is about to be called.
+func (p *Promise) markStarted() {
+	started := p.now()
+	p.mu.Lock()
+	p.started = started
+	p.mu.Unlock()
+	callHooks(p, hookStart)
+}
+
+// createdAt returns when p was created.
+func (p *Promise) createdAt() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.created
+}
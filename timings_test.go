@@ -0,0 +1,40 @@
+package promise
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingsSeparateQueueDelayFromExecution(t *testing.T) {
+	SetScheduler(NewPool(1))
+	defer SetScheduler(nil)
+
+	busy := New(func() { time.Sleep(30 * time.Millisecond) })
+	queued := New(func() { time.Sleep(10 * time.Millisecond) })
+	require.NoError(t, All(busy, queued).Wait())
+
+	timings := queued.Timings()
+	require.True(t, timings.QueueDelay() >= 25*time.Millisecond, timings.QueueDelay())
+	require.True(t, timings.Execution() >= 10*time.Millisecond, timings.Execution())
+	require.True(t, timings.Execution() < 25*time.Millisecond, timings.Execution())
+	require.Equal(t, timings.Settled.Sub(timings.Created), timings.Total())
+}
+
+func TestTimingsOfPromiseThatNeverStarted(t *testing.T) {
+	p := Rejected(ErrCanceled).Then(func() {})
+	require.Error(t, p.Wait())
+	timings := p.Timings()
+	require.True(t, timings.Started.IsZero())
+	require.Zero(t, timings.QueueDelay())
+	require.Zero(t, timings.Execution())
+	require.NotZero(t, timings.Total())
+}
+
+func TestTimingsWhilePending(t *testing.T) {
+	signal := Signal()
+	timings := signal.Promise().Timings()
+	require.Zero(t, timings.Total())
+	signal.Resolve()
+}
@@ -0,0 +1,60 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SetResultTransform makes every promise in g that resolves from now on
+// pass its results through f first, so that, for example, personal data
+// can be redacted in one place before the results reach the promises
+// chained from it, waiters, and hooks such as the sampler. f is called on
+// the goroutine settling the promise, with a copy of its results, and
+// returns the results to resolve with instead, of the same types.
+// Combinators such as All pass on results that were already transformed.
+// Returning results of other types rejects the promise. Passing nil
+// removes the transform.
+func (g *Graph) SetResultTransform(f func(p *Promise, results []interface{}) []interface{}) {
+	g.mu.Lock()
+	g.transform = f
+	g.mu.Unlock()
+}
+
+// transformResults applies g's result transform, if any, to results, the
+// values p is about to resolve with. Combinators resolve with results
+// that were transformed already, and are left alone.
+func (g *Graph) transformResults(p *Promise, results []reflect.Value) ([]reflect.Value, error) {
+	switch p.t {
+	case allCall, raceCall, anyCall, finallyCall:
+		return results, nil
+	}
+	g.mu.Lock()
+	f := g.transform
+	g.mu.Unlock()
+	if f == nil {
+		return results, nil
+	}
+	values := make([]interface{}, len(results))
+	for i, result := range results {
+		values[i] = result.Interface()
+	}
+	values = f(p, values)
+	if len(values) != len(results) {
+		return nil, fmt.Errorf("result transform returned %d values for %d results", len(values), len(results))
+	}
+	transformed := make([]reflect.Value, len(values))
+	for i, value := range values {
+		t := results[i].Type()
+		if value == nil {
+			transformed[i] = reflect.Zero(t)
+			continue
+		}
+		rv := reflect.ValueOf(value)
+		if !rv.Type().AssignableTo(t) {
+			return nil, fmt.Errorf("result transform returned %s for result %d of type %s", rv.Type(), i, t)
+		}
+		transformed[i] = reflect.New(t).Elem()
+		transformed[i].Set(rv)
+	}
+	return transformed, nil
+}
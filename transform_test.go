@@ -0,0 +1,58 @@
+package promise
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type account struct {
+	Name string
+	SSN  string
+}
+
+func TestSetResultTransform(t *testing.T) {
+	d := NewDeferred(typeOf[account]())
+	d.Promise.Graph().SetResultTransform(func(p *Promise, results []interface{}) []interface{} {
+		for i, result := range results {
+			if a, ok := result.(account); ok {
+				a.SSN = "redacted"
+				results[i] = a
+			}
+		}
+		return results
+	})
+	seen := d.Promise.Then(func(a account) string { return a.SSN })
+	copied := d.Promise.Then(func(a account) account { return a })
+	d.Resolve(account{Name: "ann", SSN: "123-45-6789"})
+
+	var ssn string
+	require.NoError(t, seen.Wait(&ssn))
+	require.Equal(t, "redacted", ssn)
+	var a account
+	require.NoError(t, All(copied, copied).Wait(&a, new(account)))
+	require.Equal(t, account{Name: "ann", SSN: "redacted"}, a)
+}
+
+func TestSetResultTransformWrongType(t *testing.T) {
+	d := NewDeferred(typeOf[int]())
+	d.Promise.Graph().SetResultTransform(func(p *Promise, results []interface{}) []interface{} {
+		return []interface{}{"not an int"}
+	})
+	d.Resolve(1)
+	err := d.Promise.Wait(new(int))
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "result transform returned string for result 0 of type int"), err.Error())
+}
+
+func TestSetResultTransformNil(t *testing.T) {
+	d := NewDeferred(typeOf[*account]())
+	d.Promise.Graph().SetResultTransform(func(p *Promise, results []interface{}) []interface{} {
+		return []interface{}{nil}
+	})
+	d.Resolve(&account{Name: "ann"})
+	var a *account
+	require.NoError(t, d.Promise.Wait(&a))
+	require.Nil(t, a)
+}
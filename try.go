@@ -0,0 +1,59 @@
+package promise
+
+import "fmt"
+
+// TryNew is like New, except that it returns an error instead of panicking
+// when f isn't a function or args don't match its parameters, so promises
+// can be built safely from functions only known at run time. Both are
+// checked even with strict mode off.
+func TryNew(f interface{}, args ...interface{}) (*Promise, error) {
+	return try(func() *Promise {
+		return newWith(f, options{args: args, strict: true})
+	})
+}
+
+// TryThen is like Then, except that it returns an error instead of
+// panicking when f can't accept p's results, even with strict mode off.
+func (p *Promise) TryThen(f interface{}) (*Promise, error) {
+	return try(func() *Promise {
+		return p.thenChecked(f, nil, true)
+	})
+}
+
+// TryAll is like All, except that it returns an error instead of
+// panicking when passed a nil promise.
+func TryAll(promises ...*Promise) (*Promise, error) {
+	for i, p := range promises {
+		if p == nil {
+			return nil, fmt.Errorf("promise %d is nil", i)
+		}
+	}
+	return try(func() *Promise {
+		return All(promises...)
+	})
+}
+
+// try calls build, turning the error it panics with into a returned error.
+// Panics with other values are not construction errors and propagate.
+func try(build func() *Promise) (p *Promise, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(error)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+	return build(), nil
+}
+
+// untrackOnPanic forgets p, which is being constructed, if construction
+// panics, so a rejected constructor call doesn't leave it pending forever.
+// It must be deferred directly.
+func (p *Promise) untrackOnPanic() {
+	if r := recover(); r != nil {
+		untrackPending(p)
+		panic(r)
+	}
+}
@@ -0,0 +1,54 @@
+package promise
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryNew(t *testing.T) {
+	p, err := TryNew(func(i int) int { return i * 2 }, 21)
+	require.NoError(t, err)
+	var result int
+	require.NoError(t, p.Wait(&result))
+	require.Equal(t, 42, result)
+
+	_, err = TryNew(42)
+	require.EqualError(t, err, "expected Function, got int")
+	_, err = TryNew(func(i int) {}, "x")
+	require.EqualError(t, err, "for argument 0: expected type int got type string")
+}
+
+func TestTryThen(t *testing.T) {
+	p := Resolved(1)
+	next, err := p.TryThen(func(i int) int { return i + 1 })
+	require.NoError(t, err)
+	var result int
+	require.NoError(t, next.Wait(&result))
+	require.Equal(t, 2, result)
+
+	_, err = p.TryThen(func(s string) {})
+	require.EqualError(t, err, "for argument 0: expected type int got type string")
+	_, err = p.TryThen(func(a, b int) {})
+	require.EqualError(t, err, "promise returns 1 values, but provided function accepts 2 args")
+}
+
+func TestTryAll(t *testing.T) {
+	p, err := TryAll(Resolved(1), Resolved(2))
+	require.NoError(t, err)
+	require.NoError(t, p.Wait(new(int), new(int)))
+
+	_, err = TryAll(Resolved(1), nil)
+	require.EqualError(t, err, "promise 1 is nil")
+}
+
+func TestFailedConstructionIsNotPending(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+	_, err := TryNew(func(tryFailedConstruction int) {}, "x")
+	require.Error(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, DumpPending(&buf))
+	require.NotContains(t, buf.String(), "TestFailedConstructionIsNotPending")
+}
@@ -0,0 +1,56 @@
+package promise
+
+// A Result2 holds the two values of a promise as one, so they can travel
+// through a PromiseT or a channel together.
+type Result2[A, B any] struct {
+	V1 A
+	V2 B
+}
+
+// Unpack returns the values of r.
+func (r Result2[A, B]) Unpack() (A, B) {
+	return r.V1, r.V2
+}
+
+// A Result3 is like Result2 for three values.
+type Result3[A, B, C any] struct {
+	V1 A
+	V2 B
+	V3 C
+}
+
+// Unpack returns the values of r.
+func (r Result3[A, B, C]) Unpack() (A, B, C) {
+	return r.V1, r.V2, r.V3
+}
+
+// NewResult2 returns a typed promise that resolves with the two values f
+// returns, such as a value and whether it was found:
+//
+//	entry, err := NewResult2(func() (Entry, bool, error) { return cache.Lookup(key) }).Wait()
+func NewResult2[A, B any](f func() (A, B, error)) *PromiseT[Result2[A, B]] {
+	return NewT(func() (Result2[A, B], error) {
+		a, b, err := f()
+		return Result2[A, B]{a, b}, err
+	})
+}
+
+// NewResult3 is like NewResult2 for three values.
+func NewResult3[A, B, C any](f func() (A, B, C, error)) *PromiseT[Result3[A, B, C]] {
+	return NewT(func() (Result3[A, B, C], error) {
+		a, b, c, err := f()
+		return Result3[A, B, C]{a, b, c}, err
+	})
+}
+
+// AwaitResult2 is like Await2, but returns the two values as a Result2.
+func AwaitResult2[A, B any](p *Promise) (Result2[A, B], error) {
+	a, b, err := Await2[A, B](p)
+	return Result2[A, B]{a, b}, err
+}
+
+// AwaitResult3 is like Await3, but returns the three values as a Result3.
+func AwaitResult3[A, B, C any](p *Promise) (Result3[A, B, C], error) {
+	a, b, c, err := Await3[A, B, C](p)
+	return Result3[A, B, C]{a, b, c}, err
+}
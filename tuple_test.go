@@ -0,0 +1,46 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResult2(t *testing.T) {
+	lookup := map[string]int{"a": 1}
+	r, err := NewResult2(func() (int, bool, error) {
+		v, ok := lookup["a"]
+		return v, ok, nil
+	}).Wait()
+	require.NoError(t, err)
+	v, ok := r.Unpack()
+	require.Equal(t, 1, v)
+	require.True(t, ok)
+
+	_, err = NewResult2(func() (int, bool, error) { return 0, false, errors.New("lookup failed") }).Wait()
+	require.Error(t, err)
+	require.Equal(t, "lookup failed", cause(err).Error())
+}
+
+func TestNewResult3(t *testing.T) {
+	r, err := NewResult3(func() (string, int, bool, error) { return "a", 1, true, nil }).Wait()
+	require.NoError(t, err)
+	require.Equal(t, Result3[string, int, bool]{"a", 1, true}, r)
+}
+
+func TestAwaitResult(t *testing.T) {
+	r2, err := AwaitResult2[string, bool](Resolved("a", true))
+	require.NoError(t, err)
+	require.Equal(t, Result2[string, bool]{"a", true}, r2)
+
+	r3, err := AwaitResult3[int, int, string](Resolved(1, 2, ""))
+	require.NoError(t, err)
+	a, b, c := r3.Unpack()
+	require.Equal(t, 1, a)
+	require.Equal(t, 2, b)
+	require.Empty(t, c)
+
+	_, err = AwaitResult2[int, int](Resolved(1))
+	require.EqualError(t, err, "Await2: promise returns [int], expected [int int]")
+}
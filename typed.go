@@ -0,0 +1,74 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A PromiseT is a promise that resolves with a single value of type T.
+// Unlike Promise, the types of its functions and results are checked at
+// compile time. The untyped Promise underneath is available from Promise
+// for use with the rest of the package.
+type PromiseT[T any] struct {
+	p *Promise
+}
+
+// NewT returns a typed promise that resolves with the result of f.
+func NewT[T any](f func() (T, error)) *PromiseT[T] {
+	return &PromiseT[T]{p: newWith(f, options{call: thunkT(f)})}
+}
+
+// Typed wraps p, which must resolve with exactly one value of type T.
+func Typed[T any](p *Promise) *PromiseT[T] {
+	want := typeOf[T]()
+	if len(p.resultType) != 1 || p.resultType[0] != want {
+		panic(fmt.Errorf("promise returns %v, expected %s", p.resultType, want))
+	}
+	return &PromiseT[T]{p: p}
+}
+
+// Promise returns the untyped promise underlying pt.
+func (pt *PromiseT[T]) Promise() *Promise {
+	return pt.p
+}
+
+// valueType returns T, for flattening.
+func (pt *PromiseT[T]) valueType() reflect.Type {
+	return typeOf[T]()
+}
+
+// Wait blocks until pt settles and returns its value or error.
+func (pt *PromiseT[T]) Wait() (T, error) {
+	var value T
+	err := pt.p.Wait(&value)
+	return value, err
+}
+
+// Then returns a promise that resolves with the result of calling f on
+// pt's value. Use ThenOf to change the result type.
+func (pt *PromiseT[T]) Then(f func(T) (T, error)) *PromiseT[T] {
+	return ThenOf(pt, f)
+}
+
+// ThenOf returns a promise that resolves with the result of calling f on
+// pt's value.
+func ThenOf[T, U any](pt *PromiseT[T], f func(T) (U, error)) *PromiseT[U] {
+	return &PromiseT[U]{p: pt.p.then(f, func(next *Promise) {
+		next.call = thunkTU(f)
+	})}
+}
+
+// AllOf returns a promise that resolves with the values of ps in order,
+// or fails if any of them fails.
+func AllOf[T any](ps ...*PromiseT[T]) *PromiseT[[]T] {
+	if len(ps) == 0 {
+		return Typed[[]T](Resolved([]T{}))
+	}
+	untyped := make([]*Promise, len(ps))
+	for i, pt := range ps {
+		untyped[i] = pt.p
+	}
+	return &PromiseT[[]T]{p: All(untyped...).Then(func(values ...T) []T {
+		return values
+	})}
+}
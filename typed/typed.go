@@ -0,0 +1,138 @@
+// Package typed provides promises that resolve with a single value of a
+// type known at compile time, built only on generics and on the completion
+// engine the promise package settles its promises with. It doesn't import
+// reflect, unlike the promise package, for programs that care
+// about binary size or the cost of reflective calls and don't need the
+// untyped API. Its promises don't interoperate with the promise
+// package's.
+package typed
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/garlicnation/promises/v2/internal/core"
+)
+
+// A Promise resolves with a value of type T, or is rejected with an
+// error.
+type Promise[T any] struct {
+	cell *core.Cell[T]
+}
+
+// A PanicError rejects a promise whose function panicked.
+type PanicError struct {
+	Value interface{}
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", err.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (err *PanicError) Unwrap() error {
+	cause, _ := err.Value.(error)
+	return cause
+}
+
+func newPromise[T any]() *Promise[T] {
+	return &Promise[T]{cell: new(core.Cell[T])}
+}
+
+// New returns a promise that resolves with the result of f, which runs in
+// its own goroutine. A panic in f rejects the promise with a *PanicError.
+func New[T any](f func() (T, error)) *Promise[T] {
+	p := newPromise[T]()
+	go p.run(f)
+	return p
+}
+
+// run calls f and settles p with its outcome.
+func (p *Promise[T]) run(f func() (T, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero T
+			p.cell.Settle(zero, &PanicError{Value: r})
+		}
+	}()
+	value, err := f()
+	p.cell.Settle(value, err)
+}
+
+// Resolved returns a promise that has resolved with value.
+func Resolved[T any](value T) *Promise[T] {
+	p := newPromise[T]()
+	p.cell.Settle(value, nil)
+	return p
+}
+
+// Rejected returns a promise that has been rejected with err.
+func Rejected[T any](err error) *Promise[T] {
+	p := newPromise[T]()
+	var zero T
+	p.cell.Settle(zero, err)
+	return p
+}
+
+// Wait blocks until p settles and returns its value or error.
+func (p *Promise[T]) Wait() (T, error) {
+	return p.cell.Wait()
+}
+
+// Done returns a channel that is closed once p has settled.
+func (p *Promise[T]) Done() <-chan struct{} {
+	return p.cell.Done()
+}
+
+// Then returns a promise that resolves with the result of calling f on
+// p's value. If p is rejected, f isn't called and the returned promise is
+// rejected with the same error. Use the package's Then to change the
+// result type.
+func (p *Promise[T]) Then(f func(T) (T, error)) *Promise[T] {
+	return Then(p, f)
+}
+
+// Then returns a promise that resolves with the result of calling f on
+// p's value, or is rejected with p's error.
+func Then[T, U any](p *Promise[T], f func(T) (U, error)) *Promise[U] {
+	next := newPromise[U]()
+	p.cell.OnSettled(func() {
+		value, err := p.cell.Wait()
+		if err != nil {
+			var zero U
+			next.cell.Settle(zero, err)
+			return
+		}
+		go next.run(func() (U, error) {
+			return f(value)
+		})
+	})
+	return next
+}
+
+// All returns a promise that resolves with the values of ps in order, or
+// is rejected with the error of the first of them to fail.
+func All[T any](ps ...*Promise[T]) *Promise[[]T] {
+	all := newPromise[[]T]()
+	values := make([]T, len(ps))
+	remaining := int64(len(ps))
+	if len(ps) == 0 {
+		all.cell.Settle(values, nil)
+		return all
+	}
+	for i, p := range ps {
+		i, p := i, p
+		p.cell.OnSettled(func() {
+			value, err := p.cell.Wait()
+			if err != nil {
+				all.cell.Settle(nil, err)
+				return
+			}
+			values[i] = value
+			if atomic.AddInt64(&remaining, -1) == 0 {
+				all.cell.Settle(values, nil)
+			}
+		})
+	}
+	return all
+}
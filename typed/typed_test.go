@@ -0,0 +1,49 @@
+package typed
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThen(t *testing.T) {
+	p := New(func() (int, error) { return 20, nil })
+	plusOne := p.Then(func(x int) (int, error) { return x + 1, nil })
+	asString := Then(plusOne, func(x int) (string, error) { return strconv.Itoa(x * 2), nil })
+	result, err := asString.Wait()
+	require.NoError(t, err)
+	require.Equal(t, "42", result)
+}
+
+func TestRejectionSkipsThen(t *testing.T) {
+	p := Rejected[int](errors.New("failed"))
+	_, err := Then(p, func(x int) (string, error) {
+		t.Error("called after a rejection")
+		return "", nil
+	}).Wait()
+	require.EqualError(t, err, "failed")
+}
+
+func TestPanic(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	_, err := New(func() (int, error) { panic(sentinel) }).Wait()
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	require.True(t, errors.Is(err, sentinel))
+}
+
+func TestAll(t *testing.T) {
+	values, err := All(Resolved(1), New(func() (int, error) { return 2, nil }), Resolved(3)).Wait()
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, values)
+
+	_, err = All(Resolved(1), Rejected[int](errors.New("failed"))).Wait()
+	require.EqualError(t, err, "failed")
+
+	values, err = All[int]().Wait()
+	require.NoError(t, err)
+	require.Empty(t, values)
+	<-All[int]().Done()
+}
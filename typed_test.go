@@ -0,0 +1,66 @@
+package promise
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromiseT(t *testing.T) {
+	p := NewT(func() (int, error) {
+		return 20, nil
+	})
+	plusOne := p.Then(func(x int) (int, error) {
+		return x + 1, nil
+	})
+	asString := ThenOf(plusOne, func(x int) (string, error) {
+		return strconv.Itoa(x * 2), nil
+	})
+	result, err := asString.Wait()
+	require.NoError(t, err)
+	require.Equal(t, "42", result)
+}
+
+func TestPromiseTError(t *testing.T) {
+	p := NewT(func() (int, error) {
+		return 0, errors.New("failed")
+	})
+	_, err := ThenOf(p, func(x int) (string, error) {
+		return "unreachable", nil
+	}).Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed")
+}
+
+func TestAllOf(t *testing.T) {
+	promises := []*PromiseT[int]{}
+	for i := 0; i < 5; i++ {
+		i := i
+		promises = append(promises, NewT(func() (int, error) {
+			return i, nil
+		}))
+	}
+	values, err := AllOf(promises...).Wait()
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 4}, values)
+
+	values, err = AllOf(promises[0]).Wait()
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, values)
+
+	values, err = AllOf[int]().Wait()
+	require.NoError(t, err)
+	require.Equal(t, []int{}, values)
+}
+
+func TestTyped(t *testing.T) {
+	p := New(func() string { return "garlic" })
+	value, err := Typed[string](p).Wait()
+	require.NoError(t, err)
+	require.Equal(t, "garlic", value)
+	require.Panics(t, func() {
+		Typed[int](p)
+	})
+}
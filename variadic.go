@@ -0,0 +1,70 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// callArgs returns args as the values to call a function with, where
+// inputs are the function's parameters that args fill. If variadic, the
+// last of inputs is the variadic slice, which takes any number of args,
+// including none, like a call written out in Go. A single arg of the slice
+// type itself is spread over it instead, as if passed with "...". Type
+// mismatches panic if strict.
+func callArgs(inputs []reflect.Type, variadic bool, args []interface{}, strict bool) []reflect.Value {
+	fixed := inputs
+	if variadic {
+		fixed = inputs[:len(inputs)-1]
+		if len(args) < len(fixed) {
+			panic(fmt.Errorf("expected at least %d args, got %d args", len(fixed), len(args)))
+		}
+	} else if len(args) != len(inputs) {
+		panic(fmt.Errorf("expected %d args, got %d args", len(inputs), len(args)))
+	}
+
+	values := make([]reflect.Value, 0, len(args))
+	for i, t := range fixed {
+		values = append(values, argValue(i, args[i], t, strict, false))
+	}
+	if !variadic {
+		return values
+	}
+	rest := args[len(fixed):]
+	slice := inputs[len(inputs)-1]
+	if len(rest) == 1 && rest[0] != nil && reflect.TypeOf(rest[0]) == slice {
+		sliceRv := reflect.ValueOf(rest[0])
+		for i := 0; i < sliceRv.Len(); i++ {
+			values = append(values, sliceRv.Index(i))
+		}
+		return values
+	}
+	for i, arg := range rest {
+		values = append(values, argValue(len(fixed)+i, arg, slice.Elem(), strict, true))
+	}
+	return values
+}
+
+// argValue returns arg, argument i, as a value to pass as a t. Variadic
+// elements only need to be assignable to t, as in Go, while other
+// arguments must have type t exactly. A nil arg is the zero t, if t can
+// be nil.
+func argValue(i int, arg interface{}, t reflect.Type, strict, variadic bool) reflect.Value {
+	if arg == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+			return reflect.Zero(t)
+		}
+		panic(fmt.Errorf("for argument %d: expected type %s got nil", i, t))
+	}
+	argRv := reflect.ValueOf(arg)
+	if !strict {
+		return argRv
+	}
+	if variadic && !argRv.Type().AssignableTo(t) {
+		panic(fmt.Errorf("for variadic argument %d: type %s is not assignable to %s", i, argRv.Type(), t))
+	}
+	if !variadic && argRv.Type() != t {
+		panic(fmt.Errorf("for argument %d: expected type %s got type %s", i, t, argRv.Type()))
+	}
+	return argRv
+}
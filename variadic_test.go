@@ -0,0 +1,52 @@
+package promise
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVariadic(t *testing.T) {
+	var s string
+	require.NoError(t, New(fmt.Sprintf, "x=%d y=%v", 5, nil).Wait(&s))
+	require.Equal(t, "x=5 y=<nil>", s)
+
+	require.NoError(t, New(fmt.Sprint).Wait(&s))
+	require.Equal(t, "", s)
+
+	require.NoError(t, New(strings.Join, []string{"a", "b"}, "-").Wait(&s))
+	require.Equal(t, "a-b", s)
+}
+
+func TestNewVariadicSpreadsSlice(t *testing.T) {
+	sum := func(base int, xs ...int) int {
+		for _, x := range xs {
+			base += x
+		}
+		return base
+	}
+	var total int
+	require.NoError(t, New(sum, 1, []int{2, 3}).Wait(&total))
+	require.Equal(t, 6, total)
+	require.NoError(t, New(sum, 1, 2, 3, 4).Wait(&total))
+	require.Equal(t, 10, total)
+	require.NoError(t, New(sum, 1).Wait(&total))
+	require.Equal(t, 1, total)
+}
+
+func TestNewVariadicMismatches(t *testing.T) {
+	SetStrict(true)
+	defer SetStrict(defaultStrict)
+	sum := func(base int, xs ...int) int { return base }
+	panicMessage := func(f func()) (msg string) {
+		defer func() { msg = fmt.Sprint(recover()) }()
+		f()
+		return ""
+	}
+	require.Equal(t, "expected at least 1 args, got 0 args", panicMessage(func() { New(sum) }))
+	require.Equal(t, "for variadic argument 2: type string is not assignable to int", panicMessage(func() { New(sum, 1, 2, "3") }))
+	require.Equal(t, "for argument 0: expected type int got nil", panicMessage(func() { New(sum, nil) }))
+	require.Equal(t, "expected 1 args, got 2 args", panicMessage(func() { New(func(int) {}, 1, 2) }))
+}
@@ -0,0 +1,51 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WaitFirst blocks until one of promises settles and returns its index
+// and error, without creating a combinator promise. The other promises
+// keep running. It panics if no promises are passed.
+func WaitFirst(promises ...*Promise) (index int, err error) {
+	indices, err := WaitN(1, promises...)
+	return indices[0], err
+}
+
+// WaitN blocks until n of promises have settled and returns their indices
+// in the order they settled, along with the error of the first of them to
+// fail, if any. The other promises keep running. It panics if n is not
+// between 1 and len(promises).
+func WaitN(n int, promises ...*Promise) ([]int, error) {
+	if n < 1 || n > len(promises) {
+		panic(fmt.Errorf("cannot wait for %d of %d promises", n, len(promises)))
+	}
+	cases := make([]reflect.SelectCase, len(promises))
+	for i, p := range promises {
+		p.checkCopy()
+		p.observe()
+		cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(p.doneChan())}
+	}
+	// Take promises that already settled in order, rather than letting
+	// select pick among them at random.
+	indices := make([]int, 0, n)
+	for i, p := range promises {
+		if len(indices) < n && p.isSettled() {
+			indices = append(indices, i)
+			cases[i].Chan = reflect.Value{}
+		}
+	}
+	for len(indices) < n {
+		i, _, _ := reflect.Select(cases)
+		indices = append(indices, i)
+		// A nil channel is never selected again.
+		cases[i].Chan = reflect.Value{}
+	}
+	for _, i := range indices {
+		if err := promises[i].err; err != nil {
+			return indices, promises[i].executionError(err)
+		}
+	}
+	return indices, nil
+}
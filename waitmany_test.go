@@ -0,0 +1,42 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitFirst(t *testing.T) {
+	slow := New(func() { time.Sleep(50 * time.Millisecond) })
+	fast := New(func() {})
+	index, err := WaitFirst(slow, fast)
+	require.NoError(t, err)
+	require.Equal(t, 1, index)
+	require.False(t, slow.isSettled())
+}
+
+func TestWaitFirstReturnsError(t *testing.T) {
+	signal := Signal()
+	index, err := WaitFirst(signal.Promise(), Rejected(errors.New("failed")))
+	require.Equal(t, 1, index)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed")
+	signal.Resolve()
+}
+
+func TestWaitN(t *testing.T) {
+	release := make(chan struct{})
+	blocked := New(func() { <-release })
+	defer close(release)
+	a := Resolved(1)
+	b := New(func() { time.Sleep(10 * time.Millisecond) })
+	indices, err := WaitN(2, blocked, b, a)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 1}, indices)
+	require.False(t, blocked.isSettled())
+
+	require.Panics(t, func() { WaitN(4, blocked, a, b) })
+	require.Panics(t, func() { WaitFirst() })
+}
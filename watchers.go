@@ -0,0 +1,44 @@
+package promise
+
+import "sync/atomic"
+
+// pendingWatchers counts the calls of watchInput that are still waiting.
+var pendingWatchers int64
+
+// PendingWatchers returns how many inputs the combinators All, Race and
+// Any are still waiting on. A combinator stops waiting on an input once
+// the input settles, or once the combinator itself settles, as when one
+// input of All fails, so that inputs which never settle don't keep it, and
+// everything it refers to, alive. A count that keeps growing points at
+// combinators that never settle.
+func PendingWatchers() int {
+	return int(atomic.LoadInt64(&pendingWatchers))
+}
+
+// watchInput calls f once input settles, unless p settles first, in which
+// case f is dropped, along with the reference p holds for it.
+func (p *Promise) watchInput(input *Promise, f func()) {
+	atomic.AddInt64(&pendingWatchers, 1)
+	var finished int32
+	finish := func() bool {
+		if !atomic.CompareAndSwapInt32(&finished, 0, 1) {
+			return false
+		}
+		atomic.AddInt64(&pendingWatchers, -1)
+		return true
+	}
+	c := input.addContinuation(func() {
+		if finish() {
+			f()
+		}
+	})
+	if c == nil {
+		return
+	}
+	p.whenSettled(func() {
+		if finish() {
+			input.removeContinuation(c)
+			p.releaseRef()
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllStopsWatchingAfterFailure(t *testing.T) {
+	before := PendingWatchers()
+	never := NewDeferred()
+	failing := NewDeferred()
+	all := All(never.Promise, failing.Promise, Resolved())
+	require.Equal(t, before+2, PendingWatchers())
+
+	failing.Reject(errors.New("failed"))
+	require.Error(t, all.Wait())
+	require.Equal(t, before, PendingWatchers())
+	never.mu.Lock()
+	require.Zero(t, never.signal.Waiting())
+	never.mu.Unlock()
+
+	// Settling the input afterwards doesn't touch all.
+	never.Resolve()
+	require.Equal(t, before, PendingWatchers())
+}
+
+func TestAllStopsWatchingWhenInputAlreadyFailed(t *testing.T) {
+	before := PendingWatchers()
+	never := NewDeferred()
+	all := All(Rejected(errors.New("failed")), never.Promise)
+	require.Error(t, all.Wait())
+	require.Equal(t, before, PendingWatchers())
+	never.Resolve()
+}
+
+func TestRaceStopsWatchingLosers(t *testing.T) {
+	before := PendingWatchers()
+	shared := NewDeferred()
+	chained := shared.Promise.Then(func() {})
+	race := Race(Resolved(), shared.Promise)
+	require.NoError(t, race.Wait())
+	require.Equal(t, before, PendingWatchers())
+	shared.Resolve()
+	require.NoError(t, chained.Wait())
+}